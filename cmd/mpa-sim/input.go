@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilYaml "k8s.io/apimachinery/pkg/util/yaml"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+)
+
+// sample qps 序列中的一个样本
+type sample struct {
+	time time.Time
+	qps  float64
+}
+
+// readQpsSeries 读取 qps 序列, 返回按时间排序的样本
+// 支持 CSV(每行为 "时间,qps", 可以有表头, '#' 开头的行为注释)与 JSON([{"time": 时间, "qps": qps}, ...])两种格式
+// 时间为 RFC3339 格式的时刻, 或相对序列起点的秒数
+func readQpsSeries(path string) ([]sample, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var samples []sample
+	if strings.HasSuffix(path, ".json") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		samples, err = parseJsonSeries(data)
+	} else {
+		samples, err = parseCsvSeries(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse qps series %s: %v", path, err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("qps series %s is empty", path)
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].time.Before(samples[j].time) })
+	return samples, nil
+}
+
+// parseCsvSeries 解析 CSV 格式的 qps 序列
+func parseCsvSeries(r io.Reader) ([]sample, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	samples := make([]sample, 0)
+	for line := 1; ; line += 1 {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expect \"time,qps\", got %q", line, strings.Join(record, ","))
+		}
+		qps, err := parseQps(record[1])
+		if err != nil {
+			// 第一行可以是表头
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		t, err := parseTime(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		samples = append(samples, sample{time: t, qps: qps})
+	}
+	return samples, nil
+}
+
+// parseJsonSeries 解析 JSON 格式的 qps 序列
+func parseJsonSeries(data []byte) ([]sample, error) {
+	var records []struct {
+		Time json.RawMessage `json:"time"`
+		Qps  float64         `json:"qps"`
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	samples := make([]sample, 0, len(records))
+	for i, record := range records {
+		if record.Qps < 0 || math.IsNaN(record.Qps) {
+			return nil, fmt.Errorf("sample %d: invalid qps %g", i, record.Qps)
+		}
+		t, err := parseTime(strings.Trim(string(record.Time), "\""))
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %v", i, err)
+		}
+		samples = append(samples, sample{time: t, qps: record.Qps})
+	}
+	return samples, nil
+}
+
+// parseTime 解析 RFC3339 格式的时刻或相对序列起点的秒数
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, 0).UTC().Add(time.Duration(seconds * float64(time.Second))), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q(expect RFC3339 or seconds)", value)
+	}
+	return t, nil
+}
+
+// parseQps 解析非负的 qps
+func parseQps(value string) (float64, error) {
+	qps, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || qps < 0 || math.IsNaN(qps) {
+		return 0, fmt.Errorf("invalid qps %q", value)
+	}
+	return qps, nil
+}
+
+// workload MPA 的 targetRef 指向的工作负载(副本数及 pod 模板)
+type workload struct {
+	replicas int32
+	template corev1.PodTemplateSpec
+}
+
+// readMpa 读取 YAML/JSON 文件(可以包含多个对象, 如 examples 中的部署文件)中的 MPA 对象
+// 文件中包含 MPA targetRef 指向的工作负载(Deployment 等带有 spec.template 的对象)时一并返回
+func readMpa(path string) (*mpaTypes.MultidimPodAutoscaler, *workload, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 先解析为 JSON, 保留整数字段的类型
+	documents := make([]json.RawMessage, 0)
+	objects := make([]*unstructured.Unstructured, 0)
+	decoder := utilYaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, fmt.Errorf("failed to decode %s: %v", path, err)
+		}
+		if len(document) == 0 || string(document) == "null" {
+			continue
+		}
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(document); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode %s: %v", path, err)
+		}
+		documents = append(documents, document)
+		objects = append(objects, object)
+	}
+
+	var mpa *mpaTypes.MultidimPodAutoscaler
+	for i, object := range objects {
+		if object.GetKind() != "MultidimPodAutoscaler" {
+			continue
+		}
+		if mpa != nil {
+			return nil, nil, fmt.Errorf("%s contains more than one MultidimPodAutoscaler", path)
+		}
+		mpa = &mpaTypes.MultidimPodAutoscaler{}
+		if err := json.Unmarshal(documents[i], mpa); err != nil {
+			return nil, nil, fmt.Errorf("invalid MultidimPodAutoscaler %s: %v", object.GetName(), err)
+		}
+	}
+	if mpa == nil {
+		return nil, nil, fmt.Errorf("no MultidimPodAutoscaler found in %s", path)
+	}
+	if mpa.Namespace == "" {
+		mpa.Namespace = "default"
+	}
+	if mpa.Spec.TargetRef == nil {
+		return mpa, nil, nil
+	}
+
+	for i, object := range objects {
+		if object.GetKind() != mpa.Spec.TargetRef.Kind || object.GetName() != mpa.Spec.TargetRef.Name {
+			continue
+		}
+		var target struct {
+			Spec struct {
+				Replicas *int32                  `json:"replicas"`
+				Template *corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(documents[i], &target); err != nil {
+			return nil, nil, fmt.Errorf("invalid pod template of %s/%s: %v", object.GetKind(), object.GetName(), err)
+		}
+		if target.Spec.Template == nil {
+			return nil, nil, fmt.Errorf("target %s/%s has no pod template", object.GetKind(), object.GetName())
+		}
+		w := &workload{replicas: 1, template: *target.Spec.Template}
+		if target.Spec.Replicas != nil {
+			w.replicas = *target.Spec.Replicas
+		}
+		return mpa, w, nil
+	}
+	return mpa, nil, nil
+}
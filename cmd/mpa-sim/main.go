@@ -0,0 +1,238 @@
+// mpa-sim 离线模拟 MPA 的推荐及更新过程(what-if)
+// 将记录的 qps 序列(CSV/JSON)按推荐间隔重放给 recommender 的推荐算法(含更新阈值、上下界等逻辑),
+// 并按 updater 的规则(上下界内不伸缩/驱逐、驱逐比例)应用推荐方案, 输出副本数/cpu 的时间线、
+// 总的资源成本、预测的 SLA 违约次数以及 updater 的动作数, 用于在部署前评估成本模型或算法参数的修改
+//
+// 使用方式: go run ./cmd/mpa-sim -qps qps.csv -mpa examples/cpu-bound/deploy/deploy-with-mpa.yaml
+// qps.csv 每行为 "时间,qps"(时间为 RFC3339 格式或相对序列起点的秒数)
+// -config 指定 recommender 的配置文件(RecommenderConfiguration)时, 使用其中的成本模型
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
+	configv1alpha1 "multidim-pod-autoscaler/pkg/apis/config/v1alpha1"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+)
+
+var (
+	qpsFile    = flag.String("qps", "", "qps 序列文件(CSV 或 JSON)")
+	mpaFile    = flag.String("mpa", "", "包含 MPA 对象的 YAML/JSON 文件(可同时包含 targetRef 指向的 Deployment 等工作负载)")
+	configFile = flag.String("config", "", "recommender 的配置文件(RecommenderConfiguration), 使用其中的成本模型, "+
+		"以及未显式指定 -interval、-default-algorithm 时的推荐间隔和默认算法")
+
+	interval         = flag.Duration("interval", time.Minute, "推荐(及 updater)的间隔, 与 recommender 的 --recommender-interval 对应")
+	defaultAlgorithm = flag.String("default-algorithm", recommendation.MMCAlgorithm,
+		"MPA 未指定 spec.algorithm 时使用的推荐算法(mmc、target-utilization、percentile-histogram)")
+	evictionFraction = flag.Float64("eviction-fraction", 1, "每轮可以驱逐的副本个数占副本数的比例, 与 updater 的 --eviction-fraction 对应")
+
+	replicas     = flag.Int("replicas", 1, "文件中没有 targetRef 指向的工作负载时, 初始的副本数")
+	cpu          = flag.String("cpu", "500m", "文件中没有工作负载, 或容器未指定 cpu requests 时, 初始的 cpu")
+	responseTime = flag.Duration("response-time", 0, "SLA 要求的响应时间, 默认使用 MPA 的 expRespTime(未指定时为 300ms)")
+	slaQuantile  = flag.Float64("sla-quantile", 0.95, "SLA 要求在响应时间内完成的请求比例")
+
+	output = flag.String("output", "table", "输出格式(table、csv、json)")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "mpa-sim: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run 读取输入并执行模拟, 结果写入 w
+func run(w io.Writer) error {
+	if *qpsFile == "" || *mpaFile == "" {
+		return fmt.Errorf("both -qps and -mpa are required")
+	}
+	if *configFile != "" {
+		if err := loadConfiguration(*configFile); err != nil {
+			return err
+		}
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("-interval must be positive")
+	}
+	if *evictionFraction < 0 || *evictionFraction > 1 {
+		return fmt.Errorf("-eviction-fraction must be in [0, 1]")
+	}
+	if *slaQuantile <= 0 || *slaQuantile >= 1 {
+		return fmt.Errorf("-sla-quantile must be in (0, 1)")
+	}
+	defaultCpu, err := resource.ParseQuantity(*cpu)
+	if err != nil {
+		return fmt.Errorf("invalid -cpu %q: %v", *cpu, err)
+	}
+
+	samples, err := readQpsSeries(*qpsFile)
+	if err != nil {
+		return err
+	}
+	mpa, target, err := readMpa(*mpaFile)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		target = &workload{
+			replicas: int32(*replicas),
+			template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			},
+		}
+	}
+	// 未指定 cpu requests 的容器使用 -cpu
+	for i := range target.template.Spec.Containers {
+		container := &target.template.Spec.Containers[i]
+		if _, existed := container.Resources.Requests[corev1.ResourceCPU]; !existed {
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[corev1.ResourceCPU] = defaultCpu
+		}
+	}
+
+	sim, err := newSimulator(mpa, target, simulatorConfig{
+		interval:         *interval,
+		evictionFraction: *evictionFraction,
+		responseTime:     *responseTime,
+		slaQuantile:      *slaQuantile,
+		defaultAlgorithm: *defaultAlgorithm,
+	}, samples[0].time)
+	if err != nil {
+		return err
+	}
+	timeline, total := sim.run(samples)
+
+	switch *output {
+	case "table":
+		return writeTable(w, timeline, total)
+	case "csv":
+		return writeCsv(w, timeline)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Timeline []step  `json:"timeline"`
+			Summary  summary `json:"summary"`
+		}{timeline, total})
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+}
+
+// loadConfiguration 读取 recommender 的配置文件, 替换推荐算法使用的成本模型
+// 命令行中未显式指定的 -interval、-default-algorithm 使用配置中的值
+func loadConfiguration(path string) error {
+	data, err := utilConfig.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	c := &configv1alpha1.RecommenderConfiguration{}
+	gvk := configv1alpha1.SchemeGroupVersion.WithKind(configv1alpha1.RecommenderConfigurationKind)
+	if err := utilConfig.Decode(data, gvk, c); err != nil {
+		return err
+	}
+	configv1alpha1.SetDefaults_RecommenderConfiguration(c)
+	if errs := configv1alpha1.ValidateRecommenderConfiguration(c); len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %v", errs.ToAggregate())
+	}
+	model, err := recommendation.ModelFromConfiguration(&c.CostModel)
+	if err != nil {
+		return fmt.Errorf("invalid cost model: %v", err)
+	}
+	if err := recommendation.SetModel(model); err != nil {
+		return fmt.Errorf("invalid cost model: %v", err)
+	}
+
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	if !explicit["interval"] {
+		*interval = c.RecommenderInterval.Duration
+	}
+	if !explicit["default-algorithm"] {
+		*defaultAlgorithm = c.DefaultAlgorithm
+	}
+	return nil
+}
+
+// writeTable 以表格输出时间线及汇总
+func writeTable(w io.Writer, timeline []step, total summary) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "OFFSET\tQPS\tACTION\tTARGET\tPODS BOUNDS\tCPU BOUNDS\tREPLICAS\tCPU\tRESCALED\tEVICTIONS\tCOST\tSLA VIOLATIONS")
+	for _, st := range timeline {
+		fmt.Fprintf(tw, "%s\t%.2f\t%s\t%d × %s\t%s\t%s\t%d\t%s\t%t\t%d\t%.6f\t%d\n",
+			seconds(st.OffsetSeconds), st.Qps, st.Action, st.TargetPodNum, st.TargetCpu, st.PodNumBounds, st.CpuBounds,
+			st.Replicas, st.Cpu, st.Rescaled, st.Evictions, st.Cost, st.SlaViolations)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, st := range timeline {
+		if st.Message != "" {
+			fmt.Fprintf(w, "%s: %s\n", seconds(st.OffsetSeconds), st.Message)
+		}
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "duration:\t%s(%d samples)\n", seconds(total.DurationSeconds), total.Samples)
+	fmt.Fprintf(tw, "recommendations:\t%d(%d applied)\n", total.Recommendations, total.Applied)
+	fmt.Fprintf(tw, "updater actions:\t%d(%d rescales, %d evictions)\n", total.Actions, total.Rescales, total.Evictions)
+	fmt.Fprintf(tw, "max replicas:\t%d\n", total.MaxReplicas)
+	fmt.Fprintf(tw, "total cost:\t%.6f\n", total.TotalCost)
+	fmt.Fprintf(tw, "sla violations:\t%d samples(%s)\n", total.SlaViolations, seconds(total.ViolationSeconds))
+	return tw.Flush()
+}
+
+// writeCsv 以 CSV 输出时间线
+func writeCsv(w io.Writer, timeline []step) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"time", "offsetSeconds", "qps", "action", "targetPodNum", "targetCpu", "podNumBounds", "cpuBounds",
+		"replicas", "cpu", "rescaled", "evictions", "cost", "slaViolations", "maxWaitProbability", "message", "reason"})
+	for _, st := range timeline {
+		writer.Write([]string{
+			st.Time.Format(time.RFC3339),
+			strconv.FormatFloat(st.OffsetSeconds, 'f', -1, 64),
+			strconv.FormatFloat(st.Qps, 'f', -1, 64),
+			st.Action,
+			strconv.Itoa(st.TargetPodNum),
+			st.TargetCpu,
+			st.PodNumBounds,
+			st.CpuBounds,
+			strconv.Itoa(st.Replicas),
+			st.Cpu,
+			strconv.FormatBool(st.Rescaled),
+			strconv.Itoa(st.Evictions),
+			strconv.FormatFloat(st.Cost, 'f', -1, 64),
+			strconv.Itoa(st.SlaViolations),
+			strconv.FormatFloat(st.MaxWaitProbability, 'f', -1, 64),
+			st.Message,
+			st.Reason,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// seconds 将秒数格式化为时长
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/metrics"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+	recommenderUtil "multidim-pod-autoscaler/pkg/recommender/util"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	utilRecommendation "multidim-pod-autoscaler/pkg/util/recommendation"
+)
+
+// 每一轮推荐的结果
+const (
+	actionApply = "apply"
+	actionSkip  = "skip"
+	actionError = "error"
+)
+
+// seriesMetricsClient 按当前的 qps 为模拟的 pods 提供 http_requests metrics(qps 平均分配到各个 pod)
+type seriesMetricsClient struct {
+	clock clock.Clock
+	qps   float64
+	pods  []*corev1.Pod
+}
+
+// GetPodRawMetric 实现 metrics.Client 接口
+func (c *seriesMetricsClient) GetPodRawMetric(
+	metricName string,
+	namespace string,
+	selector labels.Selector,
+	metricSelector labels.Selector,
+) (metrics.PodMetricsInfo, time.Time, error) {
+	if len(c.pods) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no pods")
+	}
+	now := c.clock.Now()
+	podQps := c.qps / float64(len(c.pods))
+	res := make(metrics.PodMetricsInfo, len(c.pods))
+	for _, pod := range c.pods {
+		res[recommenderUtil.GetPodId(pod)] = metrics.PodMetric{
+			Name:      metricName,
+			Timestamp: now,
+			Window:    time.Minute,
+			Value:     *resource.NewMilliQuantity(int64(math.Round(podQps*1000)), resource.DecimalSI),
+		}
+	}
+	return res, now, nil
+}
+
+// step 模拟时间线中的一轮推荐(及 updater 的动作)
+type step struct {
+	Time time.Time `json:"time"`
+	// OffsetSeconds 相对序列起点的秒数
+	OffsetSeconds float64 `json:"offsetSeconds"`
+	// Qps 本轮推荐使用的 qps(上一个推荐间隔内样本的平均值)
+	Qps    float64 `json:"qps"`
+	Action string  `json:"action"`
+	// Message 推荐失败的原因, Reason 应用或保持方案的原因(决策记录)
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	// Recommendation 当前的推荐方案(pods × cpu 及上下界)
+	TargetPodNum int    `json:"targetPodNum"`
+	TargetCpu    string `json:"targetCpu"`
+	PodNumBounds string `json:"podNumBounds"`
+	CpuBounds    string `json:"cpuBounds"`
+	// Replicas、Cpu updater 执行后的副本数及各副本的 cpu
+	Replicas int    `json:"replicas"`
+	Cpu      string `json:"cpu"`
+	// Rescaled updater 是否修改了副本数, Evictions updater 驱逐的 pod 数
+	Rescaled  bool `json:"rescaled"`
+	Evictions int  `json:"evictions"`
+	// Cost 到下一轮推荐之前的资源成本
+	Cost float64 `json:"cost"`
+	// SlaViolations 到下一轮推荐之前违反 SLA 的样本数
+	SlaViolations int `json:"slaViolations"`
+	// MaxWaitProbability 到下一轮推荐之前请求排队超时的最大概率
+	MaxWaitProbability float64 `json:"maxWaitProbability"`
+}
+
+// summary 整个模拟的汇总
+type summary struct {
+	DurationSeconds float64 `json:"durationSeconds"`
+	Samples         int     `json:"samples"`
+	Recommendations int     `json:"recommendations"`
+	// Applied 应用(更新到 MPA status)的推荐方案数
+	Applied int `json:"applied"`
+	// Rescales、Evictions updater 修改副本数及驱逐 pod 的次数, Actions 为两者之和
+	Rescales  int `json:"rescales"`
+	Evictions int `json:"evictions"`
+	Actions   int `json:"actions"`
+	// TotalCost 总的资源成本(与 recommender 的成本模型使用相同的 cpu 单价)
+	TotalCost float64 `json:"totalCost"`
+	// SlaViolations 违反 SLA 的样本数, ViolationSeconds 违反 SLA 的总时长
+	SlaViolations    int     `json:"slaViolations"`
+	ViolationSeconds float64 `json:"violationSeconds"`
+	MaxReplicas      int     `json:"maxReplicas"`
+}
+
+// simulator 离线重放 qps 序列: 每个推荐间隔运行一次推荐算法(含更新阈值等逻辑), 并按 updater 的规则应用推荐方案
+type simulator struct {
+	mpa       *mpaTypes.MultidimPodAutoscaler
+	template  corev1.PodTemplateSpec
+	pods      []*corev1.Pod
+	nextPodId int
+
+	registry      *recommendation.Registry
+	processor     utilRecommendation.Processor
+	metricsClient *seriesMetricsClient
+	clock         *clock.FakeClock
+
+	interval         time.Duration
+	evictionFraction float64
+	// responseTimeMs SLA 要求的响应时间, slaQuantile 在响应时间内完成的请求比例
+	responseTimeMs float64
+	slaQuantile    float64
+}
+
+// simulatorConfig 模拟的配置
+type simulatorConfig struct {
+	interval         time.Duration
+	evictionFraction float64
+	responseTime     time.Duration
+	slaQuantile      float64
+	defaultAlgorithm string
+}
+
+// newSimulator 构造 simulator; 初始的 pods 由 target 的副本数及 pod 模板生成
+func newSimulator(mpa *mpaTypes.MultidimPodAutoscaler, target *workload, config simulatorConfig, start time.Time) (*simulator, error) {
+	fakeClock := clock.NewFakeClock(start)
+	metricsClient := &seriesMetricsClient{clock: fakeClock}
+	registry, err := recommendation.NewRegistry(config.defaultAlgorithm,
+		recommendation.NewCalculator(metricsClient),
+		recommendation.NewTargetUtilizationCalculator(metricsClient),
+		recommendation.NewPercentileHistogramCalculatorWithClock(metricsClient, fakeClock),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	responseTime := config.responseTime
+	if responseTime <= 0 {
+		responseTime = 300 * time.Millisecond
+		if mpa.Spec.ResourcePolicy != nil && len(mpa.Spec.ResourcePolicy.ContainerPolicies) > 0 &&
+			mpa.Spec.ResourcePolicy.ContainerPolicies[0].ExpRespTime > 0 {
+			responseTime = time.Duration(mpa.Spec.ResourcePolicy.ContainerPolicies[0].ExpRespTime) * time.Millisecond
+		}
+	}
+
+	s := &simulator{
+		mpa:              mpa.DeepCopy(),
+		template:         target.template,
+		registry:         registry,
+		processor:        utilRecommendation.NewProcessor(nil),
+		metricsClient:    metricsClient,
+		clock:            fakeClock,
+		interval:         config.interval,
+		evictionFraction: config.evictionFraction,
+		responseTimeMs:   float64(responseTime / time.Millisecond),
+		slaQuantile:      config.slaQuantile,
+	}
+	if len(s.template.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("pod template has no containers")
+	}
+	for i := int32(0); i < target.replicas; i += 1 {
+		s.pods = append(s.pods, s.newPod(nil))
+	}
+	return s, nil
+}
+
+// run 重放 samples, 返回每一轮推荐的时间线及汇总
+func (s *simulator) run(samples []sample) ([]step, summary) {
+	start, end := samples[0].time, samples[len(samples)-1].time
+	timeline := make([]step, 0)
+	total := summary{DurationSeconds: end.Sub(start).Seconds(), Samples: len(samples)}
+
+	next := 0
+	for tick := start; !tick.After(end); tick = tick.Add(s.interval) {
+		st := s.recommend(tick, windowQps(samples, tick.Add(-s.interval), tick))
+		st.OffsetSeconds = tick.Sub(start).Seconds()
+		total.Recommendations += 1
+		if st.Action == actionApply {
+			total.Applied += 1
+		}
+		if st.Rescaled {
+			total.Rescales += 1
+		}
+		total.Evictions += st.Evictions
+		if st.Replicas > total.MaxReplicas {
+			total.MaxReplicas = st.Replicas
+		}
+
+		// 评估到下一轮推荐之前的样本
+		nextTick := tick.Add(s.interval)
+		for ; next < len(samples) && samples[next].time.Before(nextTick); next += 1 {
+			duration := sampleDuration(samples, next, s.interval)
+			cost, violated, waitProbability := s.evaluate(samples[next].qps, duration)
+			st.Cost += cost
+			total.TotalCost += cost
+			if waitProbability > st.MaxWaitProbability {
+				st.MaxWaitProbability = waitProbability
+			}
+			if violated {
+				st.SlaViolations += 1
+				total.SlaViolations += 1
+				total.ViolationSeconds += duration.Seconds()
+			}
+		}
+		timeline = append(timeline, st)
+	}
+	total.Actions = total.Rescales + total.Evictions
+	return timeline, total
+}
+
+// recommend 在 now 时刻以 qps 运行一轮推荐, 应用推荐方案后执行 updater 的逻辑
+func (s *simulator) recommend(now time.Time, qps float64) step {
+	s.clock.SetTime(now)
+	s.metricsClient.qps = qps
+	s.metricsClient.pods = s.pods
+	st := step{Time: now, Qps: qps}
+
+	action, decision, err := s.calculate()
+	switch {
+	case err != nil:
+		st.Action, st.Message = actionError, err.Error()
+	case action == recommendation.ApplyRecommendation:
+		st.Action = actionApply
+	default:
+		st.Action = actionSkip
+	}
+	if decision != nil {
+		st.Reason = decision.Reason
+	}
+	// 与 updater 相同: 只在最新状态为 RecommendationProvided 时更新
+	if utilMpa.GetMpaUpdateMode(s.mpa) == mpaTypes.UpdateModeAuto &&
+		utilMpa.GetMpaLatestCondition(s.mpa).Type == mpaTypes.RecommendationProvided &&
+		s.mpa.Status.RecommendationResources != nil {
+		st.Rescaled, st.Evictions = s.update()
+	}
+
+	if res := s.mpa.Status.RecommendationResources; res != nil && len(res.ContainerRecommendations) > 0 {
+		container := res.ContainerRecommendations[0]
+		st.TargetPodNum = res.TargetPodNum
+		st.TargetCpu = quantityString(container.Target, corev1.ResourceCPU)
+		st.PodNumBounds = fmt.Sprintf("[%d, %d]", res.LowerBoundPodNum, res.UpperBoundPodNum)
+		st.CpuBounds = fmt.Sprintf("[%s, %s]",
+			quantityString(container.LowerBound, corev1.ResourceCPU), quantityString(container.UpperBound, corev1.ResourceCPU))
+	}
+	st.Replicas = len(s.pods)
+	st.Cpu = s.podsCpu()
+	return st
+}
+
+// calculate 与 recommender 相同: 计算推荐方案, 应用时经过 processor 调整后写入 MPA status
+func (s *simulator) calculate() (recommendation.RecommendationAction, *mpaTypes.RecommendationDecision, error) {
+	algorithm, err := s.registry.Get(s.mpa)
+	if err != nil {
+		return recommendation.UnknownRecommendation, nil, err
+	}
+	mpaWithSelector := &utilMpa.MpaWithSelector{Mpa: s.mpa, Selector: labels.Everything()}
+	res, action, decision, err := algorithm.Calculate(mpaWithSelector, s.pods)
+	if err != nil {
+		s.setCondition(mpaTypes.RecommendationSkipped, "CalculateRecommendationFailed", err.Error())
+		return recommendation.UnknownRecommendation, nil, err
+	}
+	if action != recommendation.ApplyRecommendation {
+		s.setCondition(mpaTypes.RecommendationSkipped, "Recommendation Skipped", "")
+		return action, decision, nil
+	}
+	adjusted, _, err := s.processor.AdjustRecommendation(res, s.mpa.Spec.ResourcePolicy, s.newPod(nil))
+	if err != nil {
+		s.setCondition(mpaTypes.RecommendationSkipped, "AdjustRecommendationFailed", err.Error())
+		return recommendation.UnknownRecommendation, decision, err
+	}
+	s.mpa.Status.RecommendationResources = adjusted
+	s.mpa.Status.Algorithm = &mpaTypes.RecommendationAlgorithmStatus{Name: algorithm.Name(), Version: algorithm.Version()}
+	s.setCondition(mpaTypes.RecommendationProvided, "Recommendation Provided", "")
+	return action, decision, nil
+}
+
+// setCondition 记录最新的推荐状态(只保留最后一个, 避免 status 无限增长)
+func (s *simulator) setCondition(conditionType mpaTypes.MultidimPodAutoscalerConditionType, reason, message string) {
+	s.mpa.Status.Conditions = []mpaTypes.MultidimPodAutoscalerCondition{{
+		Type:               conditionType,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(s.clock.Now()),
+		Reason:             reason,
+		Message:            message,
+	}}
+}
+
+// update 与 updater 相同: 副本数超出推荐方案的上下界时伸缩到推荐的副本数, 驱逐资源量超出上下界的 pods
+// 新建的 pods 按 admission 的规则使用推荐的资源量; 返回是否修改了副本数及驱逐的 pod 数
+func (s *simulator) update() (bool, int) {
+	res := s.mpa.Status.RecommendationResources
+	rescaled := false
+	if !utilRecommendation.ReplicasWithinBounds(int32(len(s.pods)), res) {
+		rescaled = true
+		for len(s.pods) < res.TargetPodNum {
+			s.pods = append(s.pods, s.newPod(res))
+		}
+		// 缩容时删除最新创建的 pods
+		if len(s.pods) > res.TargetPodNum {
+			s.pods = s.pods[:res.TargetPodNum]
+		}
+	}
+
+	evictable := int(float64(len(s.pods)) * s.evictionFraction)
+	evictions := 0
+	for i, pod := range s.pods {
+		if evictions >= evictable {
+			break
+		}
+		if utilRecommendation.PodNeedsUpdate(pod, s.mpa) {
+			s.pods[i] = s.newPod(res)
+			evictions += 1
+		}
+	}
+	return rescaled, evictions
+}
+
+// newPod 按 pod 模板创建 pod; res 不为空时按 admission 的规则将受控容器的 requests 设置为推荐值
+func (s *simulator) newPod(res *mpaTypes.RecommendedResources) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: *s.template.ObjectMeta.DeepCopy(),
+		Spec:       *s.template.Spec.DeepCopy(),
+	}
+	pod.Namespace = s.mpa.Namespace
+	pod.Name = fmt.Sprintf("%s-%d", s.mpa.Name, s.nextPodId)
+	s.nextPodId += 1
+	if res == nil {
+		return pod
+	}
+	policy := s.mpa.Spec.ResourcePolicy
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if utilMpa.GetContainerScalingMode(container.Name, policy) == mpaTypes.ContainerScalingModeOff {
+			continue
+		}
+		containerRecommendation := utilRecommendation.GetContainerRecommendation(container.Name, res.ContainerRecommendations)
+		if containerRecommendation == nil {
+			continue
+		}
+		for _, resourceName := range utilMpa.GetContainerControlledResources(container.Name, policy) {
+			if target, existed := containerRecommendation.Target[resourceName]; existed {
+				if container.Resources.Requests == nil {
+					container.Resources.Requests = corev1.ResourceList{}
+				}
+				container.Resources.Requests[resourceName] = target
+			}
+		}
+	}
+	return pod
+}
+
+// evaluate 评估当前 pods 在 qps 下持续 duration 的资源成本, 以及是否违反 SLA
+// SLA: 按 M/M/c 模型, 排队时间超过 (响应时间 - 处理时间) 的请求比例不超过 1 - slaQuantile
+func (s *simulator) evaluate(qps float64, duration time.Duration) (float64, bool, float64) {
+	var cost, capacity float64
+	for _, pod := range s.pods {
+		cpu := podCpuMilli(pod)
+		cost += recommendation.PlanCost(1, cpu) * duration.Seconds()
+		capacity += float64(recommendation.PodCapacity(cpu))
+	}
+	podNum := int64(len(s.pods))
+	if podNum == 0 || capacity <= 0 {
+		return cost, qps > 0, 1
+	}
+	// 副本的处理能力不同时使用平均值
+	podCapacity := capacity / float64(podNum)
+	waitTimeMs := math.Max(s.responseTimeMs-1000.0/podCapacity, 0)
+	waitProbability := recommendation.WaitProbability(podNum, podCapacity, qps, waitTimeMs)
+	return cost, waitProbability > 1-s.slaQuantile, waitProbability
+}
+
+// podsCpu 返回各副本的 cpu(不同时显示范围)
+func (s *simulator) podsCpu() string {
+	if len(s.pods) == 0 {
+		return "-"
+	}
+	minCpu, maxCpu := int64(math.MaxInt64), int64(0)
+	for _, pod := range s.pods {
+		cpu := podCpuMilli(pod)
+		if cpu < minCpu {
+			minCpu = cpu
+		}
+		if cpu > maxCpu {
+			maxCpu = cpu
+		}
+	}
+	if minCpu == maxCpu {
+		return fmt.Sprintf("%dm", minCpu)
+	}
+	return fmt.Sprintf("%dm-%dm", minCpu, maxCpu)
+}
+
+// podCpuMilli 返回 pod 第一个容器的 cpu requests(与推荐算法的方案对应: 每个副本的 cpu)
+func podCpuMilli(pod *corev1.Pod) int64 {
+	cpu := pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+	return cpu.MilliValue()
+}
+
+// quantityString 返回 resources 中 name 的值, 不存在时返回 "-"
+func quantityString(resources corev1.ResourceList, name corev1.ResourceName) string {
+	quantity, existed := resources[name]
+	if !existed {
+		return "-"
+	}
+	return quantity.String()
+}
+
+// windowQps 返回 (from, to] 内样本 qps 的平均值(与 metrics 的时间窗口对应); 窗口内没有样本时使用 to 之前最近的样本
+func windowQps(samples []sample, from, to time.Time) float64 {
+	var sum float64
+	var count int
+	last := -1
+	for i, sample := range samples {
+		if sample.time.After(to) {
+			break
+		}
+		last = i
+		if sample.time.After(from) {
+			sum += sample.qps
+			count += 1
+		}
+	}
+	if count > 0 {
+		return sum / float64(count)
+	}
+	if last >= 0 {
+		return samples[last].qps
+	}
+	return 0
+}
+
+// sampleDuration 返回样本 i 代表的时长(到下一个样本的间隔); 最后一个样本使用前一个间隔, 只有一个样本时使用 interval
+func sampleDuration(samples []sample, i int, interval time.Duration) time.Duration {
+	if i+1 < len(samples) {
+		return samples[i+1].time.Sub(samples[i].time)
+	}
+	if i > 0 {
+		return samples[i].time.Sub(samples[i-1].time)
+	}
+	return interval
+}
@@ -0,0 +1,101 @@
+// grpc-recommender 为进程外推荐算法插件的示例(桩)服务, 用于测试 recommender 的 grpc 算法
+// 按每个副本可处理的 qps 计算副本数, 每个副本使用固定的 cpu
+// 使用方式: recommender 指定 --recommendation-plugin-address=<address>,
+// MPA 指定 spec.algorithm: {name: grpc, params: {qpsPerPod: 20, cpu: "500m"}}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"net"
+
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
+	pluginApi "multidim-pod-autoscaler/pkg/recommender/plugin/v1"
+)
+
+var (
+	address = flag.String("address", ":9090", "grpc 服务监听的地址")
+	// skip 为 true 时总是返回 SKIP, 用于测试保持当前推荐方案
+	skip = flag.Bool("skip", false, "总是返回 SKIP")
+)
+
+// params MPA spec.algorithm.params
+type params struct {
+	// QpsPerPod 每个副本可处理的 qps
+	QpsPerPod float64 `json:"qpsPerPod,omitempty"`
+	// Cpu 每个副本(的每个容器)的 cpu
+	Cpu string `json:"cpu,omitempty"`
+}
+
+type server struct {
+	pluginApi.UnimplementedCalculatorServer
+}
+
+// Calculate 实现 CalculatorServer 接口
+func (s *server) Calculate(ctx context.Context, request *pluginApi.CalculateRequest) (*pluginApi.CalculateResponse, error) {
+	p := params{QpsPerPod: 20, Cpu: "500m"}
+	if len(request.AlgorithmParams) > 0 {
+		if err := json.Unmarshal(request.AlgorithmParams, &p); err != nil {
+			return nil, fmt.Errorf("invalid params: %v", err)
+		}
+	}
+	if p.QpsPerPod <= 0 {
+		return nil, fmt.Errorf("qpsPerPod must be positive")
+	}
+	cpu, err := resource.ParseQuantity(p.Cpu)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpu %q: %v", p.Cpu, err)
+	}
+
+	var qps float64
+	for _, pod := range request.Pods {
+		for _, sample := range pod.Metrics {
+			if sample.Name == "http_requests" {
+				qps += sample.Value
+			}
+		}
+	}
+	klog.V(2).Infof("calculate recommendation for MPA(%s/%s): %d pods, qps %g, constraints %v",
+		request.Namespace, request.Name, len(request.Pods), qps, request.Constraints)
+	if *skip {
+		return &pluginApi.CalculateResponse{Action: pluginApi.CalculateResponse_SKIP}, nil
+	}
+
+	podNum := int32(math.Max(1, math.Ceil(qps/p.QpsPerPod)))
+	return &pluginApi.CalculateResponse{
+		Action: pluginApi.CalculateResponse_APPLY,
+		Recommendation: &pluginApi.RecommendedResources{
+			TargetPodNum:         podNum,
+			LowerBoundPodNum:     podNum,
+			UpperBoundPodNum:     podNum + 1,
+			UncappedTargetPodNum: podNum,
+			ContainerRecommendations: []*pluginApi.RecommendedContainerResources{
+				{
+					ContainerName: "*",
+					Target:        map[string]string{"cpu": cpu.String()},
+				},
+			},
+		},
+	}, nil
+}
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		klog.Fatalf("failed to listen on %s: %v", *address, err)
+	}
+	grpcServer := grpc.NewServer()
+	pluginApi.RegisterCalculatorServer(grpcServer, &server{})
+	klog.Infof("recommendation plugin stub listening on %s", *address)
+	if err := grpcServer.Serve(listener); err != nil {
+		klog.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -4,7 +4,11 @@ go 1.15
 
 require (
 	github.com/fsnotify/fsnotify v1.4.9 // indirect
+	github.com/golang/protobuf v1.4.3
 	github.com/prometheus/client_golang v1.10.0
+	github.com/spf13/pflag v1.0.5
+	google.golang.org/grpc v1.38.0
+	google.golang.org/protobuf v1.25.0
 	k8s.io/api v0.21.0
 	k8s.io/apimachinery v0.21.0
 	k8s.io/client-go v0.21.0
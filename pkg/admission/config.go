@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+
+	"k8s.io/klog"
+	"multidim-pod-autoscaler/pkg/admission/config"
+	configv1alpha1 "multidim-pod-autoscaler/pkg/apis/config/v1alpha1"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+)
+
+var (
+	configFile, configReloadPeriod = utilConfig.RegisterFlags(flag.CommandLine, configv1alpha1.AdmissionConfigurationKind)
+)
+
+// loadConfiguration 解析配置文件(data 为 nil 时只使用命令行参数), 设置默认值,
+// 用命令行中显式指定的参数覆盖文件中的值, 最后进行校验
+func loadConfiguration(data []byte) (*configv1alpha1.AdmissionConfiguration, error) {
+	c := &configv1alpha1.AdmissionConfiguration{}
+	if data != nil {
+		gvk := configv1alpha1.SchemeGroupVersion.WithKind(configv1alpha1.AdmissionConfigurationKind)
+		if err := utilConfig.Decode(data, gvk, c); err != nil {
+			return nil, err
+		}
+	}
+	configv1alpha1.SetDefaults_AdmissionConfiguration(c)
+	for name := range utilConfig.ExplicitFlags() {
+		switch name {
+		case "client-ca-file":
+			c.Certs.ClientCaFile = *certsConfiguration.ClientCaFile
+		case "tls-cert-file":
+			c.Certs.TlsCertFile = *certsConfiguration.TlsCertFile
+		case "tls-private-key":
+			c.Certs.TlsPrivateKey = *certsConfiguration.TlsPrivateKey
+		case "self-managed-certs":
+			c.Certs.SelfManaged = *selfManagedCerts
+		case "certs-secret-name":
+			c.Certs.SecretName = *certsSecretName
+		case "certs-validity":
+			c.Certs.Validity.Duration = *certsValidity
+		case "certs-rotate-before":
+			c.Certs.RotateBefore.Duration = *certsRotateBefore
+		case "certs-check-interval":
+			c.Certs.CheckInterval.Duration = *certsCheckInterval
+		case "port":
+			c.Port = int32(*port)
+		case "address":
+			c.Diagnostics.Address = *prometheusAddress
+		case "profiling":
+			c.Diagnostics.EnableProfiling = *profiling
+		case "kube-api-qps":
+			c.ClientConnection.QPS = float32(*kubeApiQps)
+		case "kube-api-burst":
+			c.ClientConnection.Burst = int32(*kubeApiBurst)
+		case "mpa-object-namespace":
+			c.MpaObjectNamespace = *mpaObjectNamespace
+		case "webhook-service":
+			c.Webhook.Service = *serviceName
+		case "webhook-timeout-seconds":
+			c.Webhook.TimeoutSeconds = int32(*webhookTimeout)
+		case "webhook-failure-policy":
+			c.Webhook.FailurePolicy = *webhookFailurePolicy
+		case "webhook-namespace-selector":
+			selector := *webhookNamespaceSelector
+			c.Webhook.NamespaceSelector = &selector
+		case "webhook-object-selector":
+			selector := *webhookObjectSelector
+			c.Webhook.ObjectSelector = &selector
+		case "webhook-reinvocation-policy":
+			c.Webhook.ReinvocationPolicy = *webhookReinvocationPolicy
+		case "webhook-match-policy":
+			c.Webhook.MatchPolicy = *webhookMatchPolicy
+		case "webhook-resync-period":
+			c.Webhook.ResyncPeriod.Duration = *webhookResyncPeriod
+		case "webhook-remove-on-shutdown":
+			c.Webhook.RemoveOnShutdown = *webhookRemoveOnShutdown
+		}
+	}
+	if errs := configv1alpha1.ValidateAdmissionConfiguration(c); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	if err := webhookOptions(c).Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// webhookOptions 根据配置构造注册 webhook 的 options
+func webhookOptions(c *configv1alpha1.AdmissionConfiguration) *config.WebhookOptions {
+	return &config.WebhookOptions{
+		Namespace:          namespace,
+		ServiceName:        c.Webhook.Service,
+		TimeoutSeconds:     c.Webhook.TimeoutSeconds,
+		FailurePolicy:      c.Webhook.FailurePolicy,
+		NamespaceSelector:  *c.Webhook.NamespaceSelector,
+		ObjectSelector:     *c.Webhook.ObjectSelector,
+		ReinvocationPolicy: c.Webhook.ReinvocationPolicy,
+		MatchPolicy:        c.Webhook.MatchPolicy,
+	}
+}
+
+// configurationReloader 在配置文件变化时应用新的配置
+// webhook 配置(resyncPeriod 除外)立即生效, 其他字段被修改时只打印警告(重启后生效)
+type configurationReloader struct {
+	// initial 启动时的配置
+	initial           *configv1alpha1.AdmissionConfiguration
+	webhookReconciler *config.WebhookReconciler
+
+	lock             sync.RWMutex
+	removeOnShutdown bool
+}
+
+func newConfigurationReloader(initial *configv1alpha1.AdmissionConfiguration, webhookReconciler *config.WebhookReconciler) *configurationReloader {
+	return &configurationReloader{
+		initial:           initial,
+		webhookReconciler: webhookReconciler,
+		removeOnShutdown:  initial.Webhook.RemoveOnShutdown,
+	}
+}
+
+// webhookRemoveOnShutdown 返回退出时是否删除 webhook 配置
+func (r *configurationReloader) webhookRemoveOnShutdown() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.removeOnShutdown
+}
+
+// reload 加载并应用 data 中的配置, 配置不合法时保持当前配置
+func (r *configurationReloader) reload(data []byte) {
+	c, err := loadConfiguration(data)
+	if err != nil {
+		klog.Errorf("invalid configuration, keep using the current one: %v", err)
+		return
+	}
+	if err := r.webhookReconciler.SetOptions(webhookOptions(c)); err != nil {
+		klog.Errorf("invalid webhook configuration, keep using the current one: %v", err)
+		return
+	}
+	r.lock.Lock()
+	r.removeOnShutdown = c.Webhook.RemoveOnShutdown
+	r.lock.Unlock()
+
+	old := r.initial
+	if !reflect.DeepEqual(old.ClientConnection, c.ClientConnection) || !reflect.DeepEqual(old.Diagnostics, c.Diagnostics) ||
+		old.MpaObjectNamespace != c.MpaObjectNamespace || old.Port != c.Port || !reflect.DeepEqual(old.Certs, c.Certs) ||
+		old.Webhook.ResyncPeriod != c.Webhook.ResyncPeriod {
+		klog.Warningf("clientConnection, diagnostics, mpaObjectNamespace, port, certs and webhook.resyncPeriod take effect after restart")
+	}
+	klog.Infof("configuration reloaded: webhook failurePolicy=%s, timeoutSeconds=%d", c.Webhook.FailurePolicy, c.Webhook.TimeoutSeconds)
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+const (
+	// secret 中证书及秘钥的 key, 与 gencerts.sh 生成的文件名保持一致
+	caCertKey     = "caCert.pem"
+	caKeyKey      = "caKey.pem"
+	serverCertKey = "serverCert.pem"
+	serverKeyKey  = "serverKey.pem"
+	// 轮换CA时, 新CA先只加入 caBundle, 保存在以下 key 中, 直到 webhook 配置的 caBundle 包含新CA后才用于签发
+	nextCACertKey = "nextCaCert.pem"
+	nextCAKeyKey  = "nextCaKey.pem"
+	nextCATimeKey = "nextCaTime"
+
+	// caValidity 自签发CA证书的有效期
+	caValidity = 10 * 365 * 24 * time.Hour
+	// rsaKeySize 生成秘钥的长度
+	rsaKeySize = 2048
+	// caPromoteDelay 新CA加入 caBundle 后至少经过该时间才用于签发服务器证书(等待 api-server 加载新的 webhook 配置)
+	caPromoteDelay = time.Minute
+)
+
+// CertManager 管理 webhook server 的自签发证书
+// 证书保存在 Secret 中(多副本共享), 到期前自动轮换, 并通过 tls.Config.GetCertificate 热加载
+type CertManager struct {
+	client       kubernetes.Interface
+	namespace    string
+	secretName   string
+	serviceName  string
+	validity     time.Duration
+	rotateBefore time.Duration
+
+	lock sync.RWMutex
+	// cert 当前使用的服务器证书
+	cert *tls.Certificate
+	// caBundle 当前的 CA 证书(轮换CA时同时包含新旧CA)
+	caBundle []byte
+}
+
+// NewCertManager 构造 CertManager
+// validity 为服务器证书的有效期, rotateBefore 为证书到期前多久进行轮换
+func NewCertManager(client kubernetes.Interface, namespace, secretName, serviceName string, validity, rotateBefore time.Duration) *CertManager {
+	return &CertManager{
+		client:       client,
+		namespace:    namespace,
+		secretName:   secretName,
+		serviceName:  serviceName,
+		validity:     validity,
+		rotateBefore: rotateBefore,
+	}
+}
+
+// GetCertificate 返回当前的服务器证书, 用于 tls.Config.GetCertificate
+func (cm *CertManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cm.lock.RLock()
+	defer cm.lock.RUnlock()
+	if cm.cert == nil {
+		return nil, fmt.Errorf("serving certificate is not ready")
+	}
+	return cm.cert, nil
+}
+
+// CABundle 返回当前的 CA 证书(PEM), 用于 webhook 配置的 caBundle
+func (cm *CertManager) CABundle() []byte {
+	cm.lock.RLock()
+	defer cm.lock.RUnlock()
+	return cm.caBundle
+}
+
+// Init 加载(或生成)证书, 直到成功或 ctx 结束
+func (cm *CertManager) Init(ctx context.Context) error {
+	return wait.PollImmediateUntil(5*time.Second, func() (bool, error) {
+		if _, err := cm.sync(ctx); err != nil {
+			klog.Errorf("failed to initialize webhook certificates: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}, ctx.Done())
+}
+
+// Run 每隔 interval 检查一次证书, 必要时轮换证书, 直到 ctx 结束
+// CA 发生变化时调用 onCABundleChanged(用于更新 webhook 配置的 caBundle)
+func (cm *CertManager) Run(ctx context.Context, interval time.Duration, onCABundleChanged func(caBundle []byte)) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		changed, err := cm.sync(ctx)
+		if err != nil {
+			klog.Errorf("failed to rotate webhook certificates: %v", err)
+			return
+		}
+		if changed && onCABundleChanged != nil {
+			onCABundleChanged(cm.CABundle())
+		}
+	}, interval)
+}
+
+// sync 从 Secret 中加载证书, 证书不存在或即将过期时重新生成并写回 Secret
+// 返回 caBundle 是否发生变化
+func (cm *CertManager) sync(ctx context.Context) (bool, error) {
+	secrets := cm.client.CoreV1().Secrets(cm.namespace)
+	secret, err := secrets.Get(ctx, cm.secretName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	exists := err == nil
+	var data map[string][]byte
+	if exists {
+		data = secret.Data
+	}
+
+	newData, rotated, err := cm.rotateIfNeeded(data, func(caCert *x509.Certificate) bool {
+		return cm.caBundlePublished(ctx, caCert)
+	})
+	if err != nil {
+		return false, err
+	}
+	if rotated {
+		// 多副本同时轮换时, 只有一个副本能写入成功, 其他副本在下次检查时加载
+		if exists {
+			secretCopy := secret.DeepCopy()
+			secretCopy.Data = newData
+			_, err = secrets.Update(ctx, secretCopy, metav1.UpdateOptions{})
+		} else {
+			_, err = secrets.Create(ctx, &coreV1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cm.secretName, Namespace: cm.namespace},
+				Type:       coreV1.SecretTypeOpaque,
+				Data:       newData,
+			}, metav1.CreateOptions{})
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to save certificates to secret %s/%s: %v", cm.namespace, cm.secretName, err)
+		}
+		klog.Infof("webhook certificates rotated and saved to secret %s/%s", cm.namespace, cm.secretName)
+	}
+
+	cert, err := tls.X509KeyPair(newData[serverCertKey], newData[serverKeyKey])
+	if err != nil {
+		return false, err
+	}
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	changed := !bytes.Equal(cm.caBundle, newData[caCertKey])
+	cm.cert = &cert
+	cm.caBundle = newData[caCertKey]
+	return changed, nil
+}
+
+// rotateIfNeeded 检查证书是否有效, 返回(可能重新生成的)证书数据及是否发生了轮换
+// CA 即将过期时分两步轮换, 保证 api-server 始终信任正在使用的服务器证书:
+// 1. 生成新CA, 只将其加入 caBundle(旧CA + 新CA), 仍使用旧CA签发服务器证书
+// 2. 之后的检查中, published 确认 webhook 配置的 caBundle 已包含新CA后, 使用新CA签发服务器证书, 旧CA保留在 caBundle 中直到下次轮换
+// 没有可用的CA(或旧CA已过期)时直接生成新CA
+func (cm *CertManager) rotateIfNeeded(data map[string][]byte, published func(caCert *x509.Certificate) bool) (map[string][]byte, bool, error) {
+	now := time.Now()
+	caCert, caKey, caErr := parseCA(data[caCertKey], data[caKeyKey])
+	caBundle := data[caCertKey]
+	nextCACert, nextCAKey, nextErr := parseCA(data[nextCACertKey], data[nextCAKeyKey])
+	pending := caErr == nil && nextErr == nil && now.Before(caCert.NotAfter)
+	// rotated 表示证书数据发生了变化, signerChanged 表示签发服务器证书的CA发生了变化
+	rotated, signerChanged := false, false
+	switch {
+	case caErr != nil || !now.Before(caCert.NotAfter):
+		if caErr != nil {
+			klog.Infof("generating webhook CA: %v", caErr)
+		} else {
+			klog.Infof("webhook CA expired at %v, regenerating", caCert.NotAfter)
+		}
+		newCACert, newCAKey, newCAPem, err := generateCA(now)
+		if err != nil {
+			return nil, false, err
+		}
+		caCert, caKey, caBundle = newCACert, newCAKey, newCAPem
+		pending = false
+		rotated, signerChanged = true, true
+	case pending:
+		nextTime, err := time.Parse(time.RFC3339, string(data[nextCATimeKey]))
+		if (err != nil || now.Sub(nextTime) >= caPromoteDelay) && published(nextCACert) {
+			klog.Infof("new webhook CA is trusted by the webhook configuration, signing the serving certificate with it")
+			// 新CA作为第一个block(用于签发), 保留旧CA
+			caBundle = append(encodeCert(nextCACert.Raw), encodeCert(caCert.Raw)...)
+			caCert, caKey = nextCACert, nextCAKey
+			pending = false
+			rotated, signerChanged = true, true
+		}
+	case now.Add(cm.rotateBefore).After(caCert.NotAfter):
+		klog.Infof("webhook CA expires at %v, rotating", caCert.NotAfter)
+		newCACert, newCAKey, newCAPem, err := generateCA(now)
+		if err != nil {
+			return nil, false, err
+		}
+		// 旧CA仍为第一个block(用于签发), 新CA只加入 caBundle
+		caBundle = append(encodeCert(caCert.Raw), newCAPem...)
+		nextCACert, nextCAKey = newCACert, newCAKey
+		data = copyData(data)
+		data[nextCATimeKey] = []byte(now.Format(time.RFC3339))
+		pending = true
+		rotated = true
+	}
+
+	serverCertPem, serverKeyPem := data[serverCertKey], data[serverKeyKey]
+	if signerChanged || !cm.serverCertValid(serverCertPem, serverKeyPem, caCert, now) {
+		var err error
+		serverCertPem, serverKeyPem, err = cm.generateServerCert(caCert, caKey, now)
+		if err != nil {
+			return nil, false, err
+		}
+		rotated = true
+	}
+	if !rotated {
+		return data, false, nil
+	}
+	newData := map[string][]byte{
+		caCertKey:     caBundle,
+		caKeyKey:      encodeKey(caKey),
+		serverCertKey: serverCertPem,
+		serverKeyKey:  serverKeyPem,
+	}
+	if pending {
+		newData[nextCACertKey] = encodeCert(nextCACert.Raw)
+		newData[nextCAKeyKey] = encodeKey(nextCAKey)
+		newData[nextCATimeKey] = data[nextCATimeKey]
+	}
+	return newData, true, nil
+}
+
+// caBundlePublished 判断 webhook 配置的 caBundle 是否已包含 caCert
+// webhook 配置不存在时没有需要保持信任的请求, 视为已包含
+func (cm *CertManager) caBundlePublished(ctx context.Context, caCert *x509.Certificate) bool {
+	webhookConfig, err := cm.client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, webhookConfigName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return true
+	}
+	if err != nil {
+		klog.Warningf("failed to get webhook configuration %s: %v", webhookConfigName, err)
+		return false
+	}
+	caPem := encodeCert(caCert.Raw)
+	for _, webhook := range webhookConfig.Webhooks {
+		if !bytes.Contains(webhook.ClientConfig.CABundle, caPem) {
+			return false
+		}
+	}
+	return len(webhookConfig.Webhooks) > 0
+}
+
+// copyData 复制 secret 的数据
+func copyData(data map[string][]byte) map[string][]byte {
+	result := make(map[string][]byte, len(data)+1)
+	for key, value := range data {
+		result[key] = value
+	}
+	return result
+}
+
+// serverCertValid 判断服务器证书是否由 caCert 签发、且在 rotateBefore 内不会过期
+func (cm *CertManager) serverCertValid(certPem, keyPem []byte, caCert *x509.Certificate, now time.Time) bool {
+	pair, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	if cert.CheckSignatureFrom(caCert) != nil {
+		return false
+	}
+	return now.Add(cm.rotateBefore).Before(cert.NotAfter)
+}
+
+// generateServerCert 使用CA签发 webhook service 的服务器证书
+func (cm *CertManager) generateServerCert(caCert *x509.Certificate, caKey *rsa.PrivateKey, now time.Time) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	serviceDNS := fmt.Sprintf("%s.%s.svc", cm.serviceName, cm.namespace)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: serviceDNS},
+		DNSNames: []string{
+			cm.serviceName,
+			fmt.Sprintf("%s.%s", cm.serviceName, cm.namespace),
+			serviceDNS,
+			serviceDNS + ".cluster.local",
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(cm.validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCert(der), encodeKey(key), nil
+}
+
+// generateCA 生成自签发的CA证书
+func generateCA(now time.Time) (*x509.Certificate, *rsa.PrivateKey, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("mpa_webhook_ca@%d", now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cert, key, encodeCert(der), nil
+}
+
+// parseCA 解析CA证书及秘钥, caPem 中第一个证书为当前用于签发的CA
+func parseCA(caPem, keyPem []byte) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(caPem)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("no CA certificate found")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPem)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("no CA private key found")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		// 新版本 openssl 生成的秘钥为 PKCS8 格式
+		pkcs8Key, pkcs8Err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+		if pkcs8Err != nil {
+			return nil, nil, err
+		}
+		rsaKey, ok := pkcs8Key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("CA private key is not a RSA key")
+		}
+		key = rsaKey
+	}
+	return cert, key, nil
+}
+
+// encodeCert 将 DER 格式的证书编码为 PEM
+func encodeCert(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// encodeKey 将 RSA 秘钥编码为 PEM
+func encodeKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
@@ -3,17 +3,31 @@ package config
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
+	utilPod "multidim-pod-autoscaler/pkg/util/pod"
+	"sync"
 	"time"
 
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 )
 
 const (
-	webhookConfigName = "mpa-webhook-config"
+	// webhookConfigName updater、recommender 通过该配置的 selector 判断 admission 不处理的 pods
+	webhookConfigName = utilPod.WebhookConfigName
+	webhookName       = "mpa.k8s.io"
+	// webhookServicePort webhook service 的端口(api-server 默认值)
+	webhookServicePort = int32(443)
 )
 
 // GetClient 使用 k8s集群内部配置构造并返回 clientset
@@ -40,72 +54,280 @@ func ConfigTLS(clientset *kubernetes.Clientset, serverCert, serverKey []byte) *t
 	}
 }
 
-// WebhookRegistration api-server 注册 admission控制器的webhook配置
-func WebhookRegistration(clientset *kubernetes.Clientset, caCert []byte, namespace, serviceName, url string, registerByURL bool, timeoutSeconds int32) {
-	time.Sleep(10 * time.Second)
-	webhookClient := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations()
-	_, err := webhookClient.Get(context.TODO(), webhookConfigName, metav1.GetOptions{})
-	if err == nil {
-		// 同名webhook已经被配置，先删除后继续配置
-		if err2 := webhookClient.Delete(context.TODO(), webhookConfigName, metav1.DeleteOptions{}); err2 != nil {
-			klog.Fatal(err2)
-		}
+// WebhookOptions 注册 webhook 的配置
+type WebhookOptions struct {
+	// Namespace、ServiceName 为 webhook service 的命名空间和名字
+	Namespace   string
+	ServiceName string
+	// RegisterByURL 为 true 时使用 URL 注册 webhook(否则使用 service)
+	RegisterByURL bool
+	URL           string
+	// TimeoutSeconds api-server 等待 webhook 响应的超时时间
+	TimeoutSeconds int32
+	// FailurePolicy webhook 调用失败时的处理策略: Ignore / Fail
+	FailurePolicy string
+	// NamespaceSelector、ObjectSelector 为 label selector 字符串(如: "a=b,c notin (d)"), 为空时匹配所有对象
+	NamespaceSelector string
+	ObjectSelector    string
+	// ReinvocationPolicy 其他 webhook 修改对象后是否重新调用: Never / IfNeeded
+	ReinvocationPolicy string
+	// MatchPolicy 规则的匹配方式: Exact / Equivalent
+	MatchPolicy string
+}
+
+// Validate 校验 options 中的策略及 selector
+func (o *WebhookOptions) Validate() error {
+	_, err := o.webhookSpec()
+	return err
+}
+
+// webhookSpec 根据 options 构造 webhook 配置(不包含 caBundle)
+// 显式指定 api-server 会设置默认值的字段, 避免与集群中的配置比较时产生误判
+func (o *WebhookOptions) webhookSpec() (*admissionregistration.MutatingWebhook, error) {
+	failurePolicy := admissionregistration.FailurePolicyType(o.FailurePolicy)
+	if failurePolicy != admissionregistration.Ignore && failurePolicy != admissionregistration.Fail {
+		return nil, fmt.Errorf("invalid webhook failure policy %q, must be Ignore or Fail", o.FailurePolicy)
+	}
+	reinvocationPolicy := admissionregistration.ReinvocationPolicyType(o.ReinvocationPolicy)
+	if reinvocationPolicy != admissionregistration.NeverReinvocationPolicy && reinvocationPolicy != admissionregistration.IfNeededReinvocationPolicy {
+		return nil, fmt.Errorf("invalid webhook reinvocation policy %q, must be Never or IfNeeded", o.ReinvocationPolicy)
+	}
+	matchPolicy := admissionregistration.MatchPolicyType(o.MatchPolicy)
+	if matchPolicy != admissionregistration.Exact && matchPolicy != admissionregistration.Equivalent {
+		return nil, fmt.Errorf("invalid webhook match policy %q, must be Exact or Equivalent", o.MatchPolicy)
 	}
-	RegisterClientConfig := admissionregistration.WebhookClientConfig{}
+	namespaceSelector, err := metav1.ParseToLabelSelector(o.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook namespace selector %q: %v", o.NamespaceSelector, err)
+	}
+	objectSelector, err := metav1.ParseToLabelSelector(o.ObjectSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook object selector %q: %v", o.ObjectSelector, err)
+	}
+
+	clientConfig := admissionregistration.WebhookClientConfig{}
 	// url 和 service 必须指定一个
-	if !registerByURL {
-		RegisterClientConfig.Service = &admissionregistration.ServiceReference{
-			Namespace: namespace,
-			Name:      serviceName,
+	if !o.RegisterByURL {
+		port := webhookServicePort
+		clientConfig.Service = &admissionregistration.ServiceReference{
+			Namespace: o.Namespace,
+			Name:      o.ServiceName,
+			Port:      &port,
 		}
 	} else {
-		RegisterClientConfig.URL = &url
+		url := o.URL
+		clientConfig.URL = &url
 	}
 	// 无副作用(如：在回调处理中修改资源等)
 	sideEffects := admissionregistration.SideEffectClassNone
-	// 忽略webhook的错误调用
-	failurePolicy := admissionregistration.Ignore
-	// tls连接的证书(webhook server与 api-server之间通信)
-	RegisterClientConfig.CABundle = caCert
-	webhookConfig := &admissionregistration.MutatingWebhookConfiguration{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: webhookConfigName,
-		},
-		Webhooks: []admissionregistration.MutatingWebhook{
+	timeoutSeconds := o.TimeoutSeconds
+	scope := admissionregistration.AllScopes
+	return &admissionregistration.MutatingWebhook{
+		Name: webhookName,
+		// 优先使用 v1, 兼容只支持 v1beta1 的旧版本集群
+		AdmissionReviewVersions: []string{"v1", "v1beta1"},
+		// webhook 的操作规则
+		Rules: []admissionregistration.RuleWithOperations{
+			// 拦截创建Pod的请求
 			{
-				Name:                    "mpa.k8s.io",
-				AdmissionReviewVersions: []string{"v1beta1"},
-				// webhook 的操作规则
-				Rules: []admissionregistration.RuleWithOperations{
-					// 拦截创建Pod的请求
-					{
-						Operations: []admissionregistration.OperationType{admissionregistration.Create},
-						Rule: admissionregistration.Rule{
-							APIGroups:   []string{""},
-							APIVersions: []string{"v1"},
-							Resources:   []string{"pods"},
-						},
-					},
-					// 拦截创建、更新MPA对象的请求
-					//{
-					//	Operations: []admissionregistration.OperationType{admissionregistration.Create, admissionregistration.Update},
-					//	Rule: admissionregistration.Rule{
-					//		APIGroups:   []string{"autoscaling.k8s.io"},
-					//		APIVersions: []string{"*"},
-					//		Resources:   []string{"multidimpodautoscalers"},
-					//	},
-					//},
+				Operations: []admissionregistration.OperationType{admissionregistration.Create},
+				Rule: admissionregistration.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+					Scope:       &scope,
 				},
-				FailurePolicy:  &failurePolicy,
-				ClientConfig:   RegisterClientConfig,
-				SideEffects:    &sideEffects,
-				TimeoutSeconds: &timeoutSeconds,
 			},
 		},
+		FailurePolicy:      &failurePolicy,
+		MatchPolicy:        &matchPolicy,
+		NamespaceSelector:  namespaceSelector,
+		ObjectSelector:     objectSelector,
+		ReinvocationPolicy: &reinvocationPolicy,
+		ClientConfig:       clientConfig,
+		SideEffects:        &sideEffects,
+		TimeoutSeconds:     &timeoutSeconds,
+	}, nil
+}
+
+// WebhookReconciler 保证 api-server 中的 webhook 配置与期望的配置一致
+// 配置不存在时创建, 被修改(或删除)时修正; 不会删除后重建, 避免滚动升级时出现没有 webhook 的窗口期
+type WebhookReconciler struct {
+	client  kubernetes.Interface
+	webhook *admissionregistration.MutatingWebhook
+
+	lock     sync.Mutex
+	caBundle []byte
+	// caSecretNamespace、caSecretName 保存 caBundle 的 Secret(self-managed-certs 模式), 为空时使用 caBundle
+	caSecretNamespace string
+	caSecretName      string
+	secretLister      corelisters.SecretLister
+	// trigger 通知 reconciler 立即进行一次调谐
+	trigger chan struct{}
+}
+
+// NewWebhookReconciler 构造 WebhookReconciler, options 不合法时返回 error
+func NewWebhookReconciler(client kubernetes.Interface, options *WebhookOptions, caBundle []byte) (*WebhookReconciler, error) {
+	webhook, err := options.webhookSpec()
+	if err != nil {
+		return nil, err
 	}
-	if _, err := webhookClient.Create(context.TODO(), webhookConfig, metav1.CreateOptions{}); err != nil {
-		klog.Fatal(err)
-	} else {
-		klog.V(3).Info("Webhook registration as MutatingWebhook succeeded.")
+	return &WebhookReconciler{
+		client:   client,
+		webhook:  webhook,
+		caBundle: caBundle,
+		trigger:  make(chan struct{}, 1),
+	}, nil
+}
+
+// SetOptions 使用新的 options 更新 webhook 配置(配置热加载时调用), options 不合法时返回 error 且不做修改
+func (wr *WebhookReconciler) SetOptions(options *WebhookOptions) error {
+	webhook, err := options.webhookSpec()
+	if err != nil {
+		return err
+	}
+	wr.lock.Lock()
+	wr.webhook = webhook
+	wr.lock.Unlock()
+	wr.enqueue()
+	return nil
+}
+
+// SetCABundle 更新 webhook 配置的 caBundle(证书轮换后调用)
+func (wr *WebhookReconciler) SetCABundle(caBundle []byte) {
+	wr.lock.Lock()
+	wr.caBundle = caBundle
+	wr.lock.Unlock()
+	wr.enqueue()
+}
+
+// UseCABundleFromSecret 调谐时从 Secret 中读取 caBundle, 需要在 Run 之前调用
+// Secret 被所有副本共享, 避免还未重新加载证书的副本用内存中旧的 caBundle 覆盖其他副本轮换CA后的配置
+func (wr *WebhookReconciler) UseCABundleFromSecret(namespace, secretName string) {
+	wr.caSecretNamespace = namespace
+	wr.caSecretName = secretName
+}
+
+// enqueue 通知 reconciler 进行一次调谐
+func (wr *WebhookReconciler) enqueue() {
+	select {
+	case wr.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run 监听 webhook 配置的变化并进行调谐, 同时每隔 resyncPeriod 调谐一次, 直到 ctx 结束
+func (wr *WebhookReconciler) Run(ctx context.Context, resyncPeriod time.Duration) {
+	factory := informers.NewSharedInformerFactoryWithOptions(wr.client, resyncPeriod,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", webhookConfigName).String()
+		}))
+	factory.Admissionregistration().V1().MutatingWebhookConfigurations().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { wr.enqueue() },
+			UpdateFunc: func(interface{}, interface{}) { wr.enqueue() },
+			DeleteFunc: func(interface{}) { wr.enqueue() },
+		})
+	factory.Start(ctx.Done())
+	if wr.caSecretName != "" {
+		secretFactory := informers.NewSharedInformerFactoryWithOptions(wr.client, resyncPeriod,
+			informers.WithNamespace(wr.caSecretNamespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", wr.caSecretName).String()
+			}))
+		secretInformer := secretFactory.Core().V1().Secrets()
+		secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { wr.enqueue() },
+			UpdateFunc: func(interface{}, interface{}) { wr.enqueue() },
+		})
+		wr.secretLister = secretInformer.Lister()
+		secretFactory.Start(ctx.Done())
+		if !cache.WaitForCacheSync(ctx.Done(), secretInformer.Informer().HasSynced) {
+			return
+		}
+	}
+
+	wr.enqueue()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-wr.trigger:
+		}
+		if err := wr.reconcile(ctx); err != nil {
+			klog.Errorf("failed to reconcile webhook %s: %v", webhookConfigName, err)
+			// 稍后重试
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			wr.enqueue()
+		}
+	}
+}
+
+// reconcile 创建或更新 webhook 配置
+func (wr *WebhookReconciler) reconcile(ctx context.Context) error {
+	wr.lock.Lock()
+	desired := wr.webhook.DeepCopy()
+	desired.ClientConfig.CABundle = wr.caBundle
+	wr.lock.Unlock()
+	if wr.secretLister != nil {
+		secret, err := wr.secretLister.Secrets(wr.caSecretNamespace).Get(wr.caSecretName)
+		if err == nil && len(secret.Data[caCertKey]) > 0 {
+			desired.ClientConfig.CABundle = secret.Data[caCertKey]
+		} else {
+			klog.Warningf("no CA bundle found in secret %s/%s, using the local one: %v", wr.caSecretNamespace, wr.caSecretName, err)
+		}
+	}
+
+	webhookClient := wr.client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	current, err := webhookClient.Get(ctx, webhookConfigName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = webhookClient.Create(ctx, &admissionregistration.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: webhookConfigName,
+			},
+			Webhooks: []admissionregistration.MutatingWebhook{*desired},
+		}, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			// 其他副本已创建, 下次调谐时检查其内容
+			return nil
+		}
+		if err == nil {
+			klog.V(3).Info("Webhook registration as MutatingWebhook succeeded.")
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(current.Webhooks) == 1 && equality.Semantic.DeepEqual(current.Webhooks[0], *desired) {
+		return nil
+	}
+	// 配置被修改(或为旧版本的配置), 原地更新; resourceVersion 冲突时重新调谐
+	updated := current.DeepCopy()
+	updated.Webhooks = []admissionregistration.MutatingWebhook{*desired}
+	if _, err := webhookClient.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.V(3).Info("Webhook configuration drifted and has been updated.")
+	return nil
+}
+
+// Cleanup 删除 webhook 配置(组件正常退出时调用)
+func (wr *WebhookReconciler) Cleanup(ctx context.Context) {
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		err := wr.client.AdmissionregistrationV1().MutatingWebhookConfigurations().Delete(ctx, webhookConfigName, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("failed to remove webhook %s: %v", webhookConfigName, err)
+			return false, nil
+		}
+		return true, nil
+	}, ctx.Done())
+	if err == nil {
+		klog.V(3).Infof("Webhook %s removed.", webhookConfigName)
 	}
 }
@@ -5,5 +5,5 @@ set -e
 
 echo "Unregistering MPA admission controller webhook"
 
-kubectl delete -n kube-system mutatingwebhookconfiguration.v1beta1.admissionregistration.k8s.io mpa-webhook-config
+kubectl delete -n kube-system mutatingwebhookconfiguration.v1.admissionregistration.k8s.io mpa-webhook-config
 
@@ -10,8 +10,10 @@ import (
 	patchUtil "multidim-pod-autoscaler/pkg/util/patch"
 	"net/http"
 
+	admissionV1 "k8s.io/api/admission/v1"
 	"k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreListers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 )
 
@@ -22,35 +24,72 @@ type AdmissionServer struct {
 // NewAdmissionServer 构造一个新的 AdmissionServer
 func NewAdmissionServer(
 	mpaMatcher mpaApi.Matcher,
+	namespaceLister coreListers.NamespaceLister,
 	patchesCalculators []admissionUtil.PatchCalculator,
 ) *AdmissionServer {
 	as := &AdmissionServer{
 		resourcesHandler: map[metav1.GroupResource]admissionUtil.Handler{},
 	}
-	podHandler := pod.NewPodHandler(mpaMatcher, patchesCalculators)
+	podHandler := pod.NewPodHandler(mpaMatcher, namespaceLister, patchesCalculators)
 	as.resourcesHandler[podHandler.GroupResource()] = podHandler
 
 	return as
 }
 
+// supportedReviewVersions webhook server 支持的 AdmissionReview 版本
+// v1beta1 已在 k8s 1.22 中移除, 保留以兼容旧版本集群
+var supportedReviewVersions = map[string]bool{
+	admissionV1.SchemeGroupVersion.String(): true,
+	v1beta1.SchemeGroupVersion.String():     true,
+}
+
+// decodeAdmissionReview 解析 admission review 请求
+// v1 与 v1beta1 的 AdmissionReview 结构完全相同, 统一解析为 v1, 并保留请求的 apiVersion
+func decodeAdmissionReview(data []byte) (*admissionV1.AdmissionReview, error) {
+	review := &admissionV1.AdmissionReview{}
+	if err := json.Unmarshal(data, review); err != nil {
+		return nil, err
+	}
+	if review.APIVersion == "" {
+		// 未指定版本时按 v1beta1 处理(旧版本 api-server)
+		review.APIVersion = v1beta1.SchemeGroupVersion.String()
+	}
+	if !supportedReviewVersions[review.APIVersion] {
+		return nil, fmt.Errorf("unsupported AdmissionReview version %q", review.APIVersion)
+	}
+	if review.Request == nil {
+		return nil, fmt.Errorf("AdmissionReview has no request")
+	}
+	return review, nil
+}
+
 // admitting 处理给定的请求，返回处理结果(patches等)
+// 返回的 AdmissionReview 与请求的版本相同, 且回显请求的 UID
 func (as *AdmissionServer) admitting(
 	data []byte,
-) (*v1beta1.AdmissionResponse, admissionUtil.AdmissionStatus, admissionUtil.AdmissionResource) {
-	response := v1beta1.AdmissionResponse{}
+) (*admissionV1.AdmissionReview, admissionUtil.AdmissionStatus, admissionUtil.AdmissionResource) {
+	response := admissionV1.AdmissionResponse{}
 	// 允许 admission request
 	response.Allowed = true
+	review := &admissionV1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionV1.SchemeGroupVersion.String(),
+			Kind:       "AdmissionReview",
+		},
+		Response: &response,
+	}
 
 	// 解析admission request 请求
-	admissionRequest := v1beta1.AdmissionReview{}
-	if err := json.Unmarshal(data, &admissionRequest); err != nil {
+	admissionRequest, err := decodeAdmissionReview(data)
+	if err != nil {
 		klog.Errorf("connot parse the admission request: %v", err)
-		return &response, admissionUtil.Error, admissionUtil.Unknown
+		return review, admissionUtil.Error, admissionUtil.Unknown
 	}
+	review.APIVersion = admissionRequest.APIVersion
+	response.UID = admissionRequest.Request.UID
 
 	resource := admissionUtil.Unknown
 	patches := make([]patchUtil.Patch, 0)
-	var err error
 
 	// 设置可接受的 Group Resource
 	admissionedGroupResource := metav1.GroupResource{
@@ -69,7 +108,7 @@ func (as *AdmissionServer) admitting(
 
 	if err != nil {
 		klog.Errorf("errors occored while handling admission request: %v", err)
-		return &response, admissionUtil.Error, resource
+		return review, admissionUtil.Error, resource
 	}
 
 	status := admissionUtil.Skipped
@@ -79,22 +118,22 @@ func (as *AdmissionServer) admitting(
 		plainPatches, err := json.Marshal(patches)
 		if err != nil {
 			klog.Errorf("connot marshal the patches %v: %v", patches, err)
-			return &response, admissionUtil.Error, resource
+			return review, admissionUtil.Error, resource
 		}
 		klog.V(4).Infof("admission get pods' patches: %v", string(plainPatches))
-		patchType := v1beta1.PatchTypeJSONPatch
+		patchType := admissionV1.PatchTypeJSONPatch
 		response.PatchType = &patchType
 		response.Patch = plainPatches
 
 		status = admissionUtil.Applied
 	}
 
-	// 统计被处理的pod的个数
-	if resource == admissionUtil.Pod {
-		admissionUtil.OnAppliedPod(status == admissionUtil.Applied)
+	// 统计被处理的pod的个数(未修改的pod由 handler 按原因统计, 包括 MPA 控制但不需要修改的pod)
+	if resource == admissionUtil.Pod && status == admissionUtil.Applied {
+		admissionUtil.OnAppliedPod(true)
 	}
 
-	return &response, status, resource
+	return review, status, resource
 }
 
 // Serve 完成一次webhook的回调执行流程
@@ -117,10 +156,7 @@ func (as *AdmissionServer) Serve(writer http.ResponseWriter, request *http.Reque
 		return
 	}
 
-	response, status, resource := as.admitting(body)
-	admissionReview := v1beta1.AdmissionReview{
-		Response: response,
-	}
+	admissionReview, status, resource := as.admitting(body)
 
 	// 打包回复数据
 	finalResponse, err := json.Marshal(admissionReview)
@@ -131,6 +167,7 @@ func (as *AdmissionServer) Serve(writer http.ResponseWriter, request *http.Reque
 	}
 
 	// 写入回复包
+	writer.Header().Set("Content-Type", "application/json")
 	if _, err := writer.Write(finalResponse); err != nil {
 		klog.Error(err)
 		timer.Observe(admissionUtil.Error, resource)
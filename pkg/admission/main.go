@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	cliFlag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog"
 	"multidim-pod-autoscaler/pkg/admission/config"
@@ -15,9 +18,11 @@ import (
 	clientSet "multidim-pod-autoscaler/pkg/client/clientset/versioned"
 	"multidim-pod-autoscaler/pkg/target"
 	"multidim-pod-autoscaler/pkg/util"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+	"multidim-pod-autoscaler/pkg/util/diagnostics"
 	"multidim-pod-autoscaler/pkg/util/limitrange"
-	metricsUtil "multidim-pod-autoscaler/pkg/util/metrics"
 	mpaUtil "multidim-pod-autoscaler/pkg/util/mpa"
+	"multidim-pod-autoscaler/pkg/util/policy"
 	"multidim-pod-autoscaler/pkg/util/recommendation"
 	"net/http"
 	"os"
@@ -35,8 +40,14 @@ var (
 		TlsCertFile:   flag.String("tls-cert-file", "/etc/mpa-tls-certs/serverCert.pem", "server证书的路径"),
 		TlsPrivateKey: flag.String("tls-private-key", "/etc/mpa-tls-certs/serverKey.pem", "server秘钥的路径"),
 	}
+	selfManagedCerts   = flag.Bool("self-managed-certs", false, "自行生成CA及server证书并保存在 Secret 中, 到期前自动轮换(不再读取证书文件)")
+	certsSecretName    = flag.String("certs-secret-name", "mpa-webhook-certs", "self-managed-certs 模式下保存证书的 Secret 名字")
+	certsValidity      = flag.Duration("certs-validity", 365*24*time.Hour, "self-managed-certs 模式下server证书的有效期")
+	certsRotateBefore  = flag.Duration("certs-rotate-before", 30*24*time.Hour, "self-managed-certs 模式下证书到期前多久进行轮换")
+	certsCheckInterval = flag.Duration("certs-check-interval", time.Hour, "self-managed-certs 模式下检查证书是否需要轮换的时间间隔")
 	port               = flag.Int("port", 8000, "webhook server 监听的端口号")
-	prometheusAddress  = flag.String("address", ":8944", "Prometheus metrics对外暴露的地址")
+	prometheusAddress  = flag.String("address", ":8944", "诊断 server(Prometheus metrics、/healthz、/readyz)对外暴露的地址")
+	profiling          = flag.Bool("profiling", false, "在诊断 server 上暴露 /debug/pprof")
 	kubeconfig         = flag.String("kubeconfig", "", "Path to kubeconfig. 使用out-cluster配置时指定")
 	kubeApiQps         = flag.Float64("kube-api-qps", 5.0, "访问API-Server的 QPS 限制")
 	kubeApiBurst       = flag.Int("kube-api-burst", 10, "访问API-Server的 QPS 峰值限制")
@@ -44,34 +55,61 @@ var (
 	serviceName        = flag.String("webhook-service", "mpa-webhook", "当不使用url注册webhook时，需要指定webhook的服务名")
 	webhookTimeout     = flag.Int("webhook-timeout-seconds", 30, "API-Server等待webhook响应的超时时间")
 	mpaObjectNamespace = flag.String("mpa-object-namespace", corev1.NamespaceAll, "搜索MPA Objects的命名空间")
+
+	webhookFailurePolicy     = flag.String("webhook-failure-policy", "Ignore", "webhook调用失败时的处理策略(Ignore/Fail)")
+	webhookNamespaceSelector = flag.String("webhook-namespace-selector", "kubernetes.io/metadata.name notin (kube-system),mpa.k8s.io/skip notin (true)",
+		"webhook的 namespaceSelector(label selector, 如 \"a=b,c notin (d)\"), 为空时匹配所有命名空间")
+	webhookObjectSelector = flag.String("webhook-object-selector", "mpa.k8s.io/skip notin (true)",
+		"webhook的 objectSelector(label selector), 为空时匹配所有pod")
+	webhookReinvocationPolicy = flag.String("webhook-reinvocation-policy", "Never", "其他webhook修改pod后是否重新调用(Never/IfNeeded)")
+	webhookMatchPolicy        = flag.String("webhook-match-policy", "Equivalent", "webhook规则的匹配方式(Exact/Equivalent)")
+	webhookResyncPeriod       = flag.Duration("webhook-resync-period", time.Minute, "检查webhook配置是否被修改的时间间隔")
+	webhookRemoveOnShutdown   = flag.Bool("webhook-remove-on-shutdown", false, "正常退出时删除webhook配置(多副本部署时不建议开启)")
 )
 
 func main() {
 	klog.InitFlags(nil)
 	cliFlag.InitFlags()
 
-	klog.V(1).Info("Multidim Pod Autoscaler(%s) Admission Controller", mpaUtil.MultidimPodAutoscalerVersion)
+	klog.V(1).Infof("Multidim Pod Autoscaler(%s) Admission Controller", mpaUtil.MultidimPodAutoscalerVersion)
+
+	// 配置文件(可选)中的值被命令行中显式指定的参数覆盖
+	configData, err := utilConfig.ReadFile(*configFile)
+	if err != nil {
+		klog.Fatalf("failed to load configuration: %v", err)
+	}
+	componentConfig, err := loadConfiguration(configData)
+	if err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
 
-	// 初始化 prometheus metrics
-	metricsUtil.InitializeMetrics(*prometheusAddress)
+	// 收到 SIGTERM 时结束根 context, 停止 webhook server 及 informer
+	ctx := util.SetupSignalContext()
+	stopCh := ctx.Done()
+
+	// 诊断 server: informer 缓存同步完成、webhook server 开始监听时 ready
+	diagnosticsServer := diagnostics.NewServer(componentConfig.Diagnostics.Address, componentConfig.Diagnostics.EnableProfiling)
+	informersSynced := diagnostics.NewCondition("informer caches have not synced")
+	serving := diagnostics.NewCondition("webhook server is not serving")
+	diagnosticsServer.AddReadyCheck("informers", informersSynced.Check)
+	diagnosticsServer.AddReadyCheck("webhook", serving.Check)
+	diagnosticsServer.Start()
 	// 注册 admission controller用到的 metrics tools
 	admissionUtil.RegisterMetrics()
 
-	// 初始化 tls 证书配置
-	certs := config.InitCerts(*certsConfiguration)
 	// 创建kubeconfig
-	kubeconfig := util.CreateKubeConfig(*kubeconfig, float32(*kubeApiQps), *kubeApiBurst)
+	kubeconfig := util.CreateKubeConfig(*kubeconfig, componentConfig.ClientConnection.QPS, int(componentConfig.ClientConnection.Burst))
 
 	// 创建 mpa lister(获取所有mpa对象)
 	mpaClientset := clientSet.NewForConfigOrDie(kubeconfig)
-	mpaLister := mpaUtil.NewMpasLister(mpaClientset, *mpaObjectNamespace, make(chan struct{}))
+	mpaLister := mpaUtil.NewMpasLister(mpaClientset, componentConfig.MpaObjectNamespace, stopCh)
 	// 创建informerFactory 及 mpa target ref选择器 fetcher
 	kubeClient := kubernetes.NewForConfigOrDie(kubeconfig)
 	informerFactory := informers.NewSharedInformerFactory(kubeClient, defaultResyncPeriod)
-	mpaTargetSelectorFetcher := target.NewMpaTargetSelectorFetcher(kubeconfig, kubeClient, informerFactory)
+	mpaTargetSelectorFetcher := target.NewMpaTargetSelectorFetcher(kubeconfig, kubeClient, informerFactory, stopCh)
 
 	// 创建 recommendation 获取器
-	limitRangeCalculator, err := limitrange.NewCalculator(informerFactory)
+	limitRangeCalculator, err := limitrange.NewCalculator(informerFactory, stopCh)
 	if err != nil {
 		klog.Errorf("failed to create limitRangeCalculator, err: %v", err)
 	}
@@ -79,20 +117,101 @@ func main() {
 	recommendationProvider := admissionUtil.NewRecommendationProvider(limitRangeCalculator, recommedendationProcessor)
 
 	// 创建mpa matcher & patchesCalculators
-	mpaMatcher := mpaUtil.NewMatcher(mpaLister, mpaTargetSelectorFetcher)
+	// matcher 返回的mpa合并了选中它的默认策略
+	policyResolver, err := policy.NewResolver(kubeClient, mpaClientset, componentConfig.MpaObjectNamespace, nil, stopCh)
+	if err != nil {
+		klog.Fatalf("failed to create MPA policy resolver: %v", err)
+	}
+	mpaMatcher := mpaUtil.NewMatcher(mpaLister, mpaTargetSelectorFetcher, policyResolver)
 	patchesCalculators := []admissionUtil.PatchCalculator{
 		podPatch.NewObservedPodPatchCalculator(),
 		podPatch.NewResourceUpdatesPatchCalculator(recommendationProvider),
 	}
 
-	admissionServer := logic.NewAdmissionServer(mpaMatcher, patchesCalculators)
-	http.HandleFunc("/", admissionServer.Serve)
+	// 命名空间 lister(检查命名空间是否禁用了MPA)
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	namespaceLister := namespaceInformer.Lister()
+	go namespaceInformer.Informer().Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, namespaceInformer.Informer().HasSynced) {
+		klog.Fatalf("failed to sync namespace cache")
+	}
+	informersSynced.Set()
+
+	// webhook 使用独立的 mux, 与诊断 server 分开
+	admissionServer := logic.NewAdmissionServer(mpaMatcher, namespaceLister, patchesCalculators)
+	webhookMux := http.NewServeMux()
+	webhookMux.HandleFunc("/", admissionServer.Serve)
 
 	webhookServer := &http.Server{
-		Addr:      fmt.Sprintf(":%d", *port),
-		TLSConfig: config.ConfigTLS(kubeClient, certs.ServerCert, certs.ServerKey),
+		Addr:    fmt.Sprintf(":%d", componentConfig.Port),
+		Handler: webhookMux,
 	}
+	var caCert []byte
+	var certManager *config.CertManager
+	certs := &componentConfig.Certs
+	if certs.SelfManaged {
+		// 自行管理证书: 通过 GetCertificate 热加载轮换后的证书
+		certManager = config.NewCertManager(kubeClient, namespace, certs.SecretName, componentConfig.Webhook.Service,
+			certs.Validity.Duration, certs.RotateBefore.Duration)
+		if err := certManager.Init(ctx); err != nil {
+			klog.Fatalf("failed to initialize webhook certificates: %v", err)
+		}
+		caCert = certManager.CABundle()
+		webhookServer.TLSConfig = &tls.Config{GetCertificate: certManager.GetCertificate}
+	} else {
+		// 初始化 tls 证书配置
+		certsFiles := config.InitCerts(config.CertsConfig{
+			ClientCaFile:  &certs.ClientCaFile,
+			TlsCertFile:   &certs.TlsCertFile,
+			TlsPrivateKey: &certs.TlsPrivateKey,
+		})
+		caCert = certsFiles.CaCert
+		webhookServer.TLSConfig = config.ConfigTLS(kubeClient, certsFiles.ServerCert, certsFiles.ServerKey)
+	}
+
+	// 注册 webhook, 并保证其配置不被修改
+	webhookReconciler, err := config.NewWebhookReconciler(kubeClient, webhookOptions(componentConfig), caCert)
+	if err != nil {
+		klog.Fatalf("invalid webhook configuration: %v", err)
+	}
+	if certManager != nil {
+		webhookReconciler.UseCABundleFromSecret(namespace, certs.SecretName)
+	}
+	go webhookReconciler.Run(ctx, componentConfig.Webhook.ResyncPeriod.Duration)
+	if certManager != nil {
+		go certManager.Run(ctx, certs.CheckInterval.Duration, webhookReconciler.SetCABundle)
+	}
+	reloader := newConfigurationReloader(componentConfig, webhookReconciler)
+	if *configFile != "" {
+		// 配置文件变化时热加载
+		go utilConfig.NewWatcher(*configFile, *configReloadPeriod, configData, reloader.reload).Run(ctx)
+	}
+
+	// 收到退出信号时, 停止 webhook server(可选地删除 webhook 配置)
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
 
-	go config.WebhookRegistration(kubeClient, certs.CaCert, namespace, *serviceName, "", false, int32(*webhookTimeout))
-	webhookServer.ListenAndServeTLS("", "")
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		if reloader.webhookRemoveOnShutdown() {
+			webhookReconciler.Cleanup(shutdownCtx)
+		}
+		if err := webhookServer.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("failed to shutdown webhook server: %v", err)
+		}
+		if err := diagnosticsServer.Shutdown(shutdownCtx); err != nil {
+			klog.Errorf("failed to shutdown diagnostics server: %v", err)
+		}
+	}()
+
+	serving.Set()
+	if err := webhookServer.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		klog.Fatalf("webhook server exited: %v", err)
+	}
+	// ListenAndServeTLS 在 Shutdown 开始时即返回, 等待处理中的请求完成
+	<-shutdownDone
+	klog.Infof("MPA admission controller stopped")
+	klog.Flush()
 }
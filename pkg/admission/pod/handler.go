@@ -3,25 +3,31 @@ package pod
 import (
 	"encoding/json"
 	"fmt"
-	"k8s.io/api/admission/v1beta1"
+	admissionV1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreListers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 	podPatch "multidim-pod-autoscaler/pkg/admission/pod/patch"
 	admissionUtil "multidim-pod-autoscaler/pkg/admission/util"
+	"multidim-pod-autoscaler/pkg/util/annotations"
 	mpaApi "multidim-pod-autoscaler/pkg/util/mpa"
 	patchUtil "multidim-pod-autoscaler/pkg/util/patch"
 )
 
 type podHandler struct {
 	mpaMatcher       mpaApi.Matcher
+	namespaceLister  coreListers.NamespaceLister
 	patchCalculators []admissionUtil.PatchCalculator
 }
 
 // NewPodHandler 返回一个新的 pod handler 用于计算pod的patch
-func NewPodHandler(mpaMatcher mpaApi.Matcher, patchCalculators []admissionUtil.PatchCalculator) admissionUtil.Handler {
+// namespaceLister 用于检查命名空间是否禁用了MPA, 为 nil 时不检查
+func NewPodHandler(mpaMatcher mpaApi.Matcher, namespaceLister coreListers.NamespaceLister, patchCalculators []admissionUtil.PatchCalculator) admissionUtil.Handler {
 	return &podHandler{
 		mpaMatcher:       mpaMatcher,
+		namespaceLister:  namespaceLister,
 		patchCalculators: patchCalculators,
 	}
 }
@@ -40,7 +46,7 @@ func (ph *podHandler) GroupResource() metaV1.GroupResource {
 }
 
 // GetPatches 实现 handler接口，用于计算 admission request中指定的pod的patches
-func (ph *podHandler) GetPatches(ar *v1beta1.AdmissionRequest) ([]patchUtil.Patch, error) {
+func (ph *podHandler) GetPatches(ar *admissionV1.AdmissionRequest) ([]patchUtil.Patch, error) {
 	if ar.Resource.Version != "v1" {
 		return nil, fmt.Errorf("only v1 pods are supported")
 	}
@@ -59,10 +65,29 @@ func (ph *podHandler) GetPatches(ar *v1beta1.AdmissionRequest) ([]patchUtil.Patc
 
 	klog.V(4).Infof("Admitting Pod: name=%s,namespace=%s,generateName=%s", pod.Name, pod.Namespace, pod.GenerateName)
 
+	// pod 或其所在的命名空间通过 annotation 跳过MPA
+	if annotations.IsPodSkipped(&pod) {
+		klog.V(4).Infof("Pod %s/%s skipped by annotation %s", namespace, pod.Name, annotations.MpaSkipAnnotation)
+		admissionUtil.OnSkippedPod(admissionUtil.PodAnnotation)
+		return []patchUtil.Patch{}, nil
+	}
+	if ph.namespaceLister != nil {
+		ns, err := ph.namespaceLister.Get(namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && annotations.IsNamespaceSkipped(ns) {
+			klog.V(4).Infof("Pod %s/%s skipped, MPA is disabled in namespace %s", namespace, pod.Name, namespace)
+			admissionUtil.OnSkippedPod(admissionUtil.NamespaceAnnotation)
+			return []patchUtil.Patch{}, nil
+		}
+	}
+
 	// 获取控制该pod的MPA 对象
 	controllingMpa := ph.mpaMatcher.GetPodMatchingMpa(&pod)
 	if controllingMpa == nil {
 		klog.V(4).Infof("No Matching MPA found for pod %s-%s", pod.Namespace, pod.Name)
+		admissionUtil.OnSkippedPod(admissionUtil.NoMatchingMpa)
 		return []patchUtil.Patch{}, nil
 	}
 
@@ -78,6 +103,9 @@ func (ph *podHandler) GetPatches(ar *v1beta1.AdmissionRequest) ([]patchUtil.Patc
 		}
 		patches = append(patches, subPatches...)
 	}
+	if len(patches) == 0 {
+		admissionUtil.OnSkippedPod(admissionUtil.NoPatches)
+	}
 
 	return patches, nil
 }
@@ -50,6 +50,10 @@ func (r *resourceUpdatesPatchCalculator) CalculatePatches(
 
 	updatesAnnotations := make([]string, 0)
 	for i, containerRecomm := range containersRecommendedResources {
+		// 容器未被伸缩器控制(或没有推荐方案), 不修改
+		if len(containerRecomm.Requests) == 0 && len(containerRecomm.Limits) == 0 {
+			continue
+		}
 		newPatches, newAnnotations, newUpdateAnnotation := getContainerPatch(pod, i, containerRecomm)
 		patches = append(patches, newPatches...)
 		annotationsPerContainer[pod.Spec.Containers[i].Name] = append(annotationsPerContainer[pod.Spec.Containers[i].Name], newAnnotations...)
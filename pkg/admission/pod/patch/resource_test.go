@@ -0,0 +1,122 @@
+package patch
+
+import (
+	"strings"
+	"testing"
+
+	admissionUtil "multidim-pod-autoscaler/pkg/admission/util"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCalculatePatches(t *testing.T) {
+	requestsOnly := mpaTypes.ContainerControlledRequestsOnly
+	off := mpaTypes.ContainerScalingModeOff
+	cpuOnly := []corev1.ResourceName{corev1.ResourceCPU}
+
+	tests := []struct {
+		name   string
+		policy mpaTypes.ContainerResourcePolicy
+		// expectedPaths 为 container 0 应有的 patch 路径, unexpectedPaths 为不应出现的路径前缀
+		expectedPaths   []string
+		unexpectedPaths []string
+	}{
+		{
+			name:   "limits untouched under RequestsOnly",
+			policy: mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledMode: &requestsOnly},
+			expectedPaths: []string{
+				"/spec/containers/0/resources/requests/cpu",
+				"/spec/containers/0/resources/requests/memory",
+			},
+			unexpectedPaths: []string{"/spec/containers/0/resources/limits"},
+		},
+		{
+			name:   "memory not patched when ControlledResources is [cpu]",
+			policy: mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledResources: &cpuOnly},
+			expectedPaths: []string{
+				"/spec/containers/0/resources/requests/cpu",
+				"/spec/containers/0/resources/limits/cpu",
+			},
+			unexpectedPaths: []string{
+				"/spec/containers/0/resources/requests/memory",
+				"/spec/containers/0/resources/limits/memory",
+			},
+		},
+		{
+			name:            "container with mode Off gets no patch",
+			policy:          mpaTypes.ContainerResourcePolicy{ContainerName: "app", Mode: &off},
+			unexpectedPaths: []string{"/spec/containers/0/"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "app",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("250m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("500m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+							},
+						},
+					},
+				},
+			}
+			mpa := &mpaTypes.MultidimPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "mpa", Namespace: "default"},
+				Spec: mpaTypes.MultidimPodAutoscalerSpec{
+					ResourcePolicy: &mpaTypes.PodResourcePolicy{
+						ContainerPolicies: []mpaTypes.ContainerResourcePolicy{tc.policy},
+					},
+				},
+				Status: mpaTypes.MultidimPodAutoscalerStatus{
+					RecommendationResources: &mpaTypes.RecommendedResources{
+						TargetPodNum: 2,
+						ContainerRecommendations: []mpaTypes.RecommendedContainerResources{
+							{
+								ContainerName: "app",
+								Target: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("1"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+						},
+					},
+				},
+			}
+
+			calculator := NewResourceUpdatesPatchCalculator(admissionUtil.NewRecommendationProvider(nil, nil))
+			patches, err := calculator.CalculatePatches(pod, mpa)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			paths := map[string]bool{}
+			for _, p := range patches {
+				paths[p.Path] = true
+			}
+			for _, expected := range tc.expectedPaths {
+				if !paths[expected] {
+					t.Errorf("expected patch %s, got %v", expected, patches)
+				}
+			}
+			for _, p := range patches {
+				for _, unexpected := range tc.unexpectedPaths {
+					if strings.HasPrefix(p.Path, unexpected) {
+						t.Errorf("unexpected patch %s", p.Path)
+					}
+				}
+			}
+		})
+	}
+}
@@ -41,6 +41,20 @@ const (
 	Mpa AdmissionResource = "mpa"
 )
 
+// PodSkipReason 表示 pod 未被 admission 修改的原因
+type PodSkipReason string
+
+const (
+	// NoMatchingMpa 没有MPA控制该pod
+	NoMatchingMpa PodSkipReason = "no_matching_mpa"
+	// PodAnnotation pod 通过 annotation 跳过MPA
+	PodAnnotation PodSkipReason = "pod_annotation"
+	// NamespaceAnnotation pod 所在的命名空间通过 annotation 禁用了MPA
+	NamespaceAnnotation PodSkipReason = "namespace_annotation"
+	// NoPatches MPA 控制该pod, 但不需要修改(如: 还没有推荐方案)
+	NoPatches PodSkipReason = "no_patches"
+)
+
 var (
 	admissionPodCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -48,7 +62,7 @@ var (
 			Name:      "admission_pods_total",
 			Help:      "MPA Admission 处理的 Pod 总数",
 		},
-		[]string{string(Applied)},
+		[]string{string(Applied), "skip_reason"},
 	)
 	admissionLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -66,10 +80,14 @@ func RegisterMetrics() {
 }
 
 // OnAppliedPod 更新admission处理的pod的计数器
-// 应用成功的才计数
 // 首次调用, 会创建 label为 "true" 的 Counter
 func OnAppliedPod(applied bool) {
-	admissionPodCount.WithLabelValues(fmt.Sprintf("%v", applied)).Add(1)
+	admissionPodCount.WithLabelValues(fmt.Sprintf("%v", applied), "").Add(1)
+}
+
+// OnSkippedPod 更新admission跳过的pod的计数器(按跳过原因分类)
+func OnSkippedPod(reason PodSkipReason) {
+	admissionPodCount.WithLabelValues(fmt.Sprintf("%v", false), string(reason)).Add(1)
 }
 
 // AdmissionLatencyTimer 测量 admission 中某过程的执行或延迟时间
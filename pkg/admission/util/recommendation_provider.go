@@ -5,7 +5,9 @@ import (
 	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
 	containerUtil "multidim-pod-autoscaler/pkg/util/container"
 	"multidim-pod-autoscaler/pkg/util/limitrange"
+	mpaApi "multidim-pod-autoscaler/pkg/util/mpa"
 	recommendationUtil "multidim-pod-autoscaler/pkg/util/recommendation"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
@@ -64,6 +66,7 @@ func (r *recommendationProvider) GetContainerResourcesForPod(
 }
 
 // getContainersResources 获取容器的推荐资源
+// 只修改容器被控制的资源(ControlledResources); RequestsOnly 时不修改 limit; 容器的伸缩模式为 Off 时不修改该容器
 func getContainersResources(
 	pod *corev1.Pod,
 	podPolicy *mpaTypes.PodResourcePolicy,
@@ -79,6 +82,10 @@ func getContainersResources(
 	annotations := make(recommendationUtil.ContainerAnnotationsMap)
 
 	for i, container := range pod.Spec.Containers {
+		if mpaApi.GetContainerScalingMode(container.Name, podPolicy) == mpaTypes.ContainerScalingModeOff {
+			klog.V(4).Infof("container %s scaling mode is Off, skipped", container.Name)
+			continue
+		}
 		// 获取容器的推荐资源
 		containerRecomm := recommendationUtil.GetContainerRecommendation(container.Name, recommendation.ContainerRecommendations)
 
@@ -86,33 +93,76 @@ func getContainersResources(
 			klog.V(2).Infof("no matching recommendation found for container %s", container.Name)
 			continue
 		} else {
-			// 推荐不为空 设为requests
-			resources[i].Requests = containerRecomm.Target
+			// 推荐不为空 设为requests(只保留被控制的资源)
+			controlledResources := mpaApi.GetContainerControlledResources(container.Name, podPolicy)
+			resources[i].Requests = filterControlledResources(containerRecomm.Target, controlledResources)
+		}
+		if len(resources[i].Requests) == 0 {
+			continue
 		}
 
 		var defaultLimit corev1.ResourceList
 		if limitRange != nil {
 			defaultLimit = limitRange.Default
 		}
-		//containerControlledMode := mpaApi.GetContainerControlledMode(container.Name, podPolicy)
-		//if containerControlledMode == mpaTypes.ContainerControlledRequestsAndLimits {
-		// 需要同时伸缩 request 和 limit
-		recommLimit, anotation := containerUtil.GetProportionalLimit(
-			container.Resources.Limits, container.Resources.Requests,
-			resources[i].Requests, defaultLimit)
-
-		if recommLimit != nil && len(recommLimit) != 0 {
-			// 设置伸缩后的limit
-			resources[i].Limits = recommLimit
-			if len(anotation) > 0 {
-				annotations[container.Name] = anotation
+		containerControlledMode := mpaApi.GetContainerControlledMode(container.Name, podPolicy)
+		if containerControlledMode != mpaTypes.ContainerControlledRequestsAndLimits {
+			// 不修改 limit 时 request 不能超过容器现有的 limit(没有则为 LimitRange 的默认 limit), 否则 pod 会被拒绝
+			var capped []string
+			resources[i].Requests, capped = capRequestsToLimits(resources[i].Requests, container.Resources.Limits, defaultLimit)
+			if len(capped) > 0 {
+				annotations[container.Name] = capped
 			}
 		} else {
-			resources[i].Limits = resources[i].Requests
-			annotations[container.Name] = []string{"EmptydefaultLimits, set to the same with Requests"}
+			// 需要同时伸缩 request 和 limit
+			recommLimit, anotation := containerUtil.GetProportionalLimit(
+				container.Resources.Limits, container.Resources.Requests,
+				resources[i].Requests, defaultLimit)
+
+			if recommLimit != nil && len(recommLimit) != 0 {
+				// 设置伸缩后的limit
+				resources[i].Limits = recommLimit
+				if len(anotation) > 0 {
+					annotations[container.Name] = anotation
+				}
+			} else {
+				resources[i].Limits = resources[i].Requests
+				annotations[container.Name] = []string{"EmptydefaultLimits, set to the same with Requests"}
+			}
 		}
-		//}
 		klog.V(4).Infof("container(%s) of pod(%s/%s)'s recommendation: request-%v limits-%v", container.Name, pod.Namespace, pod.Name, resources[i].Requests, resources[i].Limits)
 	}
 	return resources, annotations
 }
+
+// capRequestsToLimits 将 requests 中超过 limit 的资源截断为 limit
+// 容器未设置某资源的 limit 时使用 defaultLimit 中的值, 两者都没有则不截断
+func capRequestsToLimits(requests, limits, defaultLimit corev1.ResourceList) (corev1.ResourceList, []string) {
+	var annotations []string
+	result := corev1.ResourceList{}
+	for resourceName, request := range requests {
+		limit, found := limits[resourceName]
+		if !found {
+			limit, found = defaultLimit[resourceName]
+		}
+		if found && request.Cmp(limit) > 0 {
+			annotations = append(annotations, fmt.Sprintf("%v: request capped to limit %v since limits are not controlled", resourceName, limit.String()))
+			result[resourceName] = limit.DeepCopy()
+			continue
+		}
+		result[resourceName] = request
+	}
+	sort.Strings(annotations)
+	return result, annotations
+}
+
+// filterControlledResources 返回 resources 中被控制(属于 controlledResources)的资源
+func filterControlledResources(resources corev1.ResourceList, controlledResources []corev1.ResourceName) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, resourceName := range controlledResources {
+		if quantity, existed := resources[resourceName]; existed {
+			result[resourceName] = quantity
+		}
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+package util
+
+import (
+	"testing"
+
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func resourceList(cpu, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+func TestFilterControlledResources(t *testing.T) {
+	tests := []struct {
+		name                string
+		resources           corev1.ResourceList
+		controlledResources []corev1.ResourceName
+		expected            corev1.ResourceList
+	}{
+		{
+			name:                "cpu and memory controlled",
+			resources:           resourceList("500m", "256Mi"),
+			controlledResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+			expected:            resourceList("500m", "256Mi"),
+		},
+		{
+			name:                "only cpu controlled",
+			resources:           resourceList("500m", "256Mi"),
+			controlledResources: []corev1.ResourceName{corev1.ResourceCPU},
+			expected:            resourceList("500m", ""),
+		},
+		{
+			name:                "controlled resource not recommended",
+			resources:           resourceList("500m", ""),
+			controlledResources: []corev1.ResourceName{corev1.ResourceMemory},
+			expected:            corev1.ResourceList{},
+		},
+		{
+			name:                "nothing controlled",
+			resources:           resourceList("500m", "256Mi"),
+			controlledResources: []corev1.ResourceName{},
+			expected:            corev1.ResourceList{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := filterControlledResources(tc.resources, tc.controlledResources)
+			if !equality.Semantic.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetContainersResources(t *testing.T) {
+	requestsOnly := mpaTypes.ContainerControlledRequestsOnly
+	off := mpaTypes.ContainerScalingModeOff
+	cpuOnly := []corev1.ResourceName{corev1.ResourceCPU}
+
+	newPod := func(limits corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: resourceList("250m", "128Mi"),
+							Limits:   limits,
+						},
+					},
+				},
+			},
+		}
+	}
+	recommendation := &mpaTypes.RecommendedResources{
+		TargetPodNum: 2,
+		ContainerRecommendations: []mpaTypes.RecommendedContainerResources{
+			{ContainerName: "app", Target: resourceList("1", "512Mi")},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		policy         *mpaTypes.ContainerResourcePolicy
+		limits         corev1.ResourceList
+		limitRange     *corev1.LimitRangeItem
+		expectedReqs   corev1.ResourceList
+		expectedLimits corev1.ResourceList
+	}{
+		{
+			name:           "requests and limits scaled by default",
+			policy:         nil,
+			limits:         resourceList("500m", "256Mi"),
+			expectedReqs:   resourceList("1", "512Mi"),
+			expectedLimits: resourceList("2", "1Gi"),
+		},
+		{
+			name:           "requests capped to container limits under RequestsOnly",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledMode: &requestsOnly},
+			limits:         resourceList("500m", "256Mi"),
+			expectedReqs:   resourceList("500m", "256Mi"),
+			expectedLimits: nil,
+		},
+		{
+			name:           "requests below container limits kept under RequestsOnly",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledMode: &requestsOnly},
+			limits:         resourceList("2", "1Gi"),
+			expectedReqs:   resourceList("1", "512Mi"),
+			expectedLimits: nil,
+		},
+		{
+			name:           "requests capped to LimitRange default limits under RequestsOnly",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledMode: &requestsOnly},
+			limitRange:     &corev1.LimitRangeItem{Default: resourceList("800m", "1Gi")},
+			expectedReqs:   resourceList("800m", "512Mi"),
+			expectedLimits: nil,
+		},
+		{
+			name:           "requests not capped without any limit under RequestsOnly",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledMode: &requestsOnly},
+			expectedReqs:   resourceList("1", "512Mi"),
+			expectedLimits: nil,
+		},
+		{
+			name:           "memory not patched when only cpu is controlled",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: "app", ControlledResources: &cpuOnly},
+			limits:         resourceList("500m", "256Mi"),
+			expectedReqs:   resourceList("1", ""),
+			expectedLimits: resourceList("2", ""),
+		},
+		{
+			name:           "container with scaling mode Off gets no resources",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: "app", Mode: &off},
+			expectedReqs:   nil,
+			expectedLimits: nil,
+		},
+		{
+			name:           "wildcard policy applies to the container",
+			policy:         &mpaTypes.ContainerResourcePolicy{ContainerName: mpaTypes.DefaultContainerResourcePolicy, Mode: &off},
+			expectedReqs:   nil,
+			expectedLimits: nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var podPolicy *mpaTypes.PodResourcePolicy
+			if tc.policy != nil {
+				podPolicy = &mpaTypes.PodResourcePolicy{ContainerPolicies: []mpaTypes.ContainerResourcePolicy{*tc.policy}}
+			}
+			resources, _ := getContainersResources(newPod(tc.limits), podPolicy, recommendation, tc.limitRange)
+			if len(resources) != 1 {
+				t.Fatalf("expected resources of 1 container, got %d", len(resources))
+			}
+			if !equality.Semantic.DeepEqual(resources[0].Requests, tc.expectedReqs) {
+				t.Errorf("expected requests %v, got %v", tc.expectedReqs, resources[0].Requests)
+			}
+			if !equality.Semantic.DeepEqual(resources[0].Limits, tc.expectedLimits) {
+				t.Errorf("expected limits %v, got %v", tc.expectedLimits, resources[0].Limits)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package util
 
 import (
-	"k8s.io/api/admission/v1beta1"
+	admissionV1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"multidim-pod-autoscaler/pkg/util/patch"
 )
@@ -13,5 +13,5 @@ type Handler interface {
 	// AdmissionResource 获取 Handler 可处理的资源类型
 	AdmissionResource() AdmissionResource
 	// GetPatches 获取admissionRequest对应的资源patch(需要进行的操作)
-	GetPatches(request *v1beta1.AdmissionRequest) ([]patch.Patch, error)
+	GetPatches(request *admissionV1.AdmissionRequest) ([]patch.Patch, error)
 }
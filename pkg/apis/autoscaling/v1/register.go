@@ -39,6 +39,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&MultidimPodAutoscaler{},
 		&MultidimPodAutoscalerList{},
+		&MultidimPodAutoscalerClusterPolicy{},
+		&MultidimPodAutoscalerClusterPolicyList{},
+		&MultidimPodAutoscalerPolicy{},
+		&MultidimPodAutoscalerPolicyList{},
+		&MpaBudget{},
+		&MpaBudgetList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
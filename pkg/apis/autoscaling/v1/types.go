@@ -3,7 +3,9 @@ package v1
 import (
 	autoscaling "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -51,6 +53,44 @@ type MultidimPodAutoscalerSpec struct {
 	// 未指定时，将默认算法应用到全部容器(计算伸缩方案)
 	// +optional
 	ResourcePolicy *PodResourcePolicy `json:"resourcePolicy,omitempty" protobuf:"bytes,3,opt,name=resourcePolicy"`
+
+	// 多个伸缩器匹配到相同的POD时, 优先级高的伸缩器生效
+	// 优先级相同时, 创建时间早的伸缩器生效
+	// 默认为 0
+	// +optional
+	Priority *int32 `json:"priority,omitempty" protobuf:"varint,4,opt,name=priority"`
+
+	// 计算推荐方案使用的算法及其参数
+	// 未指定时使用 recommender 的默认算法
+	// +optional
+	Algorithm *RecommendationAlgorithmSpec `json:"algorithm,omitempty" protobuf:"bytes,5,opt,name=algorithm"`
+
+	// 推荐方案的副本数范围
+	// 未指定时不限制(仍受推荐算法搜索空间的限制)
+	// +optional
+	Replicas *ReplicaBounds `json:"replicas,omitempty" protobuf:"bytes,6,opt,name=replicas"`
+}
+
+// ReplicaBounds 推荐方案的副本数范围
+type ReplicaBounds struct {
+	// 最少副本数
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty" protobuf:"varint,1,opt,name=minReplicas"`
+	// 最多副本数
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" protobuf:"varint,2,opt,name=maxReplicas"`
+}
+
+// RecommendationAlgorithmSpec 描述计算推荐方案使用的算法
+type RecommendationAlgorithmSpec struct {
+	// 算法名(mmc、target-utilization、percentile-histogram)
+	// 为空时使用 recommender 的默认算法
+	// +optional
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+	// 算法参数(JSON 对象), 格式由算法决定
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Params *runtime.RawExtension `json:"params,omitempty" protobuf:"bytes,2,opt,name=params"`
 }
 
 // PodUpdatePolicy 描述如何改变POD(资源等)的策略
@@ -147,6 +187,88 @@ type MultidimPodAutoscalerStatus struct {
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []MultidimPodAutoscalerCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,3,rep,name=conditions"`
+
+	// 计算最新资源配置方案使用的算法及其版本
+	// +optional
+	Algorithm *RecommendationAlgorithmStatus `json:"algorithm,omitempty" protobuf:"bytes,4,opt,name=algorithm"`
+
+	// 最近一次计算推荐方案的决策记录(为什么选择或保持该方案)
+	// +optional
+	Decision *RecommendationDecision `json:"decision,omitempty" protobuf:"bytes,5,opt,name=decision"`
+
+	// 合并默认策略(MultidimPodAutoscalerClusterPolicy、MultidimPodAutoscalerPolicy)后生效的配置
+	// +optional
+	EffectivePolicy *EffectivePolicy `json:"effectivePolicy,omitempty" protobuf:"bytes,6,opt,name=effectivePolicy"`
+}
+
+// EffectivePolicy MPA 合并默认策略后生效的配置
+type EffectivePolicy struct {
+	// 选中该 MPA 的策略, 按合并顺序排列(如: ClusterPolicy/default、Policy/team-a)
+	// 后合并的策略覆盖先合并的策略, MPA 自身的配置覆盖所有策略
+	// +optional
+	AppliedPolicies []string `json:"appliedPolicies,omitempty" protobuf:"bytes,1,rep,name=appliedPolicies"`
+	// 合并后生效的配置
+	DefaultPolicy `json:",inline" protobuf:"bytes,2,opt,name=defaultPolicy"`
+}
+
+const (
+	// RecommendationDecisionApply 应用新的推荐方案
+	RecommendationDecisionApply = "Apply"
+	// RecommendationDecisionKeep 保持当前的推荐方案
+	RecommendationDecisionKeep = "Keep"
+)
+
+// RecommendationDecision 推荐算法的决策记录
+type RecommendationDecision struct {
+	// 计算推荐方案的时间
+	Time metav1.Time `json:"time" protobuf:"bytes,1,name=time"`
+	// 观测到的服务 qps
+	ObservedQps string `json:"observedQps" protobuf:"bytes,2,name=observedQps"`
+	// 算法选择的方案
+	// +optional
+	Chosen *PlanEvaluation `json:"chosen,omitempty" protobuf:"bytes,3,opt,name=chosen"`
+	// 当前的推荐方案在观测 qps 下的评估结果
+	// +optional
+	Current *PlanEvaluation `json:"current,omitempty" protobuf:"bytes,4,opt,name=current"`
+	// 其他较优的方案(按优劣排序)
+	// +optional
+	Alternatives []PlanEvaluation `json:"alternatives,omitempty" protobuf:"bytes,5,rep,name=alternatives"`
+	// 决策结果 (Apply, Keep)
+	Action string `json:"action" protobuf:"bytes,6,name=action"`
+	// 应用或保持方案的原因(如: 新旧方案得分与更新阈值的比较)
+	Reason string `json:"reason" protobuf:"bytes,7,name=reason"`
+}
+
+// PlanEvaluation 资源方案(副本数 × 每个副本的 cpu)在观测 qps 下的评估结果
+// 数值以字符串表示, 算法不适用的项为空
+type PlanEvaluation struct {
+	// 副本数
+	PodNum int `json:"podNum" protobuf:"int32,1,name=podNum"`
+	// 每个副本的 cpu
+	Cpu resource.Quantity `json:"cpu" protobuf:"bytes,2,name=cpu"`
+	// 方案的得分(越高越好)
+	// +optional
+	Score string `json:"score,omitempty" protobuf:"bytes,3,opt,name=score"`
+	// 归一化的资源成本项(资源越少越高)
+	// +optional
+	ResourceCost string `json:"resourceCost,omitempty" protobuf:"bytes,4,opt,name=resourceCost"`
+	// 违约成本项(服务可用性越高越高)
+	// +optional
+	PenaltyCost string `json:"penaltyCost,omitempty" protobuf:"bytes,5,opt,name=penaltyCost"`
+	// M/M/c 模型评估的服务可用性
+	// +optional
+	ServiceScore string `json:"serviceScore,omitempty" protobuf:"bytes,6,opt,name=serviceScore"`
+	// 服务强度(利用率) ρ = qps / 方案的处理能力
+	// +optional
+	Utilization string `json:"utilization,omitempty" protobuf:"bytes,7,opt,name=utilization"`
+}
+
+// RecommendationAlgorithmStatus 描述计算推荐方案使用的算法
+type RecommendationAlgorithmStatus struct {
+	// 算法名
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// 算法版本
+	Version string `json:"version" protobuf:"bytes,2,name=version"`
 }
 
 // RecommendedResources 伸缩器计算得出的伸缩方案
@@ -191,6 +313,21 @@ var (
 	RecommendationSkipped MultidimPodAutoscalerConditionType = "RecommendationSkipped"
 	// NoPodsMatched 表示 label selector未匹配到POD
 	NoPodsMatched MultidimPodAutoscalerConditionType = "NoPodsMatched"
+	// PodOwnerMismatch 表示存在 label selector 匹配到的POD, 但其属主链中没有 targetRef
+	// 这些POD不会被该伸缩器管理
+	PodOwnerMismatch MultidimPodAutoscalerConditionType = "PodOwnerMismatch"
+	// OverlappingMpa 表示存在其他伸缩器匹配到了相同的POD
+	// 只有优先级最高的伸缩器生效(Message 中给出)
+	OverlappingMpa MultidimPodAutoscalerConditionType = "OverlappingMpa"
+	// QuotaLimited 表示最优方案超出了命名空间 ResourceQuota 的剩余资源, 推荐方案被限制
+	// 未被限制的方案记录在 UncappedTargetPodNum / UncappedTarget 中
+	QuotaLimited MultidimPodAutoscalerConditionType = "QuotaLimited"
+	// CapacityLimited 表示最优方案的副本无法装入集群中可调度节点的剩余资源, 推荐方案被限制
+	// Message 中给出节点剩余资源的概况
+	CapacityLimited MultidimPodAutoscalerConditionType = "CapacityLimited"
+	// BudgetLimited 表示选中该伸缩器的 MpaBudget 生效(多个伸缩器的最优方案之和超出预算), 推荐方案被限制为联合分配的份额
+	// Message 中给出预算的名字及分配的方案
+	BudgetLimited MultidimPodAutoscalerConditionType = "BudgetLimited"
 )
 
 // MultidimPodAutoscalerCondition 伸缩器在某时刻的状态
@@ -209,3 +346,190 @@ type MultidimPodAutoscalerCondition struct {
 	// +optional
 	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,shortName=mpacp
+
+// MultidimPodAutoscalerClusterPolicy 集群范围的 MPA 默认策略
+// 按命名空间或 MPA 的 label 选择 MPA, 作为 MPA spec 的默认值
+type MultidimPodAutoscalerClusterPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// 策略的配置
+	Spec MultidimPodAutoscalerClusterPolicySpec `json:"spec" protobuf:"bytes,2,name=spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MultidimPodAutoscalerClusterPolicyList is a list of MultidimPodAutoscalerClusterPolicy objects.
+type MultidimPodAutoscalerClusterPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []MultidimPodAutoscalerClusterPolicy `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// MultidimPodAutoscalerClusterPolicySpec 集群策略的配置
+type MultidimPodAutoscalerClusterPolicySpec struct {
+	// 按命名空间的 label 选择 MPA
+	// 未指定时选择所有命名空间
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" protobuf:"bytes,1,opt,name=namespaceSelector"`
+
+	MultidimPodAutoscalerPolicySpec `json:",inline" protobuf:"bytes,2,opt,name=policySpec"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=mpap
+
+// MultidimPodAutoscalerPolicy 命名空间内的 MPA 默认策略
+// 只选择同一命名空间内的 MPA, 在集群策略之后合并(覆盖集群策略)
+type MultidimPodAutoscalerPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// 策略的配置
+	Spec MultidimPodAutoscalerPolicySpec `json:"spec" protobuf:"bytes,2,name=spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MultidimPodAutoscalerPolicyList is a list of MultidimPodAutoscalerPolicy objects.
+type MultidimPodAutoscalerPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []MultidimPodAutoscalerPolicy `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// MultidimPodAutoscalerPolicySpec 默认策略的配置
+type MultidimPodAutoscalerPolicySpec struct {
+	// 按 MPA 的 label 选择 MPA
+	// 未指定时选择所有 MPA
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" protobuf:"bytes,1,opt,name=selector"`
+
+	// 多个同一范围(集群或命名空间)的策略选中同一 MPA 时, 按优先级从低到高合并
+	// 优先级相同时按名字排序, 默认为 0
+	// +optional
+	Priority int32 `json:"priority,omitempty" protobuf:"varint,2,opt,name=priority"`
+
+	// MPA 未指定时使用的默认配置
+	Defaults DefaultPolicy `json:"defaults" protobuf:"bytes,3,name=defaults"`
+}
+
+// DefaultPolicy MPA spec 中可以由策略提供默认值的配置
+// 合并时 MPA 的配置覆盖策略的配置; 容器策略按容器名合并, 同名容器策略中未指定的字段使用策略的值
+type DefaultPolicy struct {
+	// 默认的更新策略
+	// +optional
+	UpdatePolicy *PodUpdatePolicy `json:"updatePolicy,omitempty" protobuf:"bytes,1,opt,name=updatePolicy"`
+	// 默认的容器策略(资源上下限、期望响应时间等)
+	// +optional
+	ResourcePolicy *PodResourcePolicy `json:"resourcePolicy,omitempty" protobuf:"bytes,2,opt,name=resourcePolicy"`
+	// 默认的推荐算法及其参数(成本模型)
+	// +optional
+	Algorithm *RecommendationAlgorithmSpec `json:"algorithm,omitempty" protobuf:"bytes,3,opt,name=algorithm"`
+	// 默认的副本数范围
+	// +optional
+	Replicas *ReplicaBounds `json:"replicas,omitempty" protobuf:"bytes,4,opt,name=replicas"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=mpab
+
+// MpaBudget 命名空间内多个 MPA 的资源预算
+// 限制选中的 MPA 推荐方案的 cpu、memory 及副本数之和; 预算生效时 recommender 为这些 MPA 联合分配方案
+type MpaBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// 预算的配置
+	Spec MpaBudgetSpec `json:"spec" protobuf:"bytes,2,name=spec"`
+
+	// 最近一次分配的结果
+	// +optional
+	Status MpaBudgetStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MpaBudgetList is a list of MpaBudget objects.
+type MpaBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []MpaBudget `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// MpaBudgetSpec 预算的配置
+// 未指定的资源不限制
+type MpaBudgetSpec struct {
+	// 按 MPA 的 label 选择同一命名空间内的 MPA
+	// 未指定时选择命名空间内的所有 MPA
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" protobuf:"bytes,1,opt,name=selector"`
+	// 所有副本的 cpu request 之和的上限
+	// +optional
+	MaxCpu *resource.Quantity `json:"maxCpu,omitempty" protobuf:"bytes,2,opt,name=maxCpu"`
+	// 所有副本的 memory request 之和的上限
+	// +optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty" protobuf:"bytes,3,opt,name=maxMemory"`
+	// 副本数之和的上限
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" protobuf:"varint,4,opt,name=maxReplicas"`
+}
+
+// MpaBudgetStatus 预算最近一次分配的结果
+type MpaBudgetStatus struct {
+	// 预算是否生效(各 MPA 独立计算的最优方案之和超出预算)
+	// +optional
+	Binding bool `json:"binding,omitempty" protobuf:"varint,1,opt,name=binding"`
+	// 各 MPA 分配到的方案
+	// +optional
+	Allocations []MpaBudgetAllocation `json:"allocations,omitempty" protobuf:"bytes,2,rep,name=allocations"`
+	// 分配的资源之和
+	// +optional
+	Allocated MpaBudgetUsage `json:"allocated,omitempty" protobuf:"bytes,3,opt,name=allocated"`
+	// 分配结果的说明(如: 最小的方案之和仍超出预算)
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,4,opt,name=message"`
+	// 最近一次分配的时间
+	// +optional
+	LastAllocationTime *metav1.Time `json:"lastAllocationTime,omitempty" protobuf:"bytes,5,opt,name=lastAllocationTime"`
+}
+
+// MpaBudgetAllocation MPA 分配到的方案
+type MpaBudgetAllocation struct {
+	// MPA 的名字
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// 副本数
+	PodNum int `json:"podNum" protobuf:"int32,2,name=podNum"`
+	// 每个副本(每个容器)的 cpu
+	// +optional
+	Cpu *resource.Quantity `json:"cpu,omitempty" protobuf:"bytes,3,opt,name=cpu"`
+	// 方案在观测 qps 下的得分, 不参与联合分配(算法不支持评估方案)的 MPA 为空
+	// +optional
+	Score string `json:"score,omitempty" protobuf:"bytes,4,opt,name=score"`
+}
+
+// MpaBudgetUsage 资源用量
+type MpaBudgetUsage struct {
+	// cpu request 之和
+	// +optional
+	Cpu *resource.Quantity `json:"cpu,omitempty" protobuf:"bytes,1,opt,name=cpu"`
+	// memory request 之和
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty" protobuf:"bytes,2,opt,name=memory"`
+	// 副本数之和
+	// +optional
+	Replicas int32 `json:"replicas,omitempty" protobuf:"varint,3,opt,name=replicas"`
+}
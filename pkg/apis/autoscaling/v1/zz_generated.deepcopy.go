@@ -23,6 +23,7 @@ package v1
 import (
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -75,6 +76,236 @@ func (in *ContainerResourcePolicy) DeepCopy() *ContainerResourcePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultPolicy) DeepCopyInto(out *DefaultPolicy) {
+	*out = *in
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(PodUpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourcePolicy != nil {
+		in, out := &in.ResourcePolicy, &out.ResourcePolicy
+		*out = new(PodResourcePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Algorithm != nil {
+		in, out := &in.Algorithm, &out.Algorithm
+		*out = new(RecommendationAlgorithmSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(ReplicaBounds)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultPolicy.
+func (in *DefaultPolicy) DeepCopy() *DefaultPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectivePolicy) DeepCopyInto(out *EffectivePolicy) {
+	*out = *in
+	if in.AppliedPolicies != nil {
+		in, out := &in.AppliedPolicies, &out.AppliedPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.DefaultPolicy.DeepCopyInto(&out.DefaultPolicy)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectivePolicy.
+func (in *EffectivePolicy) DeepCopy() *EffectivePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectivePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MpaBudget) DeepCopyInto(out *MpaBudget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MpaBudget.
+func (in *MpaBudget) DeepCopy() *MpaBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(MpaBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MpaBudget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MpaBudgetAllocation) DeepCopyInto(out *MpaBudgetAllocation) {
+	*out = *in
+	if in.Cpu != nil {
+		in, out := &in.Cpu, &out.Cpu
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MpaBudgetAllocation.
+func (in *MpaBudgetAllocation) DeepCopy() *MpaBudgetAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(MpaBudgetAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MpaBudgetList) DeepCopyInto(out *MpaBudgetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MpaBudget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MpaBudgetList.
+func (in *MpaBudgetList) DeepCopy() *MpaBudgetList {
+	if in == nil {
+		return nil
+	}
+	out := new(MpaBudgetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MpaBudgetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MpaBudgetSpec) DeepCopyInto(out *MpaBudgetSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxCpu != nil {
+		in, out := &in.MaxCpu, &out.MaxCpu
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxMemory != nil {
+		in, out := &in.MaxMemory, &out.MaxMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MpaBudgetSpec.
+func (in *MpaBudgetSpec) DeepCopy() *MpaBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MpaBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MpaBudgetStatus) DeepCopyInto(out *MpaBudgetStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]MpaBudgetAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Allocated.DeepCopyInto(&out.Allocated)
+	if in.LastAllocationTime != nil {
+		in, out := &in.LastAllocationTime, &out.LastAllocationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MpaBudgetStatus.
+func (in *MpaBudgetStatus) DeepCopy() *MpaBudgetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MpaBudgetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MpaBudgetUsage) DeepCopyInto(out *MpaBudgetUsage) {
+	*out = *in
+	if in.Cpu != nil {
+		in, out := &in.Cpu, &out.Cpu
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MpaBudgetUsage.
+func (in *MpaBudgetUsage) DeepCopy() *MpaBudgetUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(MpaBudgetUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultidimPodAutoscaler) DeepCopyInto(out *MultidimPodAutoscaler) {
 	*out = *in
@@ -103,6 +334,88 @@ func (in *MultidimPodAutoscaler) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultidimPodAutoscalerClusterPolicy) DeepCopyInto(out *MultidimPodAutoscalerClusterPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultidimPodAutoscalerClusterPolicy.
+func (in *MultidimPodAutoscalerClusterPolicy) DeepCopy() *MultidimPodAutoscalerClusterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MultidimPodAutoscalerClusterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultidimPodAutoscalerClusterPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultidimPodAutoscalerClusterPolicyList) DeepCopyInto(out *MultidimPodAutoscalerClusterPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MultidimPodAutoscalerClusterPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultidimPodAutoscalerClusterPolicyList.
+func (in *MultidimPodAutoscalerClusterPolicyList) DeepCopy() *MultidimPodAutoscalerClusterPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(MultidimPodAutoscalerClusterPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultidimPodAutoscalerClusterPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultidimPodAutoscalerClusterPolicySpec) DeepCopyInto(out *MultidimPodAutoscalerClusterPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.MultidimPodAutoscalerPolicySpec.DeepCopyInto(&out.MultidimPodAutoscalerPolicySpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultidimPodAutoscalerClusterPolicySpec.
+func (in *MultidimPodAutoscalerClusterPolicySpec) DeepCopy() *MultidimPodAutoscalerClusterPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MultidimPodAutoscalerClusterPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultidimPodAutoscalerCondition) DeepCopyInto(out *MultidimPodAutoscalerCondition) {
 	*out = *in
@@ -153,6 +466,88 @@ func (in *MultidimPodAutoscalerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultidimPodAutoscalerPolicy) DeepCopyInto(out *MultidimPodAutoscalerPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultidimPodAutoscalerPolicy.
+func (in *MultidimPodAutoscalerPolicy) DeepCopy() *MultidimPodAutoscalerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MultidimPodAutoscalerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultidimPodAutoscalerPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultidimPodAutoscalerPolicyList) DeepCopyInto(out *MultidimPodAutoscalerPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MultidimPodAutoscalerPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultidimPodAutoscalerPolicyList.
+func (in *MultidimPodAutoscalerPolicyList) DeepCopy() *MultidimPodAutoscalerPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(MultidimPodAutoscalerPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultidimPodAutoscalerPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultidimPodAutoscalerPolicySpec) DeepCopyInto(out *MultidimPodAutoscalerPolicySpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Defaults.DeepCopyInto(&out.Defaults)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultidimPodAutoscalerPolicySpec.
+func (in *MultidimPodAutoscalerPolicySpec) DeepCopy() *MultidimPodAutoscalerPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MultidimPodAutoscalerPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultidimPodAutoscalerSpec) DeepCopyInto(out *MultidimPodAutoscalerSpec) {
 	*out = *in
@@ -171,6 +566,21 @@ func (in *MultidimPodAutoscalerSpec) DeepCopyInto(out *MultidimPodAutoscalerSpec
 		*out = new(PodResourcePolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Algorithm != nil {
+		in, out := &in.Algorithm, &out.Algorithm
+		*out = new(RecommendationAlgorithmSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(ReplicaBounds)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -199,6 +609,21 @@ func (in *MultidimPodAutoscalerStatus) DeepCopyInto(out *MultidimPodAutoscalerSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Algorithm != nil {
+		in, out := &in.Algorithm, &out.Algorithm
+		*out = new(RecommendationAlgorithmStatus)
+		**out = **in
+	}
+	if in.Decision != nil {
+		in, out := &in.Decision, &out.Decision
+		*out = new(RecommendationDecision)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EffectivePolicy != nil {
+		in, out := &in.EffectivePolicy, &out.EffectivePolicy
+		*out = new(EffectivePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -212,6 +637,23 @@ func (in *MultidimPodAutoscalerStatus) DeepCopy() *MultidimPodAutoscalerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanEvaluation) DeepCopyInto(out *PlanEvaluation) {
+	*out = *in
+	out.Cpu = in.Cpu.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanEvaluation.
+func (in *PlanEvaluation) DeepCopy() *PlanEvaluation {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanEvaluation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodResourcePolicy) DeepCopyInto(out *PodResourcePolicy) {
 	*out = *in
@@ -256,6 +698,77 @@ func (in *PodUpdatePolicy) DeepCopy() *PodUpdatePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendationAlgorithmSpec) DeepCopyInto(out *RecommendationAlgorithmSpec) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationAlgorithmSpec.
+func (in *RecommendationAlgorithmSpec) DeepCopy() *RecommendationAlgorithmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendationAlgorithmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendationAlgorithmStatus) DeepCopyInto(out *RecommendationAlgorithmStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationAlgorithmStatus.
+func (in *RecommendationAlgorithmStatus) DeepCopy() *RecommendationAlgorithmStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendationAlgorithmStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendationDecision) DeepCopyInto(out *RecommendationDecision) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Chosen != nil {
+		in, out := &in.Chosen, &out.Chosen
+		*out = new(PlanEvaluation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Current != nil {
+		in, out := &in.Current, &out.Current
+		*out = new(PlanEvaluation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Alternatives != nil {
+		in, out := &in.Alternatives, &out.Alternatives
+		*out = make([]PlanEvaluation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationDecision.
+func (in *RecommendationDecision) DeepCopy() *RecommendationDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendationDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RecommendedContainerResources) DeepCopyInto(out *RecommendedContainerResources) {
 	*out = *in
@@ -322,3 +835,29 @@ func (in *RecommendedResources) DeepCopy() *RecommendedResources {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaBounds) DeepCopyInto(out *ReplicaBounds) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaBounds.
+func (in *ReplicaBounds) DeepCopy() *ReplicaBounds {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaBounds)
+	in.DeepCopyInto(out)
+	return out
+}
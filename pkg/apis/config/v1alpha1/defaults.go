@@ -0,0 +1,147 @@
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 默认值与各组件命令行参数的默认值一致
+const (
+	defaultKubeApiQps   float32 = 5.0
+	defaultKubeApiBurst int32   = 10
+
+	defaultRecommenderAddress = ":8946"
+	defaultUpdaterAddress     = ":8945"
+	defaultAdmissionAddress   = ":8944"
+
+	// defaultRecommendationAlgorithm 与 recommendation.MMCAlgorithm 相同
+	defaultRecommendationAlgorithm = "mmc"
+	defaultRecommenderInterval     = time.Minute
+	defaultRecommenderWorkers      = 4
+	defaultPluginTimeout           = 5 * time.Second
+
+	defaultUpdaterInterval  = time.Minute
+	defaultMinReplicas      = 1
+	defaultEvictionFraction = 1.0
+
+	defaultWebhookPort         = 8000
+	defaultClientCaFile        = "/etc/mpa-tls-certs/caCert.pem"
+	defaultTlsCertFile         = "/etc/mpa-tls-certs/serverCert.pem"
+	defaultTlsPrivateKey       = "/etc/mpa-tls-certs/serverKey.pem"
+	defaultCertsSecretName     = "mpa-webhook-certs"
+	defaultCertsValidity       = 365 * 24 * time.Hour
+	defaultCertsRotateBefore   = 30 * 24 * time.Hour
+	defaultCertsCheckInterval  = time.Hour
+	defaultWebhookService      = "mpa-webhook"
+	defaultWebhookTimeout      = 30
+	defaultFailurePolicy       = "Ignore"
+	defaultNamespaceSelector   = DefaultWebhookNamespaceSelector
+	defaultObjectSelector      = DefaultWebhookObjectSelector
+	defaultReinvocationPolicy  = "Never"
+	defaultMatchPolicy         = "Equivalent"
+	defaultWebhookResyncPeriod = time.Minute
+)
+
+// webhook 默认的 namespaceSelector、objectSelector(排除 kube-system 及 mpa.k8s.io/skip label 为 true 的命名空间、pod)
+// updater、recommender 在 webhook 配置不存在时使用, 与 admission 不修改的 pods 保持一致
+const (
+	DefaultWebhookNamespaceSelector = "kubernetes.io/metadata.name notin (kube-system),mpa.k8s.io/skip notin (true)"
+	DefaultWebhookObjectSelector    = "mpa.k8s.io/skip notin (true)"
+)
+
+// SetDefaults_RecommenderConfiguration 为未指定的字段设置默认值
+// 成本模型未指定的字段在转换为 recommendation.Model 时使用内置的默认值
+func SetDefaults_RecommenderConfiguration(c *RecommenderConfiguration) {
+	c.APIVersion = SchemeGroupVersion.String()
+	c.Kind = RecommenderConfigurationKind
+	setDefaultsClientConnection(&c.ClientConnection)
+	setDefaultsDiagnostics(&c.Diagnostics, defaultRecommenderAddress)
+	setDefaultDuration(&c.RecommenderInterval, defaultRecommenderInterval)
+	if c.DefaultAlgorithm == "" {
+		c.DefaultAlgorithm = defaultRecommendationAlgorithm
+	}
+	if c.Workers == 0 {
+		c.Workers = defaultRecommenderWorkers
+	}
+	setDefaultDuration(&c.Plugin.Timeout, defaultPluginTimeout)
+}
+
+// SetDefaults_UpdaterConfiguration 为未指定的字段设置默认值
+func SetDefaults_UpdaterConfiguration(c *UpdaterConfiguration) {
+	c.APIVersion = SchemeGroupVersion.String()
+	c.Kind = UpdaterConfigurationKind
+	setDefaultsClientConnection(&c.ClientConnection)
+	setDefaultsDiagnostics(&c.Diagnostics, defaultUpdaterAddress)
+	setDefaultDuration(&c.UpdaterInterval, defaultUpdaterInterval)
+	if c.MinReplicas == 0 {
+		c.MinReplicas = defaultMinReplicas
+	}
+	if c.EvictionFraction == nil {
+		fraction := defaultEvictionFraction
+		c.EvictionFraction = &fraction
+	}
+}
+
+// SetDefaults_AdmissionConfiguration 为未指定的字段设置默认值
+func SetDefaults_AdmissionConfiguration(c *AdmissionConfiguration) {
+	c.APIVersion = SchemeGroupVersion.String()
+	c.Kind = AdmissionConfigurationKind
+	setDefaultsClientConnection(&c.ClientConnection)
+	setDefaultsDiagnostics(&c.Diagnostics, defaultAdmissionAddress)
+	if c.Port == 0 {
+		c.Port = defaultWebhookPort
+	}
+
+	certs := &c.Certs
+	setDefaultString(&certs.ClientCaFile, defaultClientCaFile)
+	setDefaultString(&certs.TlsCertFile, defaultTlsCertFile)
+	setDefaultString(&certs.TlsPrivateKey, defaultTlsPrivateKey)
+	setDefaultString(&certs.SecretName, defaultCertsSecretName)
+	setDefaultDuration(&certs.Validity, defaultCertsValidity)
+	setDefaultDuration(&certs.RotateBefore, defaultCertsRotateBefore)
+	setDefaultDuration(&certs.CheckInterval, defaultCertsCheckInterval)
+
+	webhook := &c.Webhook
+	setDefaultString(&webhook.Service, defaultWebhookService)
+	if webhook.TimeoutSeconds == 0 {
+		webhook.TimeoutSeconds = defaultWebhookTimeout
+	}
+	setDefaultString(&webhook.FailurePolicy, defaultFailurePolicy)
+	if webhook.NamespaceSelector == nil {
+		selector := defaultNamespaceSelector
+		webhook.NamespaceSelector = &selector
+	}
+	if webhook.ObjectSelector == nil {
+		selector := defaultObjectSelector
+		webhook.ObjectSelector = &selector
+	}
+	setDefaultString(&webhook.ReinvocationPolicy, defaultReinvocationPolicy)
+	setDefaultString(&webhook.MatchPolicy, defaultMatchPolicy)
+	setDefaultDuration(&webhook.ResyncPeriod, defaultWebhookResyncPeriod)
+}
+
+func setDefaultsClientConnection(c *ClientConnectionConfiguration) {
+	if c.QPS == 0 {
+		c.QPS = defaultKubeApiQps
+	}
+	if c.Burst == 0 {
+		c.Burst = defaultKubeApiBurst
+	}
+}
+
+func setDefaultsDiagnostics(c *DiagnosticsConfiguration, address string) {
+	setDefaultString(&c.Address, address)
+}
+
+func setDefaultString(s *string, value string) {
+	if *s == "" {
+		*s = value
+	}
+}
+
+func setDefaultDuration(d *metav1.Duration, value time.Duration) {
+	if d.Duration == 0 {
+		d.Duration = value
+	}
+}
@@ -0,0 +1,4 @@
+// Package v1alpha1 为 recommender、updater、admission 的组件配置(componentconfig)
+// 配置文件为 YAML/JSON 格式, 通过 --config 指定(可以挂载自 ConfigMap), 文件变化时热加载
+// 命令行中显式指定的参数覆盖文件中的值
+package v1alpha1
@@ -0,0 +1,22 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	GroupName = "config.mpa.k8s.io"
+	Version   = "v1alpha1"
+)
+
+// SchemeGroupVersion 组件配置的 apiVersion
+var SchemeGroupVersion = schema.GroupVersion{
+	Group:   GroupName,
+	Version: Version,
+}
+
+const (
+	RecommenderConfigurationKind = "RecommenderConfiguration"
+	UpdaterConfigurationKind     = "UpdaterConfiguration"
+	AdmissionConfigurationKind   = "AdmissionConfiguration"
+)
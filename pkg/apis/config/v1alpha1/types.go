@@ -0,0 +1,161 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 各字段注释中标注了修改后的生效方式: "热加载" 的字段在配置文件变化后立即生效,
+// 其他字段需要重启组件后生效(热加载时只打印警告)
+
+// ClientConnectionConfiguration 访问 API-Server 的客户端配置
+type ClientConnectionConfiguration struct {
+	// QPS 访问 API-Server 的 QPS 限制, 默认 5
+	QPS float32 `json:"qps,omitempty"`
+	// Burst 访问 API-Server 的 QPS 峰值限制, 默认 10
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// DiagnosticsConfiguration 诊断 server(/metrics、/healthz、/readyz)的配置
+type DiagnosticsConfiguration struct {
+	// Address 诊断 server 对外暴露的地址, 默认值因组件而异
+	Address string `json:"address,omitempty"`
+	// EnableProfiling 是否在诊断 server 上暴露 /debug/pprof
+	EnableProfiling bool `json:"enableProfiling,omitempty"`
+}
+
+// RecommenderConfiguration recommender 的配置
+type RecommenderConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	ClientConnection ClientConnectionConfiguration `json:"clientConnection,omitempty"`
+	Diagnostics      DiagnosticsConfiguration      `json:"diagnostics,omitempty"`
+	// MpaObjectNamespace 搜索MPA Objects的命名空间, 默认所有命名空间
+	MpaObjectNamespace string `json:"mpaObjectNamespace,omitempty"`
+
+	// RecommenderInterval 每个MPA重新计算推荐方案的时间间隔, 默认 1m(热加载)
+	RecommenderInterval metav1.Duration `json:"recommenderInterval,omitempty"`
+	// DefaultAlgorithm MPA 未指定 spec.algorithm 时使用的推荐算法, 默认 mmc(热加载)
+	DefaultAlgorithm string `json:"defaultAlgorithm,omitempty"`
+	// Workers 并发处理MPA的worker数量, 默认 4
+	Workers int32 `json:"workers,omitempty"`
+	// Plugin 进程外推荐算法插件
+	Plugin RecommendationPluginConfiguration `json:"plugin,omitempty"`
+	// CostModel 推荐算法的成本模型参数, 未指定的字段使用内置的默认值(热加载)
+	CostModel CostModelConfiguration `json:"costModel,omitempty"`
+}
+
+// RecommendationPluginConfiguration 进程外推荐算法插件的配置
+type RecommendationPluginConfiguration struct {
+	// Address 插件的 gRPC 地址(如: localhost:9090), 指定后 MPA 可使用 grpc 算法
+	Address string `json:"address,omitempty"`
+	// Timeout 调用插件的超时时间, 超时后使用内置的 mmc 算法, 默认 5s
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// CostModelConfiguration 推荐算法(成本模型)的参数
+// 取值为 0 有意义的字段使用指针, 未指定(nil)时使用内置的默认值
+type CostModelConfiguration struct {
+	// PodCapacities 每个 cpu 档位的副本每秒可处理的请求数, 同时决定了推荐方案搜索空间中的 cpu
+	PodCapacities []PodCapacity `json:"podCapacities,omitempty"`
+	// MaxReplicas 推荐方案搜索空间中的最大副本数(1 ~ 16)
+	MaxReplicas int64 `json:"maxReplicas,omitempty"`
+	// CpuPrice cpu 单价(vCore/s)
+	CpuPrice float64 `json:"cpuPrice,omitempty"`
+	// ResourceCostRatio 资源成本在方案得分中的占比([0, 1]), 违约成本的占比为 1 - ResourceCostRatio
+	ResourceCostRatio *float64 `json:"resourceCostRatio,omitempty"`
+	// UpdateThreshold 新方案的得分超出当前方案得分的比例超过该阈值时才更新推荐方案
+	UpdateThreshold *float64 `json:"updateThreshold,omitempty"`
+	// DefaultResponseTime MPA 未指定 expRespTime 时请求的期望响应时间
+	DefaultResponseTime metav1.Duration `json:"defaultResponseTime,omitempty"`
+	// PreferenceWeight 约束的偏好程度对方案得分的影响权重
+	PreferenceWeight *float64 `json:"preferenceWeight,omitempty"`
+	// NearOptimalScoreRatio 得分不低于最优方案得分 (1 - ratio) 的方案视为近似最优, 用于计算推荐方案的上下界
+	NearOptimalScoreRatio *float64 `json:"nearOptimalScoreRatio,omitempty"`
+	// QpsMinUncertainty qps 估计值的最小相对误差, 用于计算推荐方案的上下界
+	QpsMinUncertainty *float64 `json:"qpsMinUncertainty,omitempty"`
+	// DefaultTargetUtilization target-utilization 算法默认的目标利用率((0, 1])
+	DefaultTargetUtilization float64 `json:"defaultTargetUtilization,omitempty"`
+}
+
+// PodCapacity 一个 cpu 档位的副本的处理能力
+type PodCapacity struct {
+	// CPU 副本的 cpu(如: 500m)
+	CPU resource.Quantity `json:"cpu"`
+	// QPS 副本每秒可处理的请求数
+	QPS int64 `json:"qps"`
+}
+
+// UpdaterConfiguration updater 的配置
+type UpdaterConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	ClientConnection ClientConnectionConfiguration `json:"clientConnection,omitempty"`
+	Diagnostics      DiagnosticsConfiguration      `json:"diagnostics,omitempty"`
+	// MpaObjectNamespace 搜索MPA Objects的命名空间, 默认所有命名空间
+	MpaObjectNamespace string `json:"mpaObjectNamespace,omitempty"`
+
+	// UpdaterInterval updater的主流程运行频率, 默认 1m(热加载)
+	UpdaterInterval metav1.Duration `json:"updaterInterval,omitempty"`
+	// MinReplicas 执行update的最少的副本数量, 默认 1(热加载)
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// EvictionFraction 可以驱逐的副本个数占预配置个数的比例([0, 1]), 默认 1(热加载)
+	EvictionFraction *float64 `json:"evictionFraction,omitempty"`
+}
+
+// AdmissionConfiguration admission controller 的配置
+type AdmissionConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	ClientConnection ClientConnectionConfiguration `json:"clientConnection,omitempty"`
+	Diagnostics      DiagnosticsConfiguration      `json:"diagnostics,omitempty"`
+	// MpaObjectNamespace 搜索MPA Objects的命名空间, 默认所有命名空间
+	MpaObjectNamespace string `json:"mpaObjectNamespace,omitempty"`
+
+	// Port webhook server 监听的端口号, 默认 8000
+	Port int32 `json:"port,omitempty"`
+	// Certs webhook server 的证书
+	Certs CertsConfiguration `json:"certs,omitempty"`
+	// Webhook 注册到 API-Server 的 webhook 配置
+	Webhook WebhookConfiguration `json:"webhook,omitempty"`
+}
+
+// CertsConfiguration webhook server 证书的配置
+type CertsConfiguration struct {
+	// ClientCaFile、TlsCertFile、TlsPrivateKey CA证书、server证书及秘钥的路径
+	ClientCaFile  string `json:"clientCaFile,omitempty"`
+	TlsCertFile   string `json:"tlsCertFile,omitempty"`
+	TlsPrivateKey string `json:"tlsPrivateKey,omitempty"`
+	// SelfManaged 自行生成CA及server证书并保存在 Secret 中, 到期前自动轮换(不再读取证书文件)
+	SelfManaged bool `json:"selfManaged,omitempty"`
+	// SecretName self-managed 模式下保存证书的 Secret 名字, 默认 mpa-webhook-certs
+	SecretName string `json:"secretName,omitempty"`
+	// Validity self-managed 模式下server证书的有效期, 默认 8760h
+	Validity metav1.Duration `json:"validity,omitempty"`
+	// RotateBefore self-managed 模式下证书到期前多久进行轮换, 默认 720h
+	RotateBefore metav1.Duration `json:"rotateBefore,omitempty"`
+	// CheckInterval self-managed 模式下检查证书是否需要轮换的时间间隔, 默认 1h
+	CheckInterval metav1.Duration `json:"checkInterval,omitempty"`
+}
+
+// WebhookConfiguration 注册到 API-Server 的 webhook 配置(热加载, ResyncPeriod 除外)
+type WebhookConfiguration struct {
+	// Service 不使用url注册webhook时的服务名, 默认 mpa-webhook
+	Service string `json:"service,omitempty"`
+	// TimeoutSeconds API-Server等待webhook响应的超时时间, 默认 30
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy webhook调用失败时的处理策略(Ignore/Fail), 默认 Ignore
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	// NamespaceSelector webhook的 namespaceSelector(label selector, 如 "a=b,c notin (d)"), 为空字符串时匹配所有命名空间
+	NamespaceSelector *string `json:"namespaceSelector,omitempty"`
+	// ObjectSelector webhook的 objectSelector(label selector), 为空字符串时匹配所有pod
+	ObjectSelector *string `json:"objectSelector,omitempty"`
+	// ReinvocationPolicy 其他webhook修改pod后是否重新调用(Never/IfNeeded), 默认 Never
+	ReinvocationPolicy string `json:"reinvocationPolicy,omitempty"`
+	// MatchPolicy webhook规则的匹配方式(Exact/Equivalent), 默认 Equivalent
+	MatchPolicy string `json:"matchPolicy,omitempty"`
+	// ResyncPeriod 检查webhook配置是否被修改的时间间隔, 默认 1m
+	ResyncPeriod metav1.Duration `json:"resyncPeriod,omitempty"`
+	// RemoveOnShutdown 正常退出时删除webhook配置(多副本部署时不建议开启)
+	RemoveOnShutdown bool `json:"removeOnShutdown,omitempty"`
+}
@@ -0,0 +1,129 @@
+package v1alpha1
+
+import (
+	"net"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateRecommenderConfiguration 校验(设置默认值后的) recommender 配置
+// 成本模型之间的约束(如: 搜索空间)由 recommendation.ModelFromConfiguration 校验
+func ValidateRecommenderConfiguration(c *RecommenderConfiguration) field.ErrorList {
+	allErrs := validateCommon(&c.ClientConnection, &c.Diagnostics)
+	allErrs = append(allErrs, validatePositiveDuration(c.RecommenderInterval, field.NewPath("recommenderInterval"))...)
+	if c.Workers <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("workers"), c.Workers, "must be positive"))
+	}
+	allErrs = append(allErrs, validatePositiveDuration(c.Plugin.Timeout, field.NewPath("plugin", "timeout"))...)
+	allErrs = append(allErrs, validateCostModel(&c.CostModel, field.NewPath("costModel"))...)
+	return allErrs
+}
+
+// ValidateUpdaterConfiguration 校验(设置默认值后的) updater 配置
+func ValidateUpdaterConfiguration(c *UpdaterConfiguration) field.ErrorList {
+	allErrs := validateCommon(&c.ClientConnection, &c.Diagnostics)
+	allErrs = append(allErrs, validatePositiveDuration(c.UpdaterInterval, field.NewPath("updaterInterval"))...)
+	if c.MinReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("minReplicas"), c.MinReplicas, "must be non-negative"))
+	}
+	if c.EvictionFraction != nil && (*c.EvictionFraction < 0 || *c.EvictionFraction > 1) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("evictionFraction"), *c.EvictionFraction, "must be in [0, 1]"))
+	}
+	return allErrs
+}
+
+// ValidateAdmissionConfiguration 校验(设置默认值后的) admission 配置
+// webhook 的策略及 selector 由 config.WebhookOptions 校验
+func ValidateAdmissionConfiguration(c *AdmissionConfiguration) field.ErrorList {
+	allErrs := validateCommon(&c.ClientConnection, &c.Diagnostics)
+	if c.Port <= 0 || c.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("port"), c.Port, "must be a valid port number"))
+	}
+
+	certsPath := field.NewPath("certs")
+	allErrs = append(allErrs, validatePositiveDuration(c.Certs.Validity, certsPath.Child("validity"))...)
+	allErrs = append(allErrs, validatePositiveDuration(c.Certs.RotateBefore, certsPath.Child("rotateBefore"))...)
+	allErrs = append(allErrs, validatePositiveDuration(c.Certs.CheckInterval, certsPath.Child("checkInterval"))...)
+	if c.Certs.RotateBefore.Duration >= c.Certs.Validity.Duration {
+		allErrs = append(allErrs, field.Invalid(certsPath.Child("rotateBefore"), c.Certs.RotateBefore.Duration.String(),
+			"must be less than validity"))
+	}
+
+	webhookPath := field.NewPath("webhook")
+	// API-Server 限制 webhook 的超时时间在 1 ~ 30 秒之间
+	if c.Webhook.TimeoutSeconds < 1 || c.Webhook.TimeoutSeconds > 30 {
+		allErrs = append(allErrs, field.Invalid(webhookPath.Child("timeoutSeconds"), c.Webhook.TimeoutSeconds, "must be in [1, 30]"))
+	}
+	allErrs = append(allErrs, validatePositiveDuration(c.Webhook.ResyncPeriod, webhookPath.Child("resyncPeriod"))...)
+	return allErrs
+}
+
+// validateCommon 校验各组件共有的配置
+func validateCommon(clientConnection *ClientConnectionConfiguration, diagnostics *DiagnosticsConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if clientConnection.QPS < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("clientConnection", "qps"), clientConnection.QPS, "must be non-negative"))
+	}
+	if clientConnection.Burst < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("clientConnection", "burst"), clientConnection.Burst, "must be non-negative"))
+	}
+	if _, _, err := net.SplitHostPort(diagnostics.Address); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("diagnostics", "address"), diagnostics.Address, err.Error()))
+	}
+	return allErrs
+}
+
+// validateCostModel 校验成本模型中各字段的取值范围
+func validateCostModel(c *CostModelConfiguration, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	cpus := make(map[int64]bool, len(c.PodCapacities))
+	for i, capacity := range c.PodCapacities {
+		capacityPath := path.Child("podCapacities").Index(i)
+		cpu := capacity.CPU.MilliValue()
+		if cpu <= 0 {
+			allErrs = append(allErrs, field.Invalid(capacityPath.Child("cpu"), capacity.CPU.String(), "must be positive"))
+		} else if cpus[cpu] {
+			allErrs = append(allErrs, field.Duplicate(capacityPath.Child("cpu"), capacity.CPU.String()))
+		}
+		cpus[cpu] = true
+		if capacity.QPS <= 0 {
+			allErrs = append(allErrs, field.Invalid(capacityPath.Child("qps"), capacity.QPS, "must be positive"))
+		}
+	}
+	if c.MaxReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxReplicas"), c.MaxReplicas, "must be non-negative"))
+	}
+	if c.CpuPrice < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("cpuPrice"), c.CpuPrice, "must be non-negative"))
+	}
+	allErrs = append(allErrs, validateRatio(c.ResourceCostRatio, path.Child("resourceCostRatio"))...)
+	if c.UpdateThreshold != nil && *c.UpdateThreshold < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("updateThreshold"), *c.UpdateThreshold, "must be non-negative"))
+	}
+	if c.DefaultResponseTime.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("defaultResponseTime"), c.DefaultResponseTime.Duration.String(), "must be non-negative"))
+	}
+	allErrs = append(allErrs, validateRatio(c.PreferenceWeight, path.Child("preferenceWeight"))...)
+	allErrs = append(allErrs, validateRatio(c.NearOptimalScoreRatio, path.Child("nearOptimalScoreRatio"))...)
+	allErrs = append(allErrs, validateRatio(c.QpsMinUncertainty, path.Child("qpsMinUncertainty"))...)
+	if c.DefaultTargetUtilization < 0 || c.DefaultTargetUtilization > 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("defaultTargetUtilization"), c.DefaultTargetUtilization, "must be in (0, 1]"))
+	}
+	return allErrs
+}
+
+// validateRatio 校验比例在 [0, 1] 内, 未指定时不校验
+func validateRatio(ratio *float64, path *field.Path) field.ErrorList {
+	if ratio != nil && (*ratio < 0 || *ratio > 1) {
+		return field.ErrorList{field.Invalid(path, *ratio, "must be in [0, 1]")}
+	}
+	return nil
+}
+
+func validatePositiveDuration(d metav1.Duration, path *field.Path) field.ErrorList {
+	if d.Duration <= 0 {
+		return field.ErrorList{field.Invalid(path, d.Duration.String(), "must be positive")}
+	}
+	return nil
+}
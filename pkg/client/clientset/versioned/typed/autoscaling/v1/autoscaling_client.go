@@ -27,7 +27,10 @@ import (
 
 type AutoscalingV1Interface interface {
 	RESTClient() rest.Interface
+	MpaBudgetsGetter
 	MultidimPodAutoscalersGetter
+	MultidimPodAutoscalerClusterPoliciesGetter
+	MultidimPodAutoscalerPoliciesGetter
 }
 
 // AutoscalingV1Client is used to interact with features provided by the autoscaling.k8s.io group.
@@ -35,10 +38,22 @@ type AutoscalingV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *AutoscalingV1Client) MpaBudgets(namespace string) MpaBudgetInterface {
+	return newMpaBudgets(c, namespace)
+}
+
 func (c *AutoscalingV1Client) MultidimPodAutoscalers(namespace string) MultidimPodAutoscalerInterface {
 	return newMultidimPodAutoscalers(c, namespace)
 }
 
+func (c *AutoscalingV1Client) MultidimPodAutoscalerClusterPolicies() MultidimPodAutoscalerClusterPolicyInterface {
+	return newMultidimPodAutoscalerClusterPolicies(c)
+}
+
+func (c *AutoscalingV1Client) MultidimPodAutoscalerPolicies(namespace string) MultidimPodAutoscalerPolicyInterface {
+	return newMultidimPodAutoscalerPolicies(c, namespace)
+}
+
 // NewForConfig creates a new AutoscalingV1Client for the given config.
 func NewForConfig(c *rest.Config) (*AutoscalingV1Client, error) {
 	config := *c
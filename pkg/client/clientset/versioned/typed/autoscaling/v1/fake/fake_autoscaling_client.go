@@ -29,10 +29,22 @@ type FakeAutoscalingV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeAutoscalingV1) MpaBudgets(namespace string) v1.MpaBudgetInterface {
+	return &FakeMpaBudgets{c, namespace}
+}
+
 func (c *FakeAutoscalingV1) MultidimPodAutoscalers(namespace string) v1.MultidimPodAutoscalerInterface {
 	return &FakeMultidimPodAutoscalers{c, namespace}
 }
 
+func (c *FakeAutoscalingV1) MultidimPodAutoscalerClusterPolicies() v1.MultidimPodAutoscalerClusterPolicyInterface {
+	return &FakeMultidimPodAutoscalerClusterPolicies{c}
+}
+
+func (c *FakeAutoscalingV1) MultidimPodAutoscalerPolicies(namespace string) v1.MultidimPodAutoscalerPolicyInterface {
+	return &FakeMultidimPodAutoscalerPolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeAutoscalingV1) RESTClient() rest.Interface {
@@ -18,4 +18,10 @@ limitations under the License.
 
 package v1
 
+type MpaBudgetExpansion interface{}
+
 type MultidimPodAutoscalerExpansion interface{}
+
+type MultidimPodAutoscalerClusterPolicyExpansion interface{}
+
+type MultidimPodAutoscalerPolicyExpansion interface{}
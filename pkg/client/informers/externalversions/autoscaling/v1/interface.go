@@ -24,8 +24,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// MpaBudgets returns a MpaBudgetInformer.
+	MpaBudgets() MpaBudgetInformer
 	// MultidimPodAutoscalers returns a MultidimPodAutoscalerInformer.
 	MultidimPodAutoscalers() MultidimPodAutoscalerInformer
+	// MultidimPodAutoscalerClusterPolicies returns a MultidimPodAutoscalerClusterPolicyInformer.
+	MultidimPodAutoscalerClusterPolicies() MultidimPodAutoscalerClusterPolicyInformer
+	// MultidimPodAutoscalerPolicies returns a MultidimPodAutoscalerPolicyInformer.
+	MultidimPodAutoscalerPolicies() MultidimPodAutoscalerPolicyInformer
 }
 
 type version struct {
@@ -39,7 +45,22 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// MpaBudgets returns a MpaBudgetInformer.
+func (v *version) MpaBudgets() MpaBudgetInformer {
+	return &mpaBudgetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // MultidimPodAutoscalers returns a MultidimPodAutoscalerInformer.
 func (v *version) MultidimPodAutoscalers() MultidimPodAutoscalerInformer {
 	return &multidimPodAutoscalerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// MultidimPodAutoscalerClusterPolicies returns a MultidimPodAutoscalerClusterPolicyInformer.
+func (v *version) MultidimPodAutoscalerClusterPolicies() MultidimPodAutoscalerClusterPolicyInformer {
+	return &multidimPodAutoscalerClusterPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// MultidimPodAutoscalerPolicies returns a MultidimPodAutoscalerPolicyInformer.
+func (v *version) MultidimPodAutoscalerPolicies() MultidimPodAutoscalerPolicyInformer {
+	return &multidimPodAutoscalerPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
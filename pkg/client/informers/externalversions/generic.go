@@ -53,8 +53,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=autoscaling.k8s.io, Version=v1
+	case v1.SchemeGroupVersion.WithResource("mpabudgets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Autoscaling().V1().MpaBudgets().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("multidimpodautoscalers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Autoscaling().V1().MultidimPodAutoscalers().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("multidimpodautoscalerclusterpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Autoscaling().V1().MultidimPodAutoscalerClusterPolicies().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("multidimpodautoscalerpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Autoscaling().V1().MultidimPodAutoscalerPolicies().Informer()}, nil
 
 	}
 
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1
 
+// MpaBudgetListerExpansion allows custom methods to be added to
+// MpaBudgetLister.
+type MpaBudgetListerExpansion interface{}
+
+// MpaBudgetNamespaceListerExpansion allows custom methods to be added to
+// MpaBudgetNamespaceLister.
+type MpaBudgetNamespaceListerExpansion interface{}
+
 // MultidimPodAutoscalerListerExpansion allows custom methods to be added to
 // MultidimPodAutoscalerLister.
 type MultidimPodAutoscalerListerExpansion interface{}
@@ -25,3 +33,15 @@ type MultidimPodAutoscalerListerExpansion interface{}
 // MultidimPodAutoscalerNamespaceListerExpansion allows custom methods to be added to
 // MultidimPodAutoscalerNamespaceLister.
 type MultidimPodAutoscalerNamespaceListerExpansion interface{}
+
+// MultidimPodAutoscalerClusterPolicyListerExpansion allows custom methods to be added to
+// MultidimPodAutoscalerClusterPolicyLister.
+type MultidimPodAutoscalerClusterPolicyListerExpansion interface{}
+
+// MultidimPodAutoscalerPolicyListerExpansion allows custom methods to be added to
+// MultidimPodAutoscalerPolicyLister.
+type MultidimPodAutoscalerPolicyListerExpansion interface{}
+
+// MultidimPodAutoscalerPolicyNamespaceListerExpansion allows custom methods to be added to
+// MultidimPodAutoscalerPolicyNamespaceLister.
+type MultidimPodAutoscalerPolicyNamespaceListerExpansion interface{}
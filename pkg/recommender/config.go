@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+
+	"k8s.io/klog"
+	configv1alpha1 "multidim-pod-autoscaler/pkg/apis/config/v1alpha1"
+	"multidim-pod-autoscaler/pkg/recommender/logic"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+	"multidim-pod-autoscaler/pkg/util/diagnostics"
+)
+
+var (
+	configFile, configReloadPeriod = utilConfig.RegisterFlags(flag.CommandLine, configv1alpha1.RecommenderConfigurationKind)
+)
+
+// loadConfiguration 解析配置文件(data 为 nil 时只使用命令行参数), 设置默认值,
+// 用命令行中显式指定的参数覆盖文件中的值, 最后进行校验
+// 同时返回由配置中的成本模型转换得到的 recommendation.Model
+func loadConfiguration(data []byte) (*configv1alpha1.RecommenderConfiguration, recommendation.Model, error) {
+	c := &configv1alpha1.RecommenderConfiguration{}
+	if data != nil {
+		gvk := configv1alpha1.SchemeGroupVersion.WithKind(configv1alpha1.RecommenderConfigurationKind)
+		if err := utilConfig.Decode(data, gvk, c); err != nil {
+			return nil, recommendation.Model{}, err
+		}
+	}
+	configv1alpha1.SetDefaults_RecommenderConfiguration(c)
+	for name := range utilConfig.ExplicitFlags() {
+		switch name {
+		case "recommender-interval":
+			c.RecommenderInterval.Duration = *recommenderInterval
+		case "default-algorithm":
+			c.DefaultAlgorithm = *defaultAlgorithm
+		case "recommender-workers":
+			c.Workers = int32(*recommenderWorkers)
+		case "recommendation-plugin-address":
+			c.Plugin.Address = *pluginAddress
+		case "recommendation-plugin-timeout":
+			c.Plugin.Timeout.Duration = *pluginTimeout
+		case "address":
+			c.Diagnostics.Address = *metricsAddress
+		case "profiling":
+			c.Diagnostics.EnableProfiling = *profiling
+		case "kube-api-qps":
+			c.ClientConnection.QPS = float32(*kubeApiQps)
+		case "kube-api-burst":
+			c.ClientConnection.Burst = int32(*kubeApiBurst)
+		case "mpa-object-namespace":
+			c.MpaObjectNamespace = *mpaObjectNamespace
+		}
+	}
+	if errs := configv1alpha1.ValidateRecommenderConfiguration(c); len(errs) > 0 {
+		return nil, recommendation.Model{}, errs.ToAggregate()
+	}
+	model, err := recommendation.ModelFromConfiguration(&c.CostModel)
+	if err != nil {
+		return nil, recommendation.Model{}, err
+	}
+	return c, model, nil
+}
+
+// configurationReloader 在配置文件变化时应用新的配置
+// 推荐间隔、默认算法及成本模型立即生效, 其他字段被修改时只打印警告(重启后生效)
+type configurationReloader struct {
+	// initial 启动时的配置
+	initial     *configv1alpha1.RecommenderConfiguration
+	recommender logic.Recommender
+	algorithms  *recommendation.Registry
+	progress    *diagnostics.Progress
+}
+
+// reload 加载并应用 data 中的配置, 配置不合法时保持当前配置
+func (r *configurationReloader) reload(data []byte) {
+	c, model, err := loadConfiguration(data)
+	if err != nil {
+		klog.Errorf("invalid configuration, keep using the current one: %v", err)
+		return
+	}
+	if err := r.algorithms.SetDefault(c.DefaultAlgorithm); err != nil {
+		klog.Errorf("invalid configuration, keep using the current one: %v", err)
+		return
+	}
+	if err := recommendation.SetModel(model); err != nil {
+		klog.Errorf("invalid cost model, keep using the current one: %v", err)
+	}
+	r.recommender.SetResyncPeriod(c.RecommenderInterval.Duration)
+	r.progress.SetInterval(c.RecommenderInterval.Duration)
+
+	old := r.initial
+	if !reflect.DeepEqual(old.ClientConnection, c.ClientConnection) || !reflect.DeepEqual(old.Diagnostics, c.Diagnostics) ||
+		old.MpaObjectNamespace != c.MpaObjectNamespace || old.Workers != c.Workers || !reflect.DeepEqual(old.Plugin, c.Plugin) {
+		klog.Warningf("clientConnection, diagnostics, mpaObjectNamespace, workers and plugin take effect after restart")
+	}
+	klog.Infof("configuration reloaded: recommenderInterval=%v, defaultAlgorithm=%s", c.RecommenderInterval.Duration, c.DefaultAlgorithm)
+}
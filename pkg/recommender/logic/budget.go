@@ -0,0 +1,299 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+	"multidim-pod-autoscaler/pkg/util"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// budgetConstraintName MpaBudget 约束名
+	budgetConstraintName = "MpaBudget"
+)
+
+// budgetAllocation 一个 MpaBudget 最近一次联合分配的结果
+// 在 resync 间隔内、预算的 spec 及选中的 MPA 不变时复用, 同一预算选中的每个 MPA 处理时不重复计算
+type budgetAllocation struct {
+	spec    mpaTypes.MpaBudgetSpec
+	members string
+	time    time.Time
+	// names、demands 与 result.Plans 的顺序一致
+	names   []string
+	demands []recommendation.BudgetDemand
+	limits  recommendation.BudgetLimits
+	result  *recommendation.BudgetAllocation
+}
+
+// budgetConstraints 返回选中 current 的 MpaBudget 对推荐方案的约束
+// mpas 为同一命名空间内参与推荐(更新模式为 Auto)的所有 MPA
+func (r *recommender) budgetConstraints(
+	ctx context.Context,
+	current *utilMpa.MpaWithSelector,
+	mpas []*utilMpa.MpaWithSelector,
+) []recommendation.PlanConstraint {
+	if r.budgetLister == nil {
+		return nil
+	}
+	budgets, err := r.budgetLister.MpaBudgets(current.Mpa.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Warningf("failed to list MpaBudgets in namespace %s: %v", current.Mpa.Namespace, err)
+		return nil
+	}
+
+	constraints := make([]recommendation.PlanConstraint, 0)
+	for _, budget := range budgets {
+		selector := labels.Everything()
+		if budget.Spec.Selector != nil {
+			selector, err = metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+			if err != nil {
+				klog.Warningf("invalid selector of MpaBudget %s/%s, ignored: %v", budget.Namespace, budget.Name, err)
+				continue
+			}
+		}
+		if !selector.Matches(labels.Set(current.Mpa.Labels)) {
+			continue
+		}
+		members := make([]*utilMpa.MpaWithSelector, 0)
+		for _, mpa := range mpas {
+			if selector.Matches(labels.Set(mpa.Mpa.Labels)) {
+				members = append(members, mpa)
+			}
+		}
+		allocation := r.getBudgetAllocation(ctx, budget, members, mpas)
+		if constraint := allocation.constraintFor(budget.Name, current.Mpa.Name); constraint != nil {
+			constraints = append(constraints, *constraint)
+		}
+	}
+	return constraints
+}
+
+// getBudgetAllocation 返回预算 budget 对 members 的联合分配结果, 缓存失效时重新计算并更新预算的状态
+func (r *recommender) getBudgetAllocation(
+	ctx context.Context,
+	budget *mpaTypes.MpaBudget,
+	members []*utilMpa.MpaWithSelector,
+	mpas []*utilMpa.MpaWithSelector,
+) *budgetAllocation {
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Mpa.Name < members[j].Mpa.Name
+	})
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Mpa.Name)
+	}
+	key := budget.Namespace + "/" + budget.Name
+	membersKey := strings.Join(names, ",")
+
+	// 同一预算同时只计算一次, 其他 worker 等待并复用结果
+	r.budgetLock.Lock()
+	defer r.budgetLock.Unlock()
+	if cached := r.budgetAllocations[key]; cached != nil && cached.members == membersKey &&
+		equality.Semantic.DeepEqual(cached.spec, budget.Spec) && time.Since(cached.time) < r.getResyncPeriod() {
+		return cached
+	}
+
+	allocation := &budgetAllocation{
+		spec:    *budget.Spec.DeepCopy(),
+		members: membersKey,
+		time:    time.Now(),
+		names:   names,
+		demands: make([]recommendation.BudgetDemand, 0, len(members)),
+		limits:  budgetLimits(&budget.Spec),
+	}
+	for _, member := range members {
+		allocation.demands = append(allocation.demands, r.budgetDemand(member, mpas))
+	}
+	allocation.result = recommendation.AllocateBudget(allocation.demands, allocation.limits)
+	klog.V(2).Infof("MpaBudget %s allocated %v for %d MPA(s) (binding: %v, feasible: %v)", key,
+		allocation.result.Used, len(members), allocation.result.Binding, allocation.result.Feasible)
+	r.budgetAllocations[key] = allocation
+	r.updateBudgetStatus(ctx, budget, allocation)
+	return allocation
+}
+
+// forgetBudgetAllocations 删除命名空间 namespace 中所有预算的缓存(该命名空间已由其他副本负责)
+func (r *recommender) forgetBudgetAllocations(namespace string) {
+	r.budgetLock.Lock()
+	defer r.budgetLock.Unlock()
+	for key := range r.budgetAllocations {
+		if strings.HasPrefix(key, namespace+"/") {
+			delete(r.budgetAllocations, key)
+		}
+	}
+}
+
+// budgetDemand 计算预算选中的 MPA 的可行方案
+// 算法不支持评估方案(或评估失败)时, 该 MPA 不参与联合分配, 以当前的推荐方案计入预算的用量
+func (r *recommender) budgetDemand(member *utilMpa.MpaWithSelector, mpas []*utilMpa.MpaWithSelector) recommendation.BudgetDemand {
+	demand := recommendation.BudgetDemand{}
+	pods, err := r.controlledPods(member, mpas)
+	if err != nil {
+		klog.Warningf("failed to get pods of MPA(%s/%s) for budget allocation: %v", member.Mpa.Namespace, member.Mpa.Name, err)
+		return demand
+	}
+	if len(pods) == 0 {
+		return demand
+	}
+	// 推荐的 cpu 应用到 pod 的每个容器, memory 保持不变
+	demand.ContainerNum = int64(len(pods[0].Spec.Containers))
+	for _, container := range pods[0].Spec.Containers {
+		demand.PodMemory += container.Resources.Requests.Memory().Value()
+	}
+
+	effective, _ := r.policyResolver.Resolve(member.Mpa)
+	calculateTarget := &utilMpa.MpaWithSelector{Mpa: effective, Selector: member.Selector}
+	if algorithm, err := r.recommendationAlgorithms.Get(effective); err == nil {
+		if evaluator, ok := algorithm.(recommendation.PlanEvaluator); ok {
+			candidates, err := evaluator.EvaluatePlans(calculateTarget, pods, r.planConstraints(calculateTarget, &effective.Spec, pods)...)
+			if err != nil {
+				klog.Warningf("failed to evaluate plans of MPA(%s/%s) for budget allocation: %v", member.Mpa.Namespace, member.Mpa.Name, err)
+			} else if len(candidates) > 0 {
+				demand.Candidates = candidates
+				return demand
+			}
+		}
+	}
+	demand.Fixed = currentPlan(member.Mpa, pods)
+	return demand
+}
+
+// currentPlan 返回 mpa 当前的推荐方案, 没有推荐方案时返回 pods 当前的副本数及 cpu request
+func currentPlan(mpa *mpaTypes.MultidimPodAutoscaler, pods []*corev1.Pod) *recommendation.PlanCandidate {
+	if res := mpa.Status.RecommendationResources; res != nil && res.TargetPodNum > 0 && len(res.ContainerRecommendations) > 0 {
+		if cpu, existed := res.ContainerRecommendations[0].Target[corev1.ResourceCPU]; existed {
+			return &recommendation.PlanCandidate{PodNum: int64(res.TargetPodNum), CpuMilli: cpu.MilliValue()}
+		}
+	}
+	plan := &recommendation.PlanCandidate{PodNum: int64(len(pods))}
+	if len(pods[0].Spec.Containers) > 0 {
+		plan.CpuMilli = pods[0].Spec.Containers[0].Resources.Requests.Cpu().MilliValue()
+	}
+	return plan
+}
+
+// budgetLimits 返回预算的资源上限, 未指定的资源不限制
+func budgetLimits(spec *mpaTypes.MpaBudgetSpec) recommendation.BudgetLimits {
+	limits := recommendation.BudgetLimits{CpuMilli: -1, Memory: -1, Replicas: -1}
+	if spec.MaxCpu != nil {
+		limits.CpuMilli = spec.MaxCpu.MilliValue()
+	}
+	if spec.MaxMemory != nil {
+		limits.Memory = spec.MaxMemory.Value()
+	}
+	if spec.MaxReplicas != nil {
+		limits.Replicas = int64(*spec.MaxReplicas)
+	}
+	return limits
+}
+
+// constraintFor 返回预算对 MPA name 的约束
+// 预算不生效或该 MPA 不参与联合分配时返回 nil
+// 每个 MPA 的份额 = 分配的方案占用的资源 + 预算剩余资源的平均值, 各 MPA 在份额内独立选择方案时总和不超出预算
+func (a *budgetAllocation) constraintFor(budgetName, name string) *recommendation.PlanConstraint {
+	if !a.result.Binding {
+		return nil
+	}
+	index := -1
+	var joint int64
+	for i := range a.demands {
+		if len(a.demands[i].Candidates) == 0 || a.result.Plans[i] == nil {
+			continue
+		}
+		joint += 1
+		if a.names[i] == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	demand := a.demands[index]
+	plan := a.result.Plans[index]
+	share := recommendation.BudgetLimits(demand.Usage(plan))
+	// 预算不可行时, 只允许资源最少的方案
+	if a.result.Feasible {
+		share.CpuMilli = shareOf(share.CpuMilli, a.limits.CpuMilli, a.result.Used.CpuMilli, joint)
+		share.Memory = shareOf(share.Memory, a.limits.Memory, a.result.Used.Memory, joint)
+		share.Replicas = shareOf(share.Replicas, a.limits.Replicas, a.result.Used.Replicas, joint)
+	}
+	description := fmt.Sprintf("MpaBudget %s allocates %d pods × %dm cpu", budgetName, plan.PodNum, plan.CpuMilli)
+	return &recommendation.PlanConstraint{
+		Name:        budgetConstraintName,
+		Description: description,
+		Allows: func(podNum, cpuMilli int64) bool {
+			return share.Allows(demand.Usage(&recommendation.PlanCandidate{PodNum: podNum, CpuMilli: cpuMilli}))
+		},
+	}
+}
+
+// shareOf 返回分配的资源 allocated 加上预算剩余资源(limit - used)的 1/n, 资源不限制(limit < 0)时返回 -1
+func shareOf(allocated, limit, used, n int64) int64 {
+	if limit < 0 {
+		return -1
+	}
+	return allocated + (limit-used)/n
+}
+
+// updateBudgetStatus 将联合分配的结果记录到预算的状态中, 只在结果变化时更新
+func (r *recommender) updateBudgetStatus(ctx context.Context, budget *mpaTypes.MpaBudget, allocation *budgetAllocation) {
+	result := allocation.result
+	status := mpaTypes.MpaBudgetStatus{
+		Binding:     result.Binding,
+		Allocations: make([]mpaTypes.MpaBudgetAllocation, 0, len(allocation.names)),
+		Allocated: mpaTypes.MpaBudgetUsage{
+			Cpu:      resource.NewMilliQuantity(result.Used.CpuMilli, resource.DecimalSI),
+			Memory:   resource.NewQuantity(result.Used.Memory, resource.BinarySI),
+			Replicas: int32(result.Used.Replicas),
+		},
+		LastAllocationTime: budget.Status.LastAllocationTime,
+	}
+	for i, name := range allocation.names {
+		plan := result.Plans[i]
+		if plan == nil {
+			continue
+		}
+		item := mpaTypes.MpaBudgetAllocation{
+			Name:   name,
+			PodNum: int(plan.PodNum),
+			Cpu:    resource.NewMilliQuantity(plan.CpuMilli, resource.DecimalSI),
+		}
+		if len(allocation.demands[i].Candidates) > 0 {
+			item.Score = strconv.FormatFloat(plan.Score, 'g', 4, 64)
+		}
+		status.Allocations = append(status.Allocations, item)
+	}
+	if !result.Feasible {
+		status.Message = "the smallest plans of the selected MPAs still exceed the budget"
+	} else if result.Binding {
+		status.Message = "the best plans of the selected MPAs exceed the budget, allocated jointly by plan scores"
+	}
+	if equality.Semantic.DeepEqual(budget.Status, status) {
+		return
+	}
+
+	now := metav1.Now()
+	status.LastAllocationTime = &now
+	budgetCopy := budget.DeepCopy()
+	budgetCopy.Status = status
+	err := util.RetryOnTransientError(ctx, func() error {
+		_, err := r.mpaclientset.AutoscalingV1().MpaBudgets(budget.Namespace).Update(ctx, budgetCopy, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Warningf("failed to update status of MpaBudget %s/%s: %v", budget.Namespace, budget.Name, err)
+	}
+}
@@ -0,0 +1,209 @@
+package logic
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	coreListers "k8s.io/client-go/listers/core/v1"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+	"sort"
+)
+
+const (
+	// capacityConstraintName 节点容量约束名
+	capacityConstraintName = "NodeCapacity"
+)
+
+// nodeFree 节点上剩余的可分配资源(包含目标负载当前占用的资源)
+type nodeFree struct {
+	name string
+	// cpu 单位为 m, memory 单位为 byte
+	cpu    int64
+	memory int64
+	pods   int64
+}
+
+// clusterCapacity 目标负载可调度节点的剩余资源
+type clusterCapacity struct {
+	nodes []nodeFree
+	// maxCpu、maxMemory 单个节点上最大的剩余 cpu、memory
+	maxCpu    int64
+	maxMemory int64
+}
+
+// newCapacityConstraint 根据集群中节点的剩余资源构造推荐方案的约束
+// 节点剩余资源 = allocatable - 节点上未结束 pods 的 requests + 目标负载在该节点上的 pods 的 requests(这些 pods 会被替换)
+// 只使用简单的调度谓词: 节点可调度且 Ready、nodeSelector 匹配、容忍节点上 NoSchedule / NoExecute 的污点
+// 方案的所有副本能贪心装入节点时才满足约束; 装箱越紧凑的方案偏好程度越高
+func newCapacityConstraint(nodeLister coreListers.NodeLister, podLister coreListers.PodLister, pods []*corev1.Pod) (*recommendation.PlanConstraint, error) {
+	if nodeLister == nil || podLister == nil || len(pods) == 0 {
+		return nil, nil
+	}
+	capacity, err := getClusterCapacity(nodeLister, podLister, pods)
+	if err != nil {
+		return nil, err
+	}
+
+	// 推荐的 cpu 应用到 pod 的每个容器, memory 保持不变
+	template := pods[0]
+	containerNum := int64(len(template.Spec.Containers))
+	var templateMemory int64
+	for _, container := range template.Spec.Containers {
+		templateMemory += container.Resources.Requests.Memory().Value()
+	}
+
+	return &recommendation.PlanConstraint{
+		Name:        capacityConstraintName,
+		Description: capacity.describe(),
+		Allows: func(podNum, cpuMilli int64) bool {
+			_, placed := capacity.pack(podNum, cpuMilli*containerNum, templateMemory)
+			return placed >= podNum
+		},
+		Preference: func(podNum, cpuMilli int64) float64 {
+			efficiency, _ := capacity.pack(podNum, cpuMilli*containerNum, templateMemory)
+			return efficiency
+		},
+	}, nil
+}
+
+// getClusterCapacity 计算目标负载(pods)可调度节点的剩余资源
+func getClusterCapacity(nodeLister coreListers.NodeLister, podLister coreListers.PodLister, pods []*corev1.Pod) (*clusterCapacity, error) {
+	nodes, err := nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	allPods, err := podLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	ownPods := make(map[types.UID]bool, len(pods))
+	for _, pod := range pods {
+		ownPods[pod.UID] = true
+	}
+	// 各节点上已分配的资源
+	usedCpu := map[string]int64{}
+	usedMemory := map[string]int64{}
+	usedPods := map[string]int64{}
+	for _, pod := range allPods {
+		if pod.Spec.NodeName == "" || ownPods[pod.UID] ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			usedCpu[pod.Spec.NodeName] += container.Resources.Requests.Cpu().MilliValue()
+			usedMemory[pod.Spec.NodeName] += container.Resources.Requests.Memory().Value()
+		}
+		usedPods[pod.Spec.NodeName] += 1
+	}
+
+	template := pods[0]
+	capacity := &clusterCapacity{}
+	for _, node := range nodes {
+		if !podFitsNode(template, node) {
+			continue
+		}
+		free := nodeFree{
+			name:   node.Name,
+			cpu:    node.Status.Allocatable.Cpu().MilliValue() - usedCpu[node.Name],
+			memory: node.Status.Allocatable.Memory().Value() - usedMemory[node.Name],
+			pods:   node.Status.Allocatable.Pods().Value() - usedPods[node.Name],
+		}
+		if free.cpu <= 0 || free.memory < 0 || free.pods <= 0 {
+			continue
+		}
+		if free.cpu > capacity.maxCpu {
+			capacity.maxCpu = free.cpu
+		}
+		if free.memory > capacity.maxMemory {
+			capacity.maxMemory = free.memory
+		}
+		capacity.nodes = append(capacity.nodes, free)
+	}
+	// 剩余 cpu 多的节点优先装箱
+	sort.Slice(capacity.nodes, func(i, j int) bool {
+		if capacity.nodes[i].cpu != capacity.nodes[j].cpu {
+			return capacity.nodes[i].cpu > capacity.nodes[j].cpu
+		}
+		return capacity.nodes[i].name < capacity.nodes[j].name
+	})
+	return capacity, nil
+}
+
+// podFitsNode 使用简单的调度谓词判断 pod 能否调度到 node 上
+func podFitsNode(pod *corev1.Pod, node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	ready := false
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			ready = condition.Status == corev1.ConditionTrue
+			break
+		}
+	}
+	if !ready {
+		return false
+	}
+	if len(pod.Spec.NodeSelector) > 0 &&
+		!labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// pack 按节点剩余 cpu 从大到小的顺序, 将 podNum 个 (podCpu, podMemory) 的副本贪心装入节点
+// 返回装箱效率(副本占用的 cpu / 使用到的节点的剩余 cpu)和能放下的副本数
+func (c *clusterCapacity) pack(podNum, podCpu, podMemory int64) (float64, int64) {
+	if podNum <= 0 || podCpu > c.maxCpu || podMemory > c.maxMemory {
+		return 0, 0
+	}
+	var placed, usedNodeCpu int64
+	for _, node := range c.nodes {
+		if placed >= podNum {
+			break
+		}
+		fit := node.pods
+		if podCpu > 0 && node.cpu/podCpu < fit {
+			fit = node.cpu / podCpu
+		}
+		if podMemory > 0 && node.memory/podMemory < fit {
+			fit = node.memory / podMemory
+		}
+		if fit <= 0 {
+			continue
+		}
+		if fit > podNum-placed {
+			fit = podNum - placed
+		}
+		placed += fit
+		usedNodeCpu += node.cpu
+	}
+	if usedNodeCpu == 0 {
+		return 0, placed
+	}
+	return float64(placed*podCpu) / float64(usedNodeCpu), placed
+}
+
+// describe 返回节点剩余资源的描述, 用于状态条件的 message
+func (c *clusterCapacity) describe() string {
+	return fmt.Sprintf("%d schedulable nodes, largest free cpu %dm, largest free memory %dMi",
+		len(c.nodes), c.maxCpu, c.maxMemory/(1024*1024))
+}
@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"testing"
+)
+
+func TestClusterCapacityPack(t *testing.T) {
+	const gi = int64(1 << 30)
+	// 节点按剩余 cpu 从大到小排列(与 getClusterCapacity 一致)
+	twoNodes := &clusterCapacity{
+		nodes: []nodeFree{
+			{name: "node-1", cpu: 4000, memory: 8 * gi, pods: 110},
+			{name: "node-2", cpu: 2000, memory: 4 * gi, pods: 110},
+		},
+		maxCpu:    4000,
+		maxMemory: 8 * gi,
+	}
+	fewPods := &clusterCapacity{
+		nodes: []nodeFree{
+			{name: "node-1", cpu: 4000, memory: 8 * gi, pods: 1},
+			{name: "node-2", cpu: 2000, memory: 4 * gi, pods: 1},
+		},
+		maxCpu:    4000,
+		maxMemory: 8 * gi,
+	}
+
+	tests := []struct {
+		name               string
+		capacity           *clusterCapacity
+		podNum             int64
+		podCpu             int64
+		podMemory          int64
+		expectedPlaced     int64
+		expectedEfficiency float64
+	}{
+		{
+			name:     "no pods",
+			capacity: twoNodes,
+			podNum:   0, podCpu: 1000, podMemory: gi,
+			expectedPlaced: 0, expectedEfficiency: 0,
+		},
+		{
+			name:     "pod cpu larger than any node",
+			capacity: twoNodes,
+			podNum:   1, podCpu: 5000, podMemory: gi,
+			expectedPlaced: 0, expectedEfficiency: 0,
+		},
+		{
+			name:     "pod memory larger than any node",
+			capacity: twoNodes,
+			podNum:   1, podCpu: 1000, podMemory: 9 * gi,
+			expectedPlaced: 0, expectedEfficiency: 0,
+		},
+		{
+			name:     "all pods fit on the largest node",
+			capacity: twoNodes,
+			podNum:   4, podCpu: 1000, podMemory: gi,
+			expectedPlaced: 4, expectedEfficiency: 1,
+		},
+		{
+			name:     "pods spill over to the next node",
+			capacity: twoNodes,
+			podNum:   5, podCpu: 1000, podMemory: gi,
+			expectedPlaced: 5, expectedEfficiency: 5000.0 / 6000.0,
+		},
+		{
+			name:     "more pods than the cluster can hold",
+			capacity: twoNodes,
+			podNum:   7, podCpu: 1000, podMemory: gi,
+			expectedPlaced: 6, expectedEfficiency: 1,
+		},
+		{
+			name:     "placement limited by memory",
+			capacity: twoNodes,
+			podNum:   3, podCpu: 500, podMemory: 4 * gi,
+			expectedPlaced: 3, expectedEfficiency: 1500.0 / 6000.0,
+		},
+		{
+			name:     "placement limited by allocatable pods",
+			capacity: fewPods,
+			podNum:   3, podCpu: 1000, podMemory: gi,
+			expectedPlaced: 2, expectedEfficiency: 2000.0 / 6000.0,
+		},
+		{
+			name:     "empty cluster",
+			capacity: &clusterCapacity{},
+			podNum:   1, podCpu: 0, podMemory: 0,
+			expectedPlaced: 0, expectedEfficiency: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			efficiency, placed := tc.capacity.pack(tc.podNum, tc.podCpu, tc.podMemory)
+			if placed != tc.expectedPlaced {
+				t.Errorf("expected %d pods placed, got %d", tc.expectedPlaced, placed)
+			}
+			if efficiency != tc.expectedEfficiency {
+				t.Errorf("expected efficiency %v, got %v", tc.expectedEfficiency, efficiency)
+			}
+		})
+	}
+}
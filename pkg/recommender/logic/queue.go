@@ -0,0 +1,203 @@
+package logic
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	recommenderMetrics "multidim-pod-autoscaler/pkg/recommender/metrics"
+	"multidim-pod-autoscaler/pkg/target"
+)
+
+// registerEventHandlers 注册 MPA 及其 targetRef 的事件回调
+// MPA 创建、spec 改变, 以及 targetRef 指向的 controller 的 spec 改变时, 将对应的 MPA 加入 queue
+func (r *recommender) registerEventHandlers(mpaInformer cache.SharedIndexInformer, factory informers.SharedInformerFactory) {
+	mpaInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: r.enqueueMpa,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMpa, oldOk := oldObj.(*mpaTypes.MultidimPodAutoscaler)
+			newMpa, newOk := newObj.(*mpaTypes.MultidimPodAutoscaler)
+			// recommender 自身会更新 MPA 的 status, 只有 spec 改变时才需要重新计算
+			if oldOk && newOk && equality.Semantic.DeepEqual(oldMpa.Spec, newMpa.Spec) {
+				return
+			}
+			r.enqueueMpa(newObj)
+		},
+		DeleteFunc: r.enqueueMpa,
+	})
+
+	if factory == nil {
+		return
+	}
+	targetInformers := map[target.WellKnownController]cache.SharedIndexInformer{
+		target.DaemonSet:             factory.Apps().V1().DaemonSets().Informer(),
+		target.Deployment:            factory.Apps().V1().Deployments().Informer(),
+		target.ReplicaSet:            factory.Apps().V1().ReplicaSets().Informer(),
+		target.StatefulSet:           factory.Apps().V1().StatefulSets().Informer(),
+		target.ReplicationController: factory.Core().V1().ReplicationControllers().Informer(),
+		target.Job:                   factory.Batch().V1().Jobs().Informer(),
+		target.CronJob:               factory.Batch().V1beta1().CronJobs().Informer(),
+	}
+	for kind, informer := range targetInformers {
+		kind := kind
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldTarget, oldErr := meta.Accessor(oldObj)
+				newTarget, newErr := meta.Accessor(newObj)
+				// 只关注 spec 的改变(如: 副本数、selector、资源配置等)
+				if oldErr == nil && newErr == nil && oldTarget.GetGeneration() == newTarget.GetGeneration() {
+					return
+				}
+				r.enqueueMpasForTarget(kind, newObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				r.enqueueMpasForTarget(kind, obj)
+			},
+		})
+	}
+}
+
+// registerBudgetEventHandlers 注册 MpaBudget 的事件回调
+// 预算创建、删除及 spec 改变时, 将预算所在命名空间中的所有 MPA 加入 queue
+func (r *recommender) registerBudgetEventHandlers(budgetInformer cache.SharedIndexInformer) {
+	budgetInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: r.enqueueMpasForBudget,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldBudget, oldOk := oldObj.(*mpaTypes.MpaBudget)
+			newBudget, newOk := newObj.(*mpaTypes.MpaBudget)
+			// recommender 自身会更新预算的 status
+			if oldOk && newOk && equality.Semantic.DeepEqual(oldBudget.Spec, newBudget.Spec) {
+				return
+			}
+			r.enqueueMpasForBudget(newObj)
+		},
+		DeleteFunc: r.enqueueMpasForBudget,
+	})
+}
+
+// enqueueMpasForBudget 将预算 obj 所在命名空间中的所有 MPA 加入 queue
+func (r *recommender) enqueueMpasForBudget(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	budget, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	mpas, err := r.mpaLister.MultidimPodAutoscalers(budget.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mpa := range mpas {
+		r.enqueueMpa(mpa)
+	}
+}
+
+// enqueueMpa 将 MPA 以 "namespace/name" 的形式加入 queue
+func (r *recommender) enqueueMpa(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	r.queue.Add(key)
+}
+
+// enqueueAllMpas 将缓存中的所有 MPA 加入 queue
+func (r *recommender) enqueueAllMpas() {
+	mpas, err := r.mpaLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mpa := range mpas {
+		r.enqueueMpa(mpa)
+	}
+}
+
+// enqueueMpasForTarget 将 targetRef 指向 obj 的所有 MPA 加入 queue
+func (r *recommender) enqueueMpasForTarget(kind target.WellKnownController, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	targetObj, err := meta.Accessor(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	mpas, err := r.mpaLister.MultidimPodAutoscalers(targetObj.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mpa := range mpas {
+		if mpa.Spec.TargetRef != nil &&
+			mpa.Spec.TargetRef.Kind == string(kind) &&
+			mpa.Spec.TargetRef.Name == targetObj.GetName() {
+			klog.V(4).Infof("%s %s/%s changed, enqueue MPA(%s/%s)", kind, targetObj.GetNamespace(), targetObj.GetName(), mpa.Namespace, mpa.Name)
+			r.enqueueMpa(mpa)
+		}
+	}
+}
+
+// runWorker 不断地从 queue 中取出 MPA 并处理, 直到 queue 被关闭或 ctx 结束
+func (r *recommender) runWorker(ctx context.Context) {
+	for r.processNextItem(ctx) {
+	}
+}
+
+// processNextItem 处理 queue 中的下一个 MPA
+// queue 被关闭时返回 false
+func (r *recommender) processNextItem(ctx context.Context) bool {
+	item, shutdown := r.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer r.queue.Done(item)
+	// queue 关闭后仍会返回剩余的MPA, 退出时不再处理
+	if ctx.Err() != nil {
+		return false
+	}
+
+	key, ok := item.(string)
+	if !ok {
+		r.queue.Forget(item)
+		return true
+	}
+	// 按命名空间分片: 同一命名空间的 MPA 由同一个副本处理, MpaBudget 的联合分配及重叠检测只在一个副本上进行
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err == nil && r.sharder != nil && !r.sharder.Owns(namespace) {
+		// 该MPA由其他副本负责; 成员变化时会重新加入 queue
+		klog.V(4).Infof("MPA(%s) belongs to another shard, skipped", key)
+		r.queue.Forget(key)
+		r.setOverlapping(key, false)
+		r.forgetBudgetAllocations(namespace)
+		// 由负责该MPA的副本导出其 metrics, 避免多个副本导出相同的时间序列
+		recommenderMetrics.DeleteMpa(namespace, name)
+		return true
+	}
+	if err := r.reconcile(ctx, key); err != nil {
+		klog.Warningf("failed to process MPA(%s), requeued: %v", key, err)
+		r.queue.AddRateLimited(key)
+		return true
+	}
+	r.queue.Forget(key)
+	if r.progress != nil {
+		r.progress.Done()
+	}
+	// 定期重新计算(qps 等 metrics 会随时间变化); MPA 已被删除时不再加入 queue
+	if err != nil {
+		return true
+	}
+	if _, err := r.mpaLister.MultidimPodAutoscalers(namespace).Get(name); err == nil {
+		r.queue.AddAfter(key, r.getResyncPeriod())
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package logic
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreListers "k8s.io/client-go/listers/core/v1"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+)
+
+const (
+	// quotaConstraintName ResourceQuota 约束名
+	quotaConstraintName = "ResourceQuota"
+)
+
+// quotaHeadroom 描述命名空间中 ResourceQuota 剩余的可用资源(包含目标负载当前占用的资源)
+// 值为 nil 的资源没有限制
+type quotaHeadroom struct {
+	// requestsCpu、limitsCpu 单位为 m
+	requestsCpu *int64
+	limitsCpu   *int64
+	// requestsMemory 单位为 byte
+	requestsMemory *int64
+	pods           *int64
+}
+
+// newQuotaConstraint 根据命名空间中的 ResourceQuota 构造推荐方案的约束
+// 剩余资源 = hard - used + 目标负载当前 pods 占用的资源(这些 pods 会被替换)
+// 只考虑没有 scope 的 ResourceQuota; 没有 ResourceQuota 时返回 nil
+func newQuotaConstraint(quotaLister coreListers.ResourceQuotaLister, namespace string, pods []*corev1.Pod) (*recommendation.PlanConstraint, error) {
+	if quotaLister == nil || len(pods) == 0 {
+		return nil, nil
+	}
+	quotas, err := quotaLister.ResourceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ResourceQuotas in namespace %s: %v", namespace, err)
+	}
+
+	// 目标负载当前占用的资源
+	var ownRequestsCpu, ownLimitsCpu, ownRequestsMemory int64
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			ownRequestsCpu += container.Resources.Requests.Cpu().MilliValue()
+			ownLimitsCpu += container.Resources.Limits.Cpu().MilliValue()
+			ownRequestsMemory += container.Resources.Requests.Memory().Value()
+		}
+	}
+	ownPods := int64(len(pods))
+
+	headroom := quotaHeadroom{}
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		for name, hard := range quota.Status.Hard {
+			used := quota.Status.Used[name]
+			switch name {
+			case corev1.ResourceCPU, corev1.ResourceRequestsCPU:
+				headroom.requestsCpu = minHeadroom(headroom.requestsCpu, hard.MilliValue()-used.MilliValue()+ownRequestsCpu)
+			case corev1.ResourceLimitsCPU:
+				headroom.limitsCpu = minHeadroom(headroom.limitsCpu, hard.MilliValue()-used.MilliValue()+ownLimitsCpu)
+			case corev1.ResourceMemory, corev1.ResourceRequestsMemory:
+				headroom.requestsMemory = minHeadroom(headroom.requestsMemory, hard.Value()-used.Value()+ownRequestsMemory)
+			case corev1.ResourcePods:
+				headroom.pods = minHeadroom(headroom.pods, hard.Value()-used.Value()+ownPods)
+			}
+		}
+	}
+	if headroom.requestsCpu == nil && headroom.limitsCpu == nil && headroom.requestsMemory == nil && headroom.pods == nil {
+		return nil, nil
+	}
+
+	// 推荐的 cpu 应用到 pod 的每个容器; limit 按当前 limit:request 的比例伸缩(未设置 limit 时与 request 相同)
+	template := pods[0]
+	containerNum := int64(len(template.Spec.Containers))
+	var templateRequestsCpu, templateLimitsCpu, templateRequestsMemory int64
+	for _, container := range template.Spec.Containers {
+		templateRequestsCpu += container.Resources.Requests.Cpu().MilliValue()
+		templateLimitsCpu += container.Resources.Limits.Cpu().MilliValue()
+		templateRequestsMemory += container.Resources.Requests.Memory().Value()
+	}
+	limitRatio := 1.0
+	if templateRequestsCpu > 0 && templateLimitsCpu > 0 {
+		limitRatio = float64(templateLimitsCpu) / float64(templateRequestsCpu)
+	}
+
+	return &recommendation.PlanConstraint{
+		Name: quotaConstraintName,
+		Allows: func(podNum, cpuMilli int64) bool {
+			requestsCpu := podNum * cpuMilli * containerNum
+			if headroom.requestsCpu != nil && requestsCpu > *headroom.requestsCpu {
+				return false
+			}
+			if headroom.limitsCpu != nil && float64(requestsCpu)*limitRatio > float64(*headroom.limitsCpu) {
+				return false
+			}
+			if headroom.requestsMemory != nil && podNum*templateRequestsMemory > *headroom.requestsMemory {
+				return false
+			}
+			if headroom.pods != nil && podNum > *headroom.pods {
+				return false
+			}
+			return true
+		},
+	}, nil
+}
+
+// minHeadroom 返回 current 与 value 中较小的值(current 为 nil 时返回 value)
+func minHeadroom(current *int64, value int64) *int64 {
+	if current != nil && *current <= value {
+		return current
+	}
+	return &value
+}
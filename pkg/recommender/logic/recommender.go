@@ -4,156 +4,654 @@ import (
 	"context"
 	"fmt"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
 	kubeClient "k8s.io/client-go/kubernetes"
 	coreListers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
 	mpaClientset "multidim-pod-autoscaler/pkg/client/clientset/versioned"
+	mpaInformers "multidim-pod-autoscaler/pkg/client/informers/externalversions"
 	mpaListers "multidim-pod-autoscaler/pkg/client/listers/autoscaling/v1"
+	recommenderMetrics "multidim-pod-autoscaler/pkg/recommender/metrics"
 	"multidim-pod-autoscaler/pkg/recommender/recommendation"
 	"multidim-pod-autoscaler/pkg/target"
+	"multidim-pod-autoscaler/pkg/util"
+	"multidim-pod-autoscaler/pkg/util/diagnostics"
 	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
 	utilPod "multidim-pod-autoscaler/pkg/util/pod"
+	"multidim-pod-autoscaler/pkg/util/policy"
 	recommendationUtil "multidim-pod-autoscaler/pkg/util/recommendation"
+	"multidim-pod-autoscaler/pkg/util/sharding"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Recommender interface {
+	// MainProcedure 运行recommender主流程, 直到 ctx 结束
+	// 监听MPA及其targetRef的变化, 并发地为每个MPA计算推荐方案
 	MainProcedure(ctx context.Context)
+	// SetResyncPeriod 修改每个MPA重新计算推荐方案的时间间隔(配置热加载时调用)
+	// 之后处理完成的MPA按新的间隔重新加入 queue
+	SetResyncPeriod(resyncPeriod time.Duration)
 }
 
+// recommender 实现 Recommender 接口
+// 每个MPA以 "namespace/name" 的形式加入 queue, 由 workers 个协程并发处理
+// 处理完成后按 resyncPeriod 重新加入 queue, 定期重新计算
+// 开启分片时只处理 sharder 分配给当前副本的MPA
 type recommender struct {
-	kubeclientset            kubeClient.Interface
-	mpaclientset             mpaClientset.Interface
-	mpaLister                mpaListers.MultidimPodAutoscalerLister
-	podLister                coreListers.PodLister
+	kubeclientset kubeClient.Interface
+	mpaclientset  mpaClientset.Interface
+	mpaLister     mpaListers.MultidimPodAutoscalerLister
+	mpaSynced     cache.InformerSynced
+	podLister     coreListers.PodLister
+	skipFilter    utilPod.SkipFilter
+	quotaLister   coreListers.ResourceQuotaLister
+	// nodeLister、allPodLister 用于计算集群中节点的剩余资源
+	nodeLister               coreListers.NodeLister
+	allPodLister             coreListers.PodLister
+	eventRecorder            record.EventRecorder
 	mpaTargetSelectorFetcher target.MpaTargetSelectorFetch
-	recommendationCalculator recommendation.Calculator
+	recommendationAlgorithms *recommendation.Registry
 	recommendationProcessor  recommendationUtil.Processor
+	policyResolver           policy.Resolver
+	budgetLister             mpaListers.MpaBudgetLister
+	queue                    workqueue.RateLimitingInterface
+	sharder                  sharding.Sharder
+	workers                  int
+	// progress 记录主流程的进度(用于 /readyz), 可以为 nil
+	progress *diagnostics.Progress
+
+	// overlappingMpas 记录与其他MPA重叠的MPA(用于 metrics)
+	overlappingLock sync.Mutex
+	overlappingMpas map[string]bool
+
+	// budgetAllocations 缓存每个 MpaBudget("namespace/name")最近一次联合分配的结果
+	budgetLock        sync.Mutex
+	budgetAllocations map[string]*budgetAllocation
+
+	resyncLock   sync.RWMutex
+	resyncPeriod time.Duration
 }
 
 func NewRecommender(
 	kubeclient kubeClient.Interface,
 	mpaclient mpaClientset.Interface,
+	factory informers.SharedInformerFactory,
 	mpaTargetSelectorFetcher target.MpaTargetSelectorFetch,
-	recommendationCalculator recommendation.Calculator,
+	recommendationAlgorithms *recommendation.Registry,
 	recommendationProcessor recommendationUtil.Processor,
-	namespace string) (Recommender, error) {
-	return &recommender{
+	namespace string,
+	sharder sharding.Sharder,
+	workers int,
+	resyncPeriod time.Duration,
+	progress *diagnostics.Progress,
+	stopCh <-chan struct{}) (Recommender, error) {
+	mpaInformerFactory := mpaInformers.NewSharedInformerFactoryWithOptions(mpaclient, time.Hour, mpaInformers.WithNamespace(namespace))
+	mpaInformer := mpaInformerFactory.Autoscaling().V1().MultidimPodAutoscalers()
+	budgetInformer := mpaInformerFactory.Autoscaling().V1().MpaBudgets()
+
+	r := &recommender{
 		kubeclientset:            kubeclient,
 		mpaclientset:             mpaclient,
-		mpaLister:                utilMpa.NewMpasLister(mpaclient, namespace, make(chan struct{})),
-		podLister:                utilPod.NewPodLister(kubeclient, namespace, make(chan struct{})),
+		mpaLister:                mpaInformer.Lister(),
+		mpaSynced:                mpaInformer.Informer().HasSynced,
+		budgetLister:             budgetInformer.Lister(),
+		podLister:                utilPod.NewPodLister(kubeclient, namespace, stopCh),
+		eventRecorder:            util.NewEventRecorder(kubeclient, "mpa-recommender"),
 		mpaTargetSelectorFetcher: mpaTargetSelectorFetcher,
 		recommendationProcessor:  recommendationProcessor,
-		recommendationCalculator: recommendationCalculator,
-	}, nil
+		recommendationAlgorithms: recommendationAlgorithms,
+		queue:                    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "mpa-recommender"),
+		sharder:                  sharder,
+		workers:                  workers,
+		resyncPeriod:             resyncPeriod,
+		progress:                 progress,
+		overlappingMpas:          make(map[string]bool),
+		budgetAllocations:        make(map[string]*budgetAllocation),
+	}
+	r.registerEventHandlers(mpaInformer.Informer(), factory)
+	r.registerBudgetEventHandlers(budgetInformer.Informer())
+	if factory != nil {
+		// ResourceQuota 用于限制推荐方案的搜索空间
+		quotaInformer := factory.Core().V1().ResourceQuotas()
+		r.quotaLister = quotaInformer.Lister()
+		// 节点及其上的 pods 用于计算节点的剩余资源
+		nodeInformer := factory.Core().V1().Nodes()
+		r.nodeLister = nodeInformer.Lister()
+		allPodInformer := factory.Core().V1().Pods()
+		r.allPodLister = allPodInformer.Lister()
+		factory.Start(stopCh)
+		if !cache.WaitForCacheSync(stopCh, quotaInformer.Informer().HasSynced) {
+			return nil, fmt.Errorf("failed to sync ResourceQuota cache during initialization")
+		}
+		if !cache.WaitForCacheSync(stopCh, nodeInformer.Informer().HasSynced, allPodInformer.Informer().HasSynced) {
+			return nil, fmt.Errorf("failed to sync node and pod cache during initialization")
+		}
+	}
+	// 默认策略变化时, 重新计算所有MPA
+	policyResolver, err := policy.NewResolver(kubeclient, mpaclient, namespace, r.enqueueAllMpas, stopCh)
+	if err != nil {
+		return nil, err
+	}
+	r.policyResolver = policyResolver
+	// 不受 MPA 管理的 pods 不计入 mpa 控制的 pods
+	skipFilter, err := utilPod.NewSkipFilter(kubeclient, namespace, stopCh)
+	if err != nil {
+		return nil, err
+	}
+	r.skipFilter = skipFilter
+	if sharder != nil {
+		// 分片成员变化时, 重新分配所有MPA
+		sharder.AddMembershipHandler(r.enqueueAllMpas)
+	}
+
+	// 非 leader 副本也保持 MPA 缓存同步
+	mpaInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, r.mpaSynced, budgetInformer.Informer().HasSynced) {
+		return nil, fmt.Errorf("failed to sync MPA cache during initialization")
+	}
+	klog.Infof("Initial MPA synced successful")
+	return r, nil
 }
 
+// MainProcedure 实现 Recommender 接口
+// 启动 workers 个协程处理 queue 中的MPA, 直到 ctx 结束; 返回前等待处理中的MPA完成
 func (r *recommender) MainProcedure(ctx context.Context) {
-	// 获取 mpas
-	mpaList, err := r.mpaLister.List(labels.Everything())
+	klog.Infof("starting MPA recommender with %d workers", r.workers)
+	var workers sync.WaitGroup
+	for i := 0; i < r.workers; i += 1 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			r.runWorker(ctx)
+		}()
+	}
+	defer workers.Wait()
+	defer r.queue.ShutDown()
+	if r.progress != nil {
+		r.progress.Begin()
+		defer r.progress.End()
+		// queue 中没有待处理的MPA时, 同样认为主流程在正常运行
+		// 间隔可能被热加载修改, 每次重新获取
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(r.getResyncPeriod()):
+				}
+				if r.queue.Len() == 0 {
+					r.progress.Done()
+				}
+			}
+		}()
+	}
+	<-ctx.Done()
+	klog.Infof("stopping MPA recommender: %v", ctx.Err())
+}
+
+// SetResyncPeriod 实现 Recommender 接口
+func (r *recommender) SetResyncPeriod(resyncPeriod time.Duration) {
+	r.resyncLock.Lock()
+	defer r.resyncLock.Unlock()
+	r.resyncPeriod = resyncPeriod
+}
+
+func (r *recommender) getResyncPeriod() time.Duration {
+	r.resyncLock.RLock()
+	defer r.resyncLock.RUnlock()
+	return r.resyncPeriod
+}
+
+// reconcile 为 key("namespace/name") 指定的MPA计算并更新推荐方案
+// 返回 error 时, 该MPA会被限速后重新加入 queue
+func (r *recommender) reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Fatalf("faied to get MPA Object list: %v", err)
-	}
-	// 获取每个mpa对应的pod label selector
-	mpas := make([]*utilMpa.MpaWithSelector, 0)
-	for _, mpa := range mpaList {
-		updateMode := utilMpa.GetMpaUpdateMode(mpa)
-		if updateMode != mpaTypes.UpdateModeAuto {
-			klog.V(3).Infof("skipped MPA Object %v/%v(its update mode was set to off(default is Auto))", mpa.Namespace, mpa.Name)
-			continue
+		klog.Errorf("invalid MPA key %q: %v", key, err)
+		return nil
+	}
+	mpa, err := r.mpaLister.MultidimPodAutoscalers(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// MPA 已被删除
+		r.setOverlapping(key, false)
+		recommenderMetrics.DeleteMpa(namespace, name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get MPA Object %s: %v", key, err)
+	}
+	// 合并默认策略后生效的配置, 只用于计算推荐方案, 不写回 MPA 的 spec
+	effective, effectivePolicy := r.policyResolver.Resolve(mpa)
+	if utilMpa.GetMpaUpdateMode(effective) != mpaTypes.UpdateModeAuto {
+		klog.V(3).Infof("skipped MPA Object %v/%v(its update mode was set to off(default is Auto))", mpa.Namespace, mpa.Name)
+		r.updateEffectivePolicy(ctx, mpa, effectivePolicy)
+		// 不再为该 mpa 计算推荐方案, 删除它的 metrics
+		recommenderMetrics.DeleteMpa(namespace, name)
+		return nil
+	}
+
+	// 获取同一命名空间下的所有mpa及其对应的pod label selector(用于判断pod实际所属的mpa)
+	mpaList, err := r.mpaLister.MultidimPodAutoscalers(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to get MPA Object list in namespace %s: %v", namespace, err)
+	}
+	var current *utilMpa.MpaWithSelector
+	mpas := make([]*utilMpa.MpaWithSelector, 0, len(mpaList))
+	for _, item := range mpaList {
+		if item.Name != name {
+			if itemEffective, _ := r.policyResolver.Resolve(item); utilMpa.GetMpaUpdateMode(itemEffective) != mpaTypes.UpdateModeAuto {
+				continue
+			}
 		}
-		selector, err := r.mpaTargetSelectorFetcher.Fetch(mpa)
+		selector, err := r.mpaTargetSelectorFetcher.Fetch(item)
 		if err != nil {
-			klog.V(3).Infof("skipped MPA Object %v/%v(connot fetch the target reference selector for it)", mpa.Namespace, mpa.Name)
+			if item.Name == name {
+				klog.V(3).Infof("skipped MPA Object %v/%v(connot fetch the target reference selector for it): %v", item.Namespace, item.Name, err)
+				return nil
+			}
 			continue
 		}
-		mpas = append(mpas,
-			&utilMpa.MpaWithSelector{
-				Mpa:      mpa,
-				Selector: selector,
-			},
-		)
+		mpaWithSelector := &utilMpa.MpaWithSelector{Mpa: item, Selector: selector}
+		if item.Name == name {
+			current = mpaWithSelector
+		}
+		mpas = append(mpas, mpaWithSelector)
 	}
-	if len(mpas) <= 0 {
-		klog.Warningf("no MPA Obejects to process the update")
+	if current == nil {
+		return nil
 	}
-	// 获取pod列表
-	podList, err := r.podLister.List(labels.Everything())
+	current.Mpa = r.updateEffectivePolicy(ctx, current.Mpa, effectivePolicy)
+
+	// 获取被该mpa的 label selector 匹配到的pod
+	podList, err := r.podLister.Pods(namespace).List(current.Selector)
 	if err != nil {
-		klog.Errorf("failed to get pods list: %v", err)
-		return
+		return fmt.Errorf("failed to get pods list of MPA(%s): %v", key, err)
 	}
-	// 过滤杯驱逐(待删除)的pod
-	livingPods := filterDeletedPods(podList)
-	// 匹配每个mpa控制的pods
-	mpaControlledPods := make(map[*utilMpa.MpaWithSelector][]*corev1.Pod)
+	// 过滤被驱逐(待删除)的pod, 以及不受 MPA 管理的pod
+	livingPods := utilPod.FilterSkippedPods(filterDeletedPods(podList), r.skipFilter)
+	// 匹配该mpa控制的pods
+	// 同时记录被 label selector 匹配到、但属主链中没有 targetRef 的pods
+	// 以及与其他mpa重叠(匹配到相同pod)的情况
+	controlledPods := make([]*corev1.Pod, 0)
+	mismatchedPods := make([]*corev1.Pod, 0)
+	var overlap *mpaOverlap
 	for _, pod := range livingPods {
-		controllingMpa := utilMpa.GetControllingMpaForPod(pod, mpas)
-		if controllingMpa != nil {
-			mpaControlledPods[controllingMpa] = append(mpaControlledPods[controllingMpa], pod)
+		match := utilMpa.MatchPodToMpas(pod, mpas, r.mpaTargetSelectorFetcher)
+		if match.Controlling == current {
+			controlledPods = append(controlledPods, pod)
 		}
-	}
-
-	for mpaWithSelector, pods := range mpaControlledPods {
-		if len(pods) <= 0 {
-			klog.Infof("MPA(%s/%s) has not controlled any pods", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name)
-			continue
+		for _, mismatched := range match.Mismatched {
+			if mismatched == current {
+				mismatchedPods = append(mismatchedPods, pod)
+			}
 		}
-		// 计算推荐方案
-		recommendationRes, action, err := r.recommendationCalculator.Calculate(mpaWithSelector, pods)
-		if err != nil {
-			klog.Warningf("failed calculate recommendation for MPA(%s/%s), skipped: %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err.Error())
-			r.updateMpaCondition(&mpaTypes.MultidimPodAutoscalerCondition{
-				Type:               mpaTypes.RecommendationSkipped,
-				Status:             corev1.ConditionTrue,
-				LastTransitionTime: metav1.Now(),
-				Reason:             "CalculateRecommendationFailed",
-			}, mpaWithSelector.Mpa)
-			continue
+		if len(match.Overlapped) > 0 {
+			involved := append([]*utilMpa.MpaWithSelector{match.Controlling}, match.Overlapped...)
+			if !containsMpa(involved, current) {
+				continue
+			}
+			if overlap == nil {
+				overlap = &mpaOverlap{winner: match.Controlling, others: map[string]bool{}}
+			}
+			for _, other := range involved {
+				if other != current {
+					overlap.others[other.Mpa.Name] = true
+				}
+			}
 		}
-		klog.V(4).Infof("calculate recommendation finished(action: %s, value: %v)", action, *recommendationRes)
+	}
+	r.setOverlapping(key, overlap != nil)
+	// 上报pod匹配相关的状态条件
+	r.updateMatchingConditions(ctx, current, mismatchedPods, overlap)
+
+	if len(controlledPods) <= 0 {
+		klog.Infof("MPA(%s) has not controlled any pods", key)
+		recommenderMetrics.OnRecommendationSkipped(namespace, name, recommenderMetrics.NoControlledPods)
+		return nil
+	}
+	// 推荐方案需要满足的约束, 包括选中该mpa的预算联合分配的份额
+	constraints := r.planConstraints(current, &effective.Spec, controlledPods)
+	constraints = append(constraints, r.budgetConstraints(ctx, current, mpas)...)
+	r.recommend(ctx, current, &effective.Spec, controlledPods, constraints)
+	return nil
+}
+
+// planConstraints 返回mpa的推荐方案需要满足的约束(副本数范围、ResourceQuota、节点容量)
+// spec 为合并默认策略后生效的配置
+func (r *recommender) planConstraints(mpaWithSelector *utilMpa.MpaWithSelector, spec *mpaTypes.MultidimPodAutoscalerSpec, pods []*corev1.Pod) []recommendation.PlanConstraint {
+	constraints := make([]recommendation.PlanConstraint, 0)
+	if replicasConstraint := newReplicasConstraint(spec.Replicas); replicasConstraint != nil {
+		constraints = append(constraints, *replicasConstraint)
+	}
+	quotaConstraint, err := newQuotaConstraint(r.quotaLister, mpaWithSelector.Mpa.Namespace, pods)
+	if err != nil {
+		klog.Warningf("failed to get ResourceQuota constraint of MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
+	} else if quotaConstraint != nil {
+		constraints = append(constraints, *quotaConstraint)
+	}
+	capacityConstraint, err := newCapacityConstraint(r.nodeLister, r.allPodLister, pods)
+	if err != nil {
+		klog.Warningf("failed to get node capacity constraint of MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
+	} else if capacityConstraint != nil {
+		constraints = append(constraints, *capacityConstraint)
+	}
+	return constraints
+}
 
-		var adjustRecommendation *mpaTypes.RecommendedResources
-		newCondition := mpaTypes.MultidimPodAutoscalerCondition{
+// recommend 为mpa计算推荐方案, 并更新mpa的状态
+// spec 为合并默认策略后生效的配置, constraints 为推荐方案需要满足的约束
+func (r *recommender) recommend(
+	ctx context.Context,
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	spec *mpaTypes.MultidimPodAutoscalerSpec,
+	pods []*corev1.Pod,
+	constraints []recommendation.PlanConstraint,
+) {
+	// 使用生效的配置计算推荐方案
+	mpaCopy := mpaWithSelector.Mpa.DeepCopy()
+	mpaCopy.Spec = *spec.DeepCopy()
+	calculateTarget := &utilMpa.MpaWithSelector{Mpa: mpaCopy, Selector: mpaWithSelector.Selector}
+
+	// 选择 mpa 指定的推荐算法
+	algorithm, err := r.recommendationAlgorithms.Get(calculateTarget.Mpa)
+	if err != nil {
+		klog.Warningf("failed to get recommendation algorithm for MPA(%s/%s), skipped: %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
+		recommenderMetrics.OnRecommendationSkipped(mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, recommenderMetrics.UnknownAlgorithm)
+		r.updateMpaCondition(ctx, &mpaTypes.MultidimPodAutoscalerCondition{
+			Type:               mpaTypes.RecommendationSkipped,
 			Status:             corev1.ConditionTrue,
 			LastTransitionTime: metav1.Now(),
-		}
-		if action == recommendation.ApplyRecommendation {
-			// 调整推荐方案
-			adjustRecommendation, _, err =
-				r.recommendationProcessor.AdjustRecommendation(recommendationRes, mpaWithSelector.Mpa.Spec.ResourcePolicy, pods[0])
-			if err != nil {
-				klog.Errorf("failed to adjust the recommendation resources of MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
-				newCondition.Type = mpaTypes.RecommendationSkipped
-				newCondition.Reason = "AdjustRecommendationFailed"
-				r.updateMpaCondition(&newCondition, mpaWithSelector.Mpa)
-				continue
-			}
-			newCondition.Type = mpaTypes.RecommendationProvided
-			newCondition.Reason = "Recommendation Provided"
-		} else if action == recommendation.SkipRecommendation {
-			newCondition.Type = mpaTypes.RecommendationSkipped
-			newCondition.Reason = "Recommendation Skipped"
-		} else {
+			Reason:             "UnknownAlgorithm",
+			Message:            err.Error(),
+		}, mpaWithSelector.Mpa)
+		return
+	}
+	if status := mpaWithSelector.Mpa.Status.Algorithm; status != nil &&
+		(status.Name != algorithm.Name() || status.Version != algorithm.Version()) {
+		// 算法(或版本)改变后, 旧方案与新算法的方案不具有可比性, 直接应用新算法的方案
+		klog.V(2).Infof("recommendation algorithm of MPA(%s/%s) changed from %s(%s) to %s(%s)", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name,
+			status.Name, status.Version, algorithm.Name(), algorithm.Version())
+		calculateTarget.Mpa.Status.RecommendationResources = nil
+	}
+
+	// 计算推荐方案
+	recommendationRes, action, decision, err := algorithm.Calculate(calculateTarget, pods, constraints...)
+	if err != nil {
+		klog.Warningf("failed calculate recommendation for MPA(%s/%s), skipped: %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err.Error())
+		recommenderMetrics.OnRecommendationSkipped(mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, recommenderMetrics.CalculateFailed)
+		r.updateMpaCondition(ctx, &mpaTypes.MultidimPodAutoscalerCondition{
+			Type:               mpaTypes.RecommendationSkipped,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "CalculateRecommendationFailed",
+			Message:            err.Error(),
+		}, mpaWithSelector.Mpa)
+		return
+	}
+	klog.V(4).Infof("calculate recommendation finished(action: %s, value: %v)", action, *recommendationRes)
+
+	var adjustRecommendation *mpaTypes.RecommendedResources
+	newCondition := mpaTypes.MultidimPodAutoscalerCondition{
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+	}
+	if action == recommendation.ApplyRecommendation {
+		// 调整推荐方案
+		adjustRecommendation, _, err =
+			r.recommendationProcessor.AdjustRecommendation(recommendationRes, spec.ResourcePolicy, pods[0])
+		if err != nil {
+			klog.Errorf("failed to adjust the recommendation resources of MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
+			recommenderMetrics.OnRecommendationSkipped(mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, recommenderMetrics.AdjustFailed)
 			newCondition.Type = mpaTypes.RecommendationSkipped
-			newCondition.Reason = "Recommendation Unknown"
+			newCondition.Reason = "AdjustRecommendationFailed"
+			r.updateMpaCondition(ctx, &newCondition, mpaWithSelector.Mpa)
+			return
+		}
+		newCondition.Type = mpaTypes.RecommendationProvided
+		newCondition.Reason = "Recommendation Provided"
+	} else if action == recommendation.SkipRecommendation {
+		newCondition.Type = mpaTypes.RecommendationSkipped
+		newCondition.Reason = "Recommendation Skipped"
+	} else {
+		newCondition.Type = mpaTypes.RecommendationSkipped
+		newCondition.Reason = "Recommendation Unknown"
+	}
+
+	mpa := mpaWithSelector.Mpa
+	if action == recommendation.ApplyRecommendation || decision != nil {
+		mpa = mpa.DeepCopy()
+	}
+	if action == recommendation.ApplyRecommendation {
+		// 记录推荐方案是否被约束限制, 以及计算方案使用的算法
+		setCappingConditions(&mpa.Status, recommendationRes, constraints)
+		mpa.Status.Algorithm = &mpaTypes.RecommendationAlgorithmStatus{
+			Name:    algorithm.Name(),
+			Version: algorithm.Version(),
+		}
+	}
+	// 记录本次计算的决策
+	var previousDecision *mpaTypes.RecommendationDecision
+	if decision != nil {
+		previousDecision = mpa.Status.Decision
+		decision.Time = metav1.Now()
+		mpa.Status.Decision = decision
+	}
+	// 如果必要，更新推荐方案
+	_, err = r.updateRecommendationIfBetter(ctx, adjustRecommendation, newCondition, mpa)
+	if err != nil {
+		klog.Errorf("failed to update the recommendation resources for MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
+		recommenderMetrics.OnRecommendationSkipped(mpa.Namespace, mpa.Name, recommenderMetrics.UpdateStatusFailed)
+	} else {
+		klog.V(4).Infof("Successful recommendation for MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, adjustRecommendation)
+		if decision != nil {
+			r.recordDecisionEvent(mpa, previousDecision, decision)
+		}
+		if action == recommendation.SkipRecommendation {
+			recommenderMetrics.OnRecommendationSkipped(mpa.Namespace, mpa.Name, recommenderMetrics.KeepCurrent)
 		}
+		current := adjustRecommendation
+		if current == nil {
+			current = mpa.Status.RecommendationResources
+		}
+		observeRecommendationMetrics(mpa, current, decision)
+	}
+}
 
-		// 如果必要，更新推荐方案
-		_, err = r.updateRecommendationIfBetter(adjustRecommendation, newCondition, mpaWithSelector.Mpa)
-		if err != nil {
-			klog.Errorf("failed to update the recommendation resources for MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, err)
+// observeRecommendationMetrics 更新 MPA 当前推荐方案及最近一次决策相关的 metrics
+func observeRecommendationMetrics(mpa *mpaTypes.MultidimPodAutoscaler, current *mpaTypes.RecommendedResources, decision *mpaTypes.RecommendationDecision) {
+	recommenderMetrics.ObserveRecommendation(mpa.Namespace, mpa.Name, current)
+	recommenderMetrics.ObserveDecision(mpa.Namespace, mpa.Name, decision)
+	if current == nil || decision == nil || len(current.ContainerRecommendations) == 0 {
+		return
+	}
+	qps, err := strconv.ParseFloat(decision.ObservedQps, 64)
+	if err != nil {
+		return
+	}
+	cpu, existed := current.ContainerRecommendations[0].Target[corev1.ResourceCPU]
+	if !existed {
+		return
+	}
+	capacity := recommendation.PodCapacity(cpu.MilliValue())
+	recommenderMetrics.ObservePredictedResponseTime(mpa.Namespace, mpa.Name,
+		recommendation.ResponseTime(int64(current.TargetPodNum), float64(capacity), qps))
+}
+
+// recordDecisionEvent 为推荐的决策产生 Event
+// 应用新方案时总是产生; 保持当前方案时只在上一次决策不是保持时产生, 避免每个推荐周期产生一个 Event
+func (r *recommender) recordDecisionEvent(mpa *mpaTypes.MultidimPodAutoscaler, previous, decision *mpaTypes.RecommendationDecision) {
+	message := fmt.Sprintf("observed qps %s", decision.ObservedQps)
+	if chosen := decision.Chosen; chosen != nil {
+		message += fmt.Sprintf(", best plan %d pods × %s", chosen.PodNum, chosen.Cpu.String())
+		if chosen.Score != "" {
+			message += fmt.Sprintf(" (score %s)", chosen.Score)
+		}
+	}
+	message += ": " + decision.Reason
+
+	if decision.Action == mpaTypes.RecommendationDecisionApply {
+		r.eventRecorder.Event(mpa, corev1.EventTypeNormal, "RecommendationApplied", message)
+	} else if previous == nil || previous.Action != mpaTypes.RecommendationDecisionKeep {
+		r.eventRecorder.Event(mpa, corev1.EventTypeNormal, "RecommendationKept", message)
+	}
+}
+
+// setCappingConditions 根据被约束过滤的最优方案(UncappedTargetPodNum / UncappedTarget)设置 QuotaLimited、CapacityLimited、BudgetLimited 状态条件
+func setCappingConditions(status *mpaTypes.MultidimPodAutoscalerStatus, res *mpaTypes.RecommendedResources, constraints []recommendation.PlanConstraint) {
+	if res == nil || len(res.ContainerRecommendations) == 0 {
+		return
+	}
+	target := res.ContainerRecommendations[0].Target[corev1.ResourceCPU]
+	uncapped, existed := res.ContainerRecommendations[0].UncappedTarget[corev1.ResourceCPU]
+	if !existed {
+		uncapped = target
+	}
+	rejected := map[string]*recommendation.PlanConstraint{}
+	for _, constraint := range recommendation.RejectedBy(int64(res.UncappedTargetPodNum), uncapped.MilliValue(), constraints) {
+		constraint := constraint
+		rejected[constraint.Name] = &constraint
+	}
+
+	if rejected[quotaConstraintName] != nil {
+		message := fmt.Sprintf("the best plan %d pods × %s cpu exceeds the ResourceQuota headroom, capped to %d pods × %s cpu",
+			res.UncappedTargetPodNum, uncapped.String(), res.TargetPodNum, target.String())
+		utilMpa.SetMpaCondition(status, mpaTypes.QuotaLimited, corev1.ConditionTrue, "ExceedsQuotaHeadroom", message)
+	} else if utilMpa.GetMpaCondition(status, mpaTypes.QuotaLimited) != nil {
+		utilMpa.SetMpaCondition(status, mpaTypes.QuotaLimited, corev1.ConditionFalse, "WithinQuotaHeadroom", "")
+	}
+
+	if constraint := rejected[capacityConstraintName]; constraint != nil {
+		message := fmt.Sprintf("the best plan %d pods × %s cpu does not fit in the free node capacity (%s), capped to %d pods × %s cpu",
+			res.UncappedTargetPodNum, uncapped.String(), constraint.Description, res.TargetPodNum, target.String())
+		utilMpa.SetMpaCondition(status, mpaTypes.CapacityLimited, corev1.ConditionTrue, "ExceedsNodeCapacity", message)
+	} else if utilMpa.GetMpaCondition(status, mpaTypes.CapacityLimited) != nil {
+		utilMpa.SetMpaCondition(status, mpaTypes.CapacityLimited, corev1.ConditionFalse, "WithinNodeCapacity", "")
+	}
+
+	if constraint := rejected[budgetConstraintName]; constraint != nil {
+		message := fmt.Sprintf("the best plan %d pods × %s cpu exceeds the share of the budget (%s), capped to %d pods × %s cpu",
+			res.UncappedTargetPodNum, uncapped.String(), constraint.Description, res.TargetPodNum, target.String())
+		utilMpa.SetMpaCondition(status, mpaTypes.BudgetLimited, corev1.ConditionTrue, "ExceedsBudgetShare", message)
+	} else if utilMpa.GetMpaCondition(status, mpaTypes.BudgetLimited) != nil {
+		utilMpa.SetMpaCondition(status, mpaTypes.BudgetLimited, corev1.ConditionFalse, "WithinBudget", "")
+	}
+}
+
+// setOverlapping 记录指定mpa是否与其他mpa重叠, 并更新对应的 metrics
+func (r *recommender) setOverlapping(key string, overlapping bool) {
+	r.overlappingLock.Lock()
+	defer r.overlappingLock.Unlock()
+	if overlapping {
+		r.overlappingMpas[key] = true
+	} else {
+		delete(r.overlappingMpas, key)
+	}
+	recommenderMetrics.ObserveOverlappingMpas(len(r.overlappingMpas))
+}
+
+// containsMpa 判断 mpas 中是否包含 mpa
+func containsMpa(mpas []*utilMpa.MpaWithSelector, mpa *utilMpa.MpaWithSelector) bool {
+	for _, item := range mpas {
+		if item == mpa {
+			return true
+		}
+	}
+	return false
+}
+
+// mpaOverlap 描述了一个mpa与其他mpa的重叠情况
+// winner 为重叠的pods实际所属的mpa; others 为重叠的其他mpa的名字集合
+type mpaOverlap struct {
+	winner *utilMpa.MpaWithSelector
+	others map[string]bool
+}
+
+// updateMatchingConditions 更新mpa匹配pods相关的状态条件(PodOwnerMismatch、OverlappingMpa)
+// 更新成功后 mpaWithSelector.Mpa 被替换为更新后的对象
+func (r *recommender) updateMatchingConditions(
+	ctx context.Context,
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	mismatchedPods []*corev1.Pod,
+	overlap *mpaOverlap,
+) {
+	mpa := mpaWithSelector.Mpa
+	mpaCopy := mpa.DeepCopy()
+
+	var changed, becameOverlapping bool
+	if len(mismatchedPods) > 0 {
+		message := fmt.Sprintf("%d pod(s) selected by labels are not controlled by %s %s, ignored (e.g. %s)",
+			len(mismatchedPods), mpa.Spec.TargetRef.Kind, mpa.Spec.TargetRef.Name, mismatchedPods[0].Name)
+		changed = utilMpa.SetMpaCondition(&mpaCopy.Status, mpaTypes.PodOwnerMismatch, corev1.ConditionTrue, "PodsNotControlledByTarget", message) || changed
+	} else if utilMpa.GetMpaCondition(&mpaCopy.Status, mpaTypes.PodOwnerMismatch) != nil {
+		changed = utilMpa.SetMpaCondition(&mpaCopy.Status, mpaTypes.PodOwnerMismatch, corev1.ConditionFalse, "AllPodsControlledByTarget", "") || changed
+	}
+
+	var overlapMessage string
+	if overlap != nil {
+		others := make([]string, 0, len(overlap.others))
+		for name := range overlap.others {
+			others = append(others, name)
+		}
+		sort.Strings(others)
+		if overlap.winner == mpaWithSelector {
+			overlapMessage = fmt.Sprintf("overlaps with MPA(s) %s; this MPA takes precedence", strings.Join(others, ", "))
 		} else {
-			klog.V(4).Infof("Successful recommendation for MPA(%s/%s): %v", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, adjustRecommendation)
+			overlapMessage = fmt.Sprintf("overlaps with MPA(s) %s; MPA %s takes precedence and the recommendation of this MPA is not applied to the shared pods",
+				strings.Join(others, ", "), overlap.winner.Mpa.Name)
 		}
+		existing := utilMpa.GetMpaCondition(&mpaCopy.Status, mpaTypes.OverlappingMpa)
+		becameOverlapping = existing == nil || existing.Status != corev1.ConditionTrue
+		changed = utilMpa.SetMpaCondition(&mpaCopy.Status, mpaTypes.OverlappingMpa, corev1.ConditionTrue, "PodsSelectedByMultipleMpas", overlapMessage) || changed
+	} else if utilMpa.GetMpaCondition(&mpaCopy.Status, mpaTypes.OverlappingMpa) != nil {
+		changed = utilMpa.SetMpaCondition(&mpaCopy.Status, mpaTypes.OverlappingMpa, corev1.ConditionFalse, "NoOverlappingMpa", "") || changed
+	}
+
+	if becameOverlapping {
+		r.eventRecorder.Event(mpa, corev1.EventTypeWarning, "OverlappingMpa", overlapMessage)
+	}
+	if !changed {
+		return
 	}
+
+	updated, err := r.updateMpa(ctx, mpaCopy)
+	if err != nil {
+		klog.Warningf("failed to update matching conditions of MPA(%s/%s): %v", mpa.Namespace, mpa.Name, err)
+		return
+	}
+	mpaWithSelector.Mpa = updated
+}
+
+// updateEffectivePolicy 将合并默认策略后生效的配置记录到 mpa 的 status.effectivePolicy
+// 返回更新后的mpa对象; 没有变化或更新失败时返回 mpa 本身
+func (r *recommender) updateEffectivePolicy(ctx context.Context, mpa *mpaTypes.MultidimPodAutoscaler, effective *mpaTypes.EffectivePolicy) *mpaTypes.MultidimPodAutoscaler {
+	if equality.Semantic.DeepEqual(mpa.Status.EffectivePolicy, effective) {
+		return mpa
+	}
+	mpaCopy := mpa.DeepCopy()
+	mpaCopy.Status.EffectivePolicy = effective
+	updated, err := r.updateMpa(ctx, mpaCopy)
+	if err != nil {
+		klog.Warningf("failed to update effective policy of MPA(%s/%s): %v", mpa.Namespace, mpa.Name, err)
+		return mpa
+	}
+	return updated
 }
 
 // updateMpaCondition 更新mpa对象的状态条件，用于判断是否需要应用推荐方案
 func (r *recommender) updateMpaCondition(
+	ctx context.Context,
 	newStatusCondition *mpaTypes.MultidimPodAutoscalerCondition,
 	mpa *mpaTypes.MultidimPodAutoscaler,
 ) (bool, error) {
@@ -161,14 +659,18 @@ func (r *recommender) updateMpaCondition(
 		return false, fmt.Errorf("no aviliable status condition to update the MPA(%s/%s)'s status", mpa.Namespace, mpa.Name)
 	}
 	mpaCopy := mpa.DeepCopy()
-	mpaCopy.Status.Conditions = append(mpaCopy.Status.Conditions, *newStatusCondition)
-	_, err :=
-		r.mpaclientset.AutoscalingV1().MultidimPodAutoscalers(mpa.Namespace).Update(context.TODO(), mpaCopy, metav1.UpdateOptions{})
+	// 每种状态条件只保留一个(每轮 resync 都会调用), 没有变化时不更新
+	if !utilMpa.SetMpaCondition(&mpaCopy.Status, newStatusCondition.Type, newStatusCondition.Status,
+		newStatusCondition.Reason, newStatusCondition.Message) {
+		return false, nil
+	}
+	_, err := r.updateMpa(ctx, mpaCopy)
 	return true, err
 }
 
 // updateRecommendationIfBetter 更新mpa对象的资源推荐方案
 func (r *recommender) updateRecommendationIfBetter(
+	ctx context.Context,
 	newRecommendation *mpaTypes.RecommendedResources,
 	newStatusCondition mpaTypes.MultidimPodAutoscalerCondition,
 	mpa *mpaTypes.MultidimPodAutoscaler) (bool, error) {
@@ -180,13 +682,45 @@ func (r *recommender) updateRecommendationIfBetter(
 	} else {
 		klog.Warningf("no aviliable recommendation to update the MPA(%s/%s)'s status", mpa.Namespace, mpa.Name)
 	}
-	mpaCopy.Status.Conditions = append(mpaCopy.Status.Conditions, newStatusCondition)
+	utilMpa.SetMpaCondition(&mpaCopy.Status, newStatusCondition.Type, newStatusCondition.Status,
+		newStatusCondition.Reason, newStatusCondition.Message)
+	// 提供了新的推荐方案后, 之前跳过推荐的状态条件不再成立
+	if newStatusCondition.Type == mpaTypes.RecommendationProvided &&
+		utilMpa.GetMpaCondition(&mpaCopy.Status, mpaTypes.RecommendationSkipped) != nil {
+		utilMpa.SetMpaCondition(&mpaCopy.Status, mpaTypes.RecommendationSkipped, corev1.ConditionFalse, newStatusCondition.Reason, "")
+	}
 
-	_, err =
-		r.mpaclientset.AutoscalingV1().MultidimPodAutoscalers(mpa.Namespace).Update(context.TODO(), mpaCopy, metav1.UpdateOptions{})
+	_, err = r.updateMpa(ctx, mpaCopy)
 	return true, err
 }
 
+// updateMpa 更新mpa对象, 出现暂时性错误时重试
+func (r *recommender) updateMpa(ctx context.Context, mpa *mpaTypes.MultidimPodAutoscaler) (*mpaTypes.MultidimPodAutoscaler, error) {
+	var updated *mpaTypes.MultidimPodAutoscaler
+	err := util.RetryOnTransientError(ctx, func() error {
+		var err error
+		updated, err = r.mpaclientset.AutoscalingV1().MultidimPodAutoscalers(mpa.Namespace).Update(ctx, mpa, metav1.UpdateOptions{})
+		return err
+	})
+	return updated, err
+}
+
+// controlledPods 返回 mpaWithSelector 实际控制的pods(不包括被驱逐及不受 MPA 管理的pods)
+// mpas 为同一命名空间内参与推荐的所有mpa, 用于判断pod实际所属的mpa
+func (r *recommender) controlledPods(mpaWithSelector *utilMpa.MpaWithSelector, mpas []*utilMpa.MpaWithSelector) ([]*corev1.Pod, error) {
+	podList, err := r.podLister.Pods(mpaWithSelector.Mpa.Namespace).List(mpaWithSelector.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0)
+	for _, pod := range utilPod.FilterSkippedPods(filterDeletedPods(podList), r.skipFilter) {
+		if utilMpa.MatchPodToMpas(pod, mpas, r.mpaTargetSelectorFetcher).Controlling == mpaWithSelector {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
 // filterDeletedPods 过滤已被删除的pods
 func filterDeletedPods(pods []*corev1.Pod) []*corev1.Pod {
 	result := make([]*corev1.Pod, 0)
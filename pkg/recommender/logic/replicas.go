@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"fmt"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/recommendation"
+)
+
+const (
+	// replicasConstraintName 副本数范围约束名
+	replicasConstraintName = "ReplicaBounds"
+)
+
+// newReplicasConstraint 根据 spec.replicas(合并默认策略后)构造推荐方案的约束
+// 未指定副本数范围时返回 nil
+func newReplicasConstraint(bounds *mpaTypes.ReplicaBounds) *recommendation.PlanConstraint {
+	if bounds == nil || (bounds.MinReplicas == nil && bounds.MaxReplicas == nil) {
+		return nil
+	}
+	min, max := int64(0), int64(-1)
+	description := "replicas"
+	if bounds.MinReplicas != nil {
+		min = int64(*bounds.MinReplicas)
+		description = fmt.Sprintf("%d <= %s", min, description)
+	}
+	if bounds.MaxReplicas != nil {
+		max = int64(*bounds.MaxReplicas)
+		description = fmt.Sprintf("%s <= %d", description, max)
+	}
+	return &recommendation.PlanConstraint{
+		Name:        replicasConstraintName,
+		Description: description,
+		Allows: func(podNum, cpuMilli int64) bool {
+			return podNum >= min && (max < 0 || podNum <= max)
+		},
+	}
+}
@@ -15,10 +15,13 @@ import (
 	recommenderUtil "multidim-pod-autoscaler/pkg/recommender/util"
 	"multidim-pod-autoscaler/pkg/target"
 	"multidim-pod-autoscaler/pkg/util"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+	"multidim-pod-autoscaler/pkg/util/diagnostics"
+	"multidim-pod-autoscaler/pkg/util/leaderelection"
 	"multidim-pod-autoscaler/pkg/util/limitrange"
-	"multidim-pod-autoscaler/pkg/util/metrics"
 	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
 	utilRecommendation "multidim-pod-autoscaler/pkg/util/recommendation"
+	"multidim-pod-autoscaler/pkg/util/sharding"
 	"time"
 )
 
@@ -29,14 +32,26 @@ const (
 
 var (
 	recommenderInterval = flag.Duration("recommender-interval", 1*time.Minute,
-		"recommender的主流程运行频率")
+		"每个MPA重新计算推荐方案的时间间隔")
+	defaultAlgorithm = flag.String("default-algorithm", recommendation.MMCAlgorithm,
+		"MPA 未指定 spec.algorithm 时使用的推荐算法(mmc、target-utilization、percentile-histogram、grpc)")
+	recommenderWorkers = flag.Int("recommender-workers", 4, "并发处理MPA的worker数量")
 
-	metricsAddress = flag.String("address", ":8946", "Prometheus metrics对外暴露的地址")
+	pluginAddress = flag.String("recommendation-plugin-address", "",
+		"进程外推荐算法插件的 gRPC 地址(如: localhost:9090), 指定后 MPA 可使用 grpc 算法")
+	pluginTimeout = flag.Duration("recommendation-plugin-timeout", 5*time.Second,
+		"调用推荐算法插件的超时时间, 超时后使用内置的 mmc 算法")
+
+	metricsAddress = flag.String("address", ":8946", "诊断 server(Prometheus metrics、/healthz、/readyz)对外暴露的地址")
+	profiling      = flag.Bool("profiling", false, "在诊断 server 上暴露 /debug/pprof")
 	kubeconfig     = flag.String("kubeconfig", "", "Path to kubeconfig. 使用out-cluster配置时指定")
 	kubeApiQps     = flag.Float64("kube-api-qps", 5.0, "访问API-Server的 QPS 限制")
 	kubeApiBurst   = flag.Int("kube-api-burst", 10, "访问API-Server的 QPS 峰值限制")
 
 	mpaObjectNamespace = flag.String("mpa-object-namespace", corev1.NamespaceAll, "搜索MPA Objects的命名空间")
+
+	leaderElection = leaderelection.RegisterFlags(flag.CommandLine, "mpa-recommender")
+	shardingConfig = sharding.RegisterFlags(flag.CommandLine)
 )
 
 func main() {
@@ -44,39 +59,128 @@ func main() {
 	cliFlag.InitFlags()
 	klog.V(1).Infof("Multidim Pod Autoscaler %s Recommender", utilMpa.MultidimPodAutoscalerVersion)
 
-	metrics.InitializeMetrics(*metricsAddress)
+	// 配置文件(可选)中的值被命令行中显式指定的参数覆盖
+	configData, err := utilConfig.ReadFile(*configFile)
+	if err != nil {
+		klog.Fatalf("failed to load configuration: %v", err)
+	}
+	componentConfig, model, err := loadConfiguration(configData)
+	if err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
+	if err := recommendation.SetModel(model); err != nil {
+		klog.Fatalf("invalid cost model: %v", err)
+	}
+
+	// 收到 SIGTERM 时结束根 context, 停止主流程及 informer
+	ctx := util.SetupSignalContext()
+	stopCh := ctx.Done()
+
+	// 诊断 server: informer 缓存同步完成、主流程(成为 leader 后)持续运行时 ready
+	diagnosticsServer := diagnostics.NewServer(componentConfig.Diagnostics.Address, componentConfig.Diagnostics.EnableProfiling)
+	informersSynced := diagnostics.NewCondition("informer caches have not synced")
+	progress := diagnostics.NewProgress(componentConfig.RecommenderInterval.Duration)
+	diagnosticsServer.AddReadyCheck("informers", informersSynced.Check)
+	diagnosticsServer.AddReadyCheck("main-procedure", progress.Check)
+	diagnosticsServer.Start()
 	recommenderMetrics.RegisterMetrics()
+	leaderelection.RegisterMetrics()
+	sharding.RegisterMetrics()
 
-	config := util.CreateKubeConfig(*kubeconfig, float32(*kubeApiQps), *kubeApiBurst)
+	config := util.CreateKubeConfig(*kubeconfig, componentConfig.ClientConnection.QPS, int(componentConfig.ClientConnection.Burst))
 	kubeclient := kubeClient.NewForConfigOrDie(config)
 	mpaClient := mpaClientset.NewForConfigOrDie(config)
 	factory := informers.NewSharedInformerFactory(kubeclient, defaultResyncPeriod)
-	targetSelectorFetcher := target.NewMpaTargetSelectorFetcher(config, kubeclient, factory)
+	targetSelectorFetcher := target.NewMpaTargetSelectorFetcher(config, kubeclient, factory, stopCh)
 
-	customMetricsClient := recommenderUtil.NewCustomMetricsClient(config)
-	recommendationCalculator := recommendation.NewCalculator(recommenderMetrics.NewClient(customMetricsClient))
+	customMetricsClient := recommenderUtil.NewCustomMetricsClient(config, stopCh)
+	metricsClient := recommenderMetrics.NewClient(customMetricsClient)
+	mmcCalculator := recommendation.NewCalculator(metricsClient)
+	algorithms := []recommendation.Algorithm{
+		mmcCalculator,
+		recommendation.NewTargetUtilizationCalculator(metricsClient),
+		recommendation.NewPercentileHistogramCalculator(metricsClient),
+	}
+	if componentConfig.Plugin.Address != "" {
+		// 插件调用失败时使用内置的 mmc 算法
+		grpcCalculator, err := recommendation.NewGrpcCalculator(metricsClient, componentConfig.Plugin.Address,
+			componentConfig.Plugin.Timeout.Duration, mmcCalculator)
+		if err != nil {
+			klog.Fatalf("failed to create recommendation plugin client: %v", err)
+		}
+		algorithms = append(algorithms, grpcCalculator)
+	}
+	recommendationAlgorithms, err := recommendation.NewRegistry(componentConfig.DefaultAlgorithm, algorithms...)
+	if err != nil {
+		klog.Fatalf("failed to create recommendation algorithms: %v", err)
+	}
 
-	limitRangeCalculator, err := limitrange.NewCalculator(factory)
+	limitRangeCalculator, err := limitrange.NewCalculator(factory, stopCh)
 	if err != nil {
 		limitRangeCalculator = nil
 	}
 	recommendationProcessor := utilRecommendation.NewProcessor(limitRangeCalculator)
 
+	// 开启分片时, 各副本同时运行, 只处理分配给自己的命名空间中的MPA
+	var member *sharding.Member
+	var sharder sharding.Sharder
+	if shardingConfig.Enabled {
+		member, err = sharding.NewMember(kubeclient, shardingConfig, "mpa-recommender", stopCh)
+		if err != nil {
+			klog.Fatalf("failed to join the recommender shard group: %v", err)
+		}
+		sharder = member
+	}
+
 	recommender, err := logic.NewRecommender(
 		kubeclient,
 		mpaClient,
+		factory,
 		targetSelectorFetcher,
-		recommendationCalculator,
+		recommendationAlgorithms,
 		recommendationProcessor,
-		*mpaObjectNamespace,
+		componentConfig.MpaObjectNamespace,
+		sharder,
+		int(componentConfig.Workers),
+		componentConfig.RecommenderInterval.Duration,
+		progress,
+		stopCh,
 	)
 	if err != nil {
 		klog.Fatalf("failed to create MPA recommender: %v", err)
 	}
-	ticker := time.Tick(*recommenderInterval)
-	for range ticker {
-		ctx, cancel := context.WithTimeout(context.Background(), *recommenderInterval)
-		defer cancel()
+	informersSynced.Set()
+	if *configFile != "" {
+		// 配置文件变化时热加载
+		reloader := &configurationReloader{
+			initial:     componentConfig,
+			recommender: recommender,
+			algorithms:  recommendationAlgorithms,
+			progress:    progress,
+		}
+		go utilConfig.NewWatcher(*configFile, *configReloadPeriod, configData, reloader.reload).Run(ctx)
+	}
+	if member != nil {
+		klog.V(1).Infof("sharding: %v, identity: %s", shardingConfig, member.Identity())
+		// 退出时等待 membership Lease 被删除, 让其他副本立即接管
+		left := make(chan struct{})
+		go func() {
+			defer close(left)
+			member.Run(ctx)
+		}()
 		recommender.MainProcedure(ctx)
+		<-left
+	} else {
+		// 只有 leader 执行主流程; 其他副本的 informer 缓存保持同步, 随时接替
+		klog.V(1).Infof("leader election: %v", leaderElection)
+		leaderelection.Run(ctx, kubeclient, leaderElection, "mpa-recommender", recommender.MainProcedure)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := diagnosticsServer.Shutdown(shutdownCtx); err != nil {
+		klog.Errorf("failed to shutdown diagnostics server: %v", err)
 	}
+	klog.Infof("MPA recommender stopped")
+	klog.Flush()
 }
@@ -1,7 +1,11 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
 	"multidim-pod-autoscaler/pkg/util/metrics"
 )
 
@@ -9,12 +13,163 @@ const (
 	metricsNamespace = metrics.TopNamespace + "recommender"
 )
 
+// SkipReason 表示 MPA 本轮没有得到新的推荐方案的原因
+type SkipReason string
+
+const (
+	// NoControlledPods MPA 没有控制任何 pod
+	NoControlledPods SkipReason = "no_controlled_pods"
+	// UnknownAlgorithm MPA 指定的推荐算法不存在
+	UnknownAlgorithm SkipReason = "unknown_algorithm"
+	// CalculateFailed 计算推荐方案失败
+	CalculateFailed SkipReason = "calculate_failed"
+	// AdjustFailed 调整推荐方案失败
+	AdjustFailed SkipReason = "adjust_failed"
+	// KeepCurrent 推荐算法决定保持当前方案(如: 新方案的提升未超过更新阈值)
+	KeepCurrent SkipReason = "keep_current"
+	// UpdateStatusFailed 更新 MPA 的状态失败
+	UpdateStatusFailed SkipReason = "update_status_failed"
+)
+
 var (
 	recommenderLatency = metrics.CreateExecutionTimeMetric(metricsNamespace, "mpa recommender主流程中的执行时间")
+	overlappingMpas    = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "overlapping_mpas",
+			Help:      "与其他MPA匹配到相同POD的MPA个数",
+		},
+	)
+	pluginCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "plugin_calls_total",
+			Help:      "调用进程外推荐算法插件的次数(result 为 success 或失败后使用内置算法的原因)",
+		},
+		[]string{"result"},
+	)
+
+	recommendedReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "recommended_replicas",
+			Help:      "MPA 当前推荐的副本数",
+		},
+		metrics.MpaLabels,
+	)
+	recommendedResources = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "recommended_container_resources",
+			Help:      "MPA 当前为每个容器推荐的资源量(cpu 单位为核, memory 单位为字节)",
+		},
+		append(metrics.MpaLabels, "container", "resource"),
+	)
+	observedQps = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "observed_qps",
+			Help:      "MPA 最近一次计算推荐方案时观测到的服务 qps",
+		},
+		metrics.MpaLabels,
+	)
+	planScore = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "plan_score",
+			Help:      "MPA 最近一次计算推荐方案时最优方案的得分(越高越好, 推荐算法不给出得分时不上报)",
+		},
+		metrics.MpaLabels,
+	)
+	predictedResponseTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "predicted_response_time_seconds",
+			Help:      "按 M/M/c 模型预测的, 当前推荐方案在观测到的 qps 下的平均响应时间(处理能力不足时为 +Inf)",
+		},
+		metrics.MpaLabels,
+	)
+	recommendationSkips = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "recommendation_skips_total",
+			Help:      "MPA 没有得到新的推荐方案的次数(按原因区分)",
+		},
+		append(metrics.MpaLabels, "reason"),
+	)
+
+	// mpaSeries 记录每个 MPA 产生的时间序列, MPA 被删除后一并删除
+	mpaSeries = metrics.NewMpaSeries()
 )
 
 func RegisterMetrics() {
-	prometheus.MustRegister(recommenderLatency)
+	prometheus.MustRegister(recommenderLatency, overlappingMpas, pluginCalls,
+		recommendedReplicas, recommendedResources, observedQps, planScore, predictedResponseTime, recommendationSkips)
+}
+
+// ObserveOverlappingMpas 记录当前与其他MPA重叠的MPA个数
+func ObserveOverlappingMpas(count int) {
+	overlappingMpas.Set(float64(count))
+}
+
+// OnPluginCall 记录一次推荐算法插件的调用结果
+func OnPluginCall(result string) {
+	pluginCalls.WithLabelValues(result).Inc()
+}
+
+// ObserveRecommendation 记录 MPA 当前的推荐方案(副本数及每个容器的资源量)
+func ObserveRecommendation(namespace, name string, res *mpaTypes.RecommendedResources) {
+	if res == nil {
+		return
+	}
+	setMpaGauge(recommendedReplicas, float64(res.TargetPodNum), namespace, name)
+	// 容器可能被删除, 先删除上一次上报的推荐值
+	mpaSeries.ForgetVec(recommendedResources, namespace, name)
+	for _, container := range res.ContainerRecommendations {
+		if cpu, existed := container.Target[corev1.ResourceCPU]; existed {
+			setMpaGauge(recommendedResources, float64(cpu.MilliValue())/1000.0, namespace, name, container.ContainerName, string(corev1.ResourceCPU))
+		}
+		if memory, existed := container.Target[corev1.ResourceMemory]; existed {
+			setMpaGauge(recommendedResources, float64(memory.Value()), namespace, name, container.ContainerName, string(corev1.ResourceMemory))
+		}
+	}
+}
+
+// ObserveDecision 记录 MPA 最近一次决策观测到的 qps 及最优方案的得分
+func ObserveDecision(namespace, name string, decision *mpaTypes.RecommendationDecision) {
+	if decision == nil {
+		return
+	}
+	if qps, err := strconv.ParseFloat(decision.ObservedQps, 64); err == nil {
+		setMpaGauge(observedQps, qps, namespace, name)
+	}
+	if decision.Chosen == nil {
+		return
+	}
+	if score, err := strconv.ParseFloat(decision.Chosen.Score, 64); err == nil {
+		setMpaGauge(planScore, score, namespace, name)
+	}
+}
+
+// ObservePredictedResponseTime 记录 MPA 当前推荐方案预测的平均响应时间(s)
+func ObservePredictedResponseTime(namespace, name string, seconds float64) {
+	setMpaGauge(predictedResponseTime, seconds, namespace, name)
+}
+
+// OnRecommendationSkipped 记录 MPA 一次没有得到新的推荐方案的原因
+func OnRecommendationSkipped(namespace, name string, reason SkipReason) {
+	recommendationSkips.WithLabelValues(namespace, name, string(reason)).Inc()
+	mpaSeries.Track(recommendationSkips, namespace, name, string(reason))
+}
+
+// DeleteMpa 删除 MPA 的所有时间序列(MPA 已被删除或已不由当前副本负责)
+func DeleteMpa(namespace, name string) {
+	mpaSeries.Forget(namespace, name)
+}
+
+func setMpaGauge(vec *prometheus.GaugeVec, value float64, labelValues ...string) {
+	vec.WithLabelValues(labelValues...).Set(value)
+	mpaSeries.Track(vec, labelValues...)
 }
 
 func NewExecutionTimer() *metrics.ExecutionTimer {
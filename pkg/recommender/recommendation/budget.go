@@ -0,0 +1,204 @@
+package recommendation
+
+import (
+	"math"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PlanEvaluator 可选接口, 由可以评估搜索空间中每个方案的算法实现
+// 用于为多个 MPA 联合分配预算(MpaBudget); 未实现该接口的算法的 MPA 不参与联合分配
+type PlanEvaluator interface {
+	// EvaluatePlans 返回当前负载下满足 constraints 且得分大于 0 的方案
+	EvaluatePlans(
+		mpaWithSelector *utilMpa.MpaWithSelector,
+		controlledPod []*corev1.Pod,
+		constraints ...PlanConstraint,
+	) ([]PlanCandidate, error)
+}
+
+// PlanCandidate 方案(副本数 × 每个副本的 cpu)及其得分
+type PlanCandidate struct {
+	PodNum int64
+	// CpuMilli 每个副本(每个容器)的 cpu(m)
+	CpuMilli int64
+	// Score 方案的得分(资源成本项与违约成本项的加权和)
+	Score float64
+}
+
+// BudgetDemand 预算选中的一个 MPA
+type BudgetDemand struct {
+	// Candidates 可行的方案, 为空时该 MPA 不参与联合分配
+	Candidates []PlanCandidate
+	// Fixed 不参与联合分配的 MPA 的当前方案(计入预算的用量), 可以为 nil
+	Fixed *PlanCandidate
+	// ContainerNum 每个副本的容器数(推荐的 cpu 应用到每个容器)
+	ContainerNum int64
+	// PodMemory 每个副本的 memory request(byte)
+	PodMemory int64
+}
+
+// Usage 返回方案 p 占用的资源
+func (d *BudgetDemand) Usage(p *PlanCandidate) BudgetUsage {
+	if p == nil {
+		return BudgetUsage{}
+	}
+	return BudgetUsage{
+		CpuMilli: p.PodNum * p.CpuMilli * d.ContainerNum,
+		Memory:   p.PodNum * d.PodMemory,
+		Replicas: p.PodNum,
+	}
+}
+
+// BudgetUsage 资源用量, cpu 单位为 m, memory 单位为 byte
+type BudgetUsage struct {
+	CpuMilli int64
+	Memory   int64
+	Replicas int64
+}
+
+func (u BudgetUsage) add(other BudgetUsage) BudgetUsage {
+	return BudgetUsage{CpuMilli: u.CpuMilli + other.CpuMilli, Memory: u.Memory + other.Memory, Replicas: u.Replicas + other.Replicas}
+}
+
+func (u BudgetUsage) sub(other BudgetUsage) BudgetUsage {
+	return BudgetUsage{CpuMilli: u.CpuMilli - other.CpuMilli, Memory: u.Memory - other.Memory, Replicas: u.Replicas - other.Replicas}
+}
+
+// BudgetLimits 预算, 值小于 0 的资源不限制
+type BudgetLimits BudgetUsage
+
+// Allows 判断用量 u 是否在预算内
+func (l BudgetLimits) Allows(u BudgetUsage) bool {
+	return (l.CpuMilli < 0 || u.CpuMilli <= l.CpuMilli) &&
+		(l.Memory < 0 || u.Memory <= l.Memory) &&
+		(l.Replicas < 0 || u.Replicas <= l.Replicas)
+}
+
+// cost 返回用量 u 占预算的比例之和(只考虑被限制的资源)
+func (l BudgetLimits) cost(u BudgetUsage) float64 {
+	var cost float64
+	if l.CpuMilli > 0 {
+		cost += float64(u.CpuMilli) / float64(l.CpuMilli)
+	}
+	if l.Memory > 0 {
+		cost += float64(u.Memory) / float64(l.Memory)
+	}
+	if l.Replicas > 0 {
+		cost += float64(u.Replicas) / float64(l.Replicas)
+	}
+	return cost
+}
+
+// BudgetAllocation 联合分配的结果
+type BudgetAllocation struct {
+	// Plans 每个 MPA 分配的方案(与 demands 的顺序一致), nil 表示该 MPA 没有方案
+	Plans []*PlanCandidate
+	// Binding 各 MPA 独立的最优方案之和是否超出预算
+	Binding bool
+	// Feasible 为 false 时每个 MPA 使用资源最少的方案仍超出预算, Plans 为资源最少的方案
+	Feasible bool
+	// Used 分配的资源之和
+	Used BudgetUsage
+}
+
+// AllocateBudget 在预算 limits 内为 demands 联合分配方案
+// 各 MPA 独立的最优方案之和在预算内时直接使用这些方案;
+// 否则从每个 MPA 资源最少的方案开始, 每次选择单位资源(占预算的比例)得分提升最大且不超出预算的方案替换,
+// 直到没有可以替换的方案(贪心近似最大化得分之和)
+// 得分包含违约成本项, 因此资源优先分配给减少资源时违约成本上升最快的 MPA
+func AllocateBudget(demands []BudgetDemand, limits BudgetLimits) *BudgetAllocation {
+	result := &BudgetAllocation{Plans: make([]*PlanCandidate, len(demands)), Feasible: true}
+
+	// 各 MPA 独立的最优方案
+	for i := range demands {
+		d := &demands[i]
+		if len(d.Candidates) == 0 {
+			result.Plans[i] = d.Fixed
+		} else {
+			result.Plans[i] = bestCandidate(d.Candidates)
+		}
+		result.Used = result.Used.add(d.Usage(result.Plans[i]))
+	}
+	if limits.Allows(result.Used) {
+		return result
+	}
+	result.Binding = true
+
+	// 从资源最少的方案开始
+	result.Used = BudgetUsage{}
+	for i := range demands {
+		d := &demands[i]
+		if len(d.Candidates) > 0 {
+			result.Plans[i] = cheapestCandidate(d, limits)
+		}
+		result.Used = result.Used.add(d.Usage(result.Plans[i]))
+	}
+	if !limits.Allows(result.Used) {
+		result.Feasible = false
+		return result
+	}
+
+	for {
+		chosenDemand, chosenPlan := -1, -1
+		var chosenRatio, chosenGain float64
+		for i := range demands {
+			d := &demands[i]
+			current := result.Plans[i]
+			if len(d.Candidates) == 0 || current == nil {
+				continue
+			}
+			base := result.Used.sub(d.Usage(current))
+			for j := range d.Candidates {
+				candidate := &d.Candidates[j]
+				gain := candidate.Score - current.Score
+				if gain <= 0 || !limits.Allows(base.add(d.Usage(candidate))) {
+					continue
+				}
+				// 资源不增加的方案优先
+				ratio := math.Inf(1)
+				if delta := limits.cost(d.Usage(candidate)) - limits.cost(d.Usage(current)); delta > 0 {
+					ratio = gain / delta
+				}
+				if chosenDemand < 0 || ratio > chosenRatio || (ratio == chosenRatio && gain > chosenGain) {
+					chosenDemand, chosenPlan, chosenRatio, chosenGain = i, j, ratio, gain
+				}
+			}
+		}
+		if chosenDemand < 0 {
+			break
+		}
+		d := &demands[chosenDemand]
+		result.Used = result.Used.sub(d.Usage(result.Plans[chosenDemand])).add(d.Usage(&d.Candidates[chosenPlan]))
+		result.Plans[chosenDemand] = &d.Candidates[chosenPlan]
+	}
+	return result
+}
+
+// bestCandidate 返回得分最高的方案, 得分相同时选择 cpu 总量较少的方案
+func bestCandidate(candidates []PlanCandidate) *PlanCandidate {
+	var best *PlanCandidate
+	for i := range candidates {
+		c := &candidates[i]
+		if best == nil || c.Score > best.Score ||
+			(c.Score == best.Score && c.PodNum*c.CpuMilli < best.PodNum*best.CpuMilli) {
+			best = c
+		}
+	}
+	return best
+}
+
+// cheapestCandidate 返回占预算比例最小的方案, 相同时选择得分较高的方案
+func cheapestCandidate(d *BudgetDemand, limits BudgetLimits) *PlanCandidate {
+	var cheapest *PlanCandidate
+	var cheapestCost float64
+	for i := range d.Candidates {
+		c := &d.Candidates[i]
+		cost := limits.cost(d.Usage(c))
+		if cheapest == nil || cost < cheapestCost || (cost == cheapestCost && c.Score > cheapest.Score) {
+			cheapest, cheapestCost = c, cost
+		}
+	}
+	return cheapest
+}
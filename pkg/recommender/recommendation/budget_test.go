@@ -0,0 +1,127 @@
+package recommendation
+
+import (
+	"testing"
+)
+
+func TestAllocateBudget(t *testing.T) {
+	unlimited := BudgetLimits{CpuMilli: -1, Memory: -1, Replicas: -1}
+	cpuLimit := func(cpuMilli int64) BudgetLimits {
+		limits := unlimited
+		limits.CpuMilli = cpuMilli
+		return limits
+	}
+	// demand 每个副本 1 个容器、memory 1Gi, 可选方案为 1 或 2 个副本 × 1000m
+	demand := func(oneScore, twoScore float64) BudgetDemand {
+		return BudgetDemand{
+			Candidates: []PlanCandidate{
+				{PodNum: 1, CpuMilli: 1000, Score: oneScore},
+				{PodNum: 2, CpuMilli: 1000, Score: twoScore},
+			},
+			ContainerNum: 1,
+			PodMemory:    1 << 30,
+		}
+	}
+	fixed := BudgetDemand{Fixed: &PlanCandidate{PodNum: 1, CpuMilli: 1000}, ContainerNum: 1, PodMemory: 1 << 30}
+
+	tests := []struct {
+		name             string
+		demands          []BudgetDemand
+		limits           BudgetLimits
+		expectedPodNums  []int64
+		expectedBinding  bool
+		expectedFeasible bool
+		expectedUsed     BudgetUsage
+	}{
+		{
+			name:             "unlimited budget uses the best plans",
+			demands:          []BudgetDemand{demand(0.5, 0.9), demand(0.5, 0.6)},
+			limits:           unlimited,
+			expectedPodNums:  []int64{2, 2},
+			expectedFeasible: true,
+			expectedUsed:     BudgetUsage{CpuMilli: 4000, Memory: 4 << 30, Replicas: 4},
+		},
+		{
+			name:             "best plans within the budget are not binding",
+			demands:          []BudgetDemand{demand(0.5, 0.9), demand(0.5, 0.6)},
+			limits:           cpuLimit(4000),
+			expectedPodNums:  []int64{2, 2},
+			expectedFeasible: true,
+			expectedUsed:     BudgetUsage{CpuMilli: 4000, Memory: 4 << 30, Replicas: 4},
+		},
+		{
+			name:             "binding budget goes to the MPA with the larger gain",
+			demands:          []BudgetDemand{demand(0.5, 0.6), demand(0.5, 0.9)},
+			limits:           cpuLimit(3000),
+			expectedPodNums:  []int64{1, 2},
+			expectedBinding:  true,
+			expectedFeasible: true,
+			expectedUsed:     BudgetUsage{CpuMilli: 3000, Memory: 3 << 30, Replicas: 3},
+		},
+		{
+			name:             "binding replicas limit",
+			demands:          []BudgetDemand{demand(0.5, 0.9), demand(0.5, 0.6)},
+			limits:           BudgetLimits{CpuMilli: -1, Memory: -1, Replicas: 3},
+			expectedPodNums:  []int64{2, 1},
+			expectedBinding:  true,
+			expectedFeasible: true,
+			expectedUsed:     BudgetUsage{CpuMilli: 3000, Memory: 3 << 30, Replicas: 3},
+		},
+		{
+			name:             "fixed plans count towards the budget",
+			demands:          []BudgetDemand{fixed, demand(0.5, 0.9)},
+			limits:           cpuLimit(2500),
+			expectedPodNums:  []int64{1, 1},
+			expectedBinding:  true,
+			expectedFeasible: true,
+			expectedUsed:     BudgetUsage{CpuMilli: 2000, Memory: 2 << 30, Replicas: 2},
+		},
+		{
+			name:             "infeasible budget falls back to the smallest plans",
+			demands:          []BudgetDemand{demand(0.5, 0.9), demand(0.5, 0.6)},
+			limits:           cpuLimit(1500),
+			expectedPodNums:  []int64{1, 1},
+			expectedBinding:  true,
+			expectedFeasible: false,
+			expectedUsed:     BudgetUsage{CpuMilli: 2000, Memory: 2 << 30, Replicas: 2},
+		},
+		{
+			name:             "MPA without plans",
+			demands:          []BudgetDemand{{ContainerNum: 1}, demand(0.5, 0.9)},
+			limits:           cpuLimit(1000),
+			expectedPodNums:  []int64{0, 1},
+			expectedBinding:  true,
+			expectedFeasible: true,
+			expectedUsed:     BudgetUsage{CpuMilli: 1000, Memory: 1 << 30, Replicas: 1},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := AllocateBudget(tc.demands, tc.limits)
+			if len(result.Plans) != len(tc.expectedPodNums) {
+				t.Fatalf("expected %d plans, got %d", len(tc.expectedPodNums), len(result.Plans))
+			}
+			for i, podNum := range tc.expectedPodNums {
+				plan := result.Plans[i]
+				if podNum == 0 {
+					if plan != nil {
+						t.Errorf("expected no plan for demand %d, got %v", i, *plan)
+					}
+					continue
+				}
+				if plan == nil || plan.PodNum != podNum {
+					t.Errorf("expected %d pods for demand %d, got %v", podNum, i, plan)
+				}
+			}
+			if result.Binding != tc.expectedBinding {
+				t.Errorf("expected binding %v, got %v", tc.expectedBinding, result.Binding)
+			}
+			if result.Feasible != tc.expectedFeasible {
+				t.Errorf("expected feasible %v, got %v", tc.expectedFeasible, result.Feasible)
+			}
+			if result.Used != tc.expectedUsed {
+				t.Errorf("expected used %+v, got %+v", tc.expectedUsed, result.Used)
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"multidim-pod-autoscaler/pkg/recommender/metrics"
 	"multidim-pod-autoscaler/pkg/recommender/util"
 	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	"sort"
+	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -15,17 +18,6 @@ import (
 )
 
 var (
-	cpuRequestMap = map[int64]int64{
-		250:  6,
-		500:  12,
-		750:  20,
-		1000: 26,
-		1250: 34,
-		1500: 40,
-		1750: 46,
-		2000: 52,
-		2250: 60,
-	}
 	servicePenaltyCostMap = map[float64]float64{
 		95.0: 1.0,
 		90.0: 0.9,
@@ -34,6 +26,15 @@ var (
 		60.0: 0.3,
 		0.0:  0.0,
 	}
+	// servicePenaltyThresholds servicePenaltyCostMap 的阈值(降序)
+	servicePenaltyThresholds = func() []float64 {
+		thresholds := make([]float64, 0, len(servicePenaltyCostMap))
+		for score := range servicePenaltyCostMap {
+			thresholds = append(thresholds, score)
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(thresholds)))
+		return thresholds
+	}()
 	// fractional constant
 	factConst = []float64{1,
 		1, 2, 6, 24, 120,
@@ -49,25 +50,17 @@ var (
 	}
 )
 
+// 成本模型的其他参数(cpu 档位的处理能力、cpu 单价、更新阈值等)见 Model
 const (
 	podNumMin int64 = 1
-	podNumMax int64 = 16
-	// defaultResponseTime 请求默认响应时间 ms
-	defaultResponseTime = 300
-	// cpuPrice cpu单价 vCore/s
-	cpuPrice = 0.00003334
 	// podCreateTime pod 创建时间 ms
 	podCreateTime int64 = 5000
 	// recommenderInterval 两次推荐的间隔时间
 	recommenderInterval = int64(1 * 60 * 1000)
-	// 资源成本与违约成本分别的占比
-	resourceCostRatio = 0.6
-	penaltyCostRatio  = 1.0 - resourceCostRatio
-	// 资源成本的最值
-	resourceCostMax = float64(podNumMax*2250) * cpuPrice
-	resourceCostMin = float64(podNumMin*250) * cpuPrice
-	// 推荐方案更新的阈值
-	recommendationBetterThresold = 0.1
+	// qpsConfidenceZ qps 估计值置信区间的 z 值(95%)
+	qpsConfidenceZ = 1.96
+	// decisionAlternatives 决策记录中保留的其他方案的个数
+	decisionAlternatives = 3
 )
 
 type RecommendationAction string
@@ -79,32 +72,237 @@ const (
 )
 
 type Calculator interface {
+	// Calculate 计算mpa的推荐方案
+	// constraints 过滤搜索空间中不可行的方案; 被过滤的最优方案记录在 UncappedTargetPodNum / UncappedTarget 中
+	// 返回的决策记录(可以为空)解释选择该方案或保持当前方案的原因, 其时间由调用者设置
 	Calculate(
 		mpaWithSelector *utilMpa.MpaWithSelector,
 		controlledPod []*corev1.Pod,
-	) (*mpaTypes.RecommendedResources, RecommendationAction, error)
+		constraints ...PlanConstraint,
+	) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, error)
+}
+
+// PlanConstraint 描述推荐方案(副本数 × 每个副本的 cpu)需要满足的约束(如: ResourceQuota)
+type PlanConstraint struct {
+	// Name 约束名, 用于日志及状态描述
+	Name string
+	// Description 可选, 约束当前的状态(如: 剩余资源), 用于解释方案被拒绝的原因
+	Description string
+	// Allows 判断 podNum 个副本、每个副本 cpuMilli(m) 的方案是否满足约束
+	Allows func(podNum, cpuMilli int64) bool
+	// Preference 可选, 返回方案的偏好程度([0, 1], 如: bin-packing 的效果)
+	// 方案的得分按 Model.PreferenceWeight 的权重进行折算
+	Preference func(podNum, cpuMilli int64) float64
+}
+
+// constrainedScore 根据 constraints 的偏好程度折算方案的得分
+func constrainedScore(score float64, podNum, cpuMilli int64, constraints []PlanConstraint) float64 {
+	for _, constraint := range constraints {
+		if constraint.Preference == nil {
+			continue
+		}
+		score *= 1 - getModel().PreferenceWeight*(1-constraint.Preference(podNum, cpuMilli))
+	}
+	return score
+}
+
+// RejectedBy 返回 constraints 中不允许该方案的约束
+func RejectedBy(podNum, cpuMilli int64, constraints []PlanConstraint) []PlanConstraint {
+	rejected := make([]PlanConstraint, 0)
+	for _, constraint := range constraints {
+		if !constraint.Allows(podNum, cpuMilli) {
+			rejected = append(rejected, constraint)
+		}
+	}
+	return rejected
 }
 
+// calculator 基于 M/M/c 排队论模型的推荐算法
+// 在搜索空间中选择资源成本与违约成本加权得分最高的方案
 type calculator struct {
 	metricsClient metrics.Client
 }
 
-func NewCalculator(client metrics.Client) Calculator {
+// mmcParams M/M/c 算法的参数
+type mmcParams struct {
+	// ResponseTimeMs 请求的期望响应时间(ms), 覆盖 resourcePolicy 中的 expRespTime
+	ResponseTimeMs int `json:"responseTimeMs,omitempty"`
+}
+
+func NewCalculator(client metrics.Client) Algorithm {
 	return &calculator{
 		metricsClient: client,
 	}
 }
 
+// Name 实现 Algorithm 接口
+func (c *calculator) Name() string {
+	return MMCAlgorithm
+}
+
+// Version 实现 Algorithm 接口
+func (c *calculator) Version() string {
+	return "v1"
+}
+
 func (c *calculator) Calculate(
 	mpaWithSelector *utilMpa.MpaWithSelector,
 	controlledPod []*corev1.Pod,
-) (*mpaTypes.RecommendedResources, RecommendationAction, error) {
+	constraints ...PlanConstraint,
+) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, error) {
+	params := mmcParams{}
+	if err := DecodeParams(mpaWithSelector.Mpa, &params); err != nil {
+		return nil, UnknownRecommendation, nil, err
+	}
+	serviceQps, podsQps, resourceFormat, err := getServiceQps(c.metricsClient, mpaWithSelector, controlledPod)
+	if err != nil {
+		return nil, UnknownRecommendation, nil, err
+	}
+	expectResponseTime := getExpectResponseTime(mpaWithSelector.Mpa, &params)
+	var oldScore float64
+	// 获取当前负载下不考虑约束的推荐方案
+	_, uncappedPodNum, uncappedPodResource := recommendResource(serviceQps, expectResponseTime)
+	// 获取当前负载下满足约束的推荐方案
+	plans := evaluatePlans(serviceQps, expectResponseTime, constraints...)
+	score, targetPodNum, targetPodResource := bestPlan(plans)
+	if targetPodNum == 0 {
+		return nil, UnknownRecommendation, nil, fmt.Errorf("no plan satisfies the constraints(the best plan %d pods × %dm is rejected by %s)",
+			uncappedPodNum, uncappedPodResource, constraintNames(RejectedBy(uncappedPodNum, uncappedPodResource, constraints)))
+	}
+
+	// 推荐方案的上下界: 近似最优的方案, 以及 qps 置信区间两端的最优方案
+	// 得分随方案的变化并不平滑, 副本数(cpu)的上下界只考虑与推荐方案 cpu(副本数)相同的方案, 避免上下界覆盖整个搜索空间
+	bounds := newPlanBounds(targetPodNum, targetPodResource)
+	bounds.addNearOptimal(plans, score)
+	qpsLow, qpsHigh := qpsConfidenceInterval(serviceQps, podsQps)
+	sameCpu := append([]PlanConstraint{fixedPlanConstraint(0, targetPodResource)}, constraints...)
+	samePodNum := append([]PlanConstraint{fixedPlanConstraint(targetPodNum, 0)}, constraints...)
+	for _, qps := range []float64{qpsLow, qpsHigh} {
+		if _, podNum, podResource := recommendResource(qps, expectResponseTime, sameCpu...); podNum > 0 {
+			bounds.add(podNum, podResource)
+		}
+		if _, podNum, podResource := recommendResource(qps, expectResponseTime, samePodNum...); podNum > 0 {
+			bounds.add(podNum, podResource)
+		}
+	}
+	klog.V(4).Infof("recommendation bounds(qps in [%g, %g]): pods in [%d, %d], cpu in [%dm, %dm]",
+		qpsLow, qpsHigh, bounds.minPodNum, bounds.maxPodNum, bounds.minCpu, bounds.maxCpu)
+
+	chosen := findPlan(plans, targetPodNum, targetPodResource)
+	decision := &mpaTypes.RecommendationDecision{
+		ObservedQps:  formatDecimal(serviceQps),
+		Chosen:       chosen.evaluation(resourceFormat),
+		Alternatives: topAlternatives(plans, chosen, resourceFormat),
+	}
+
+	// 计算旧的资源方案在新的qps下的得分
+	podNum, cpu, existed := oldPlan(mpaWithSelector.Mpa)
+	if !existed {
+		decision.Reason = "no current recommendation"
+	} else {
+		current := planString(podNum, cpu)
+		if cpu == targetPodResource && podNum == targetPodNum {
+			oldScore = score
+			decision.Current = chosen.evaluation(resourceFormat)
+		} else if rejected := RejectedBy(podNum, cpu, constraints); len(rejected) > 0 {
+			// 旧方案已不满足约束(如: quota 被调低), 必须更新
+			oldScore = 0
+			decision.Reason = fmt.Sprintf("current plan %s is rejected by %s", current, constraintNames(rejected))
+		} else if getModel().PodCapacities[cpu] > 0 {
+			old := evaluatePlan(podNum, cpu, serviceQps, expectResponseTime, constraints)
+			oldScore = old.score
+			decision.Current = old.evaluation(resourceFormat)
+		} else {
+			decision.Reason = fmt.Sprintf("current plan %s is not in the search space", current)
+		}
+		if decision.Reason == "" {
+			decision.Reason = thresholdReason(score, oldScore, current)
+		}
+	}
+
+	// 如果旧方案得分为零(无方案) 或 当前方案得分超出旧方案得分 threshold 则进行更新
+	if oldScore < 0.0000001 || (score-oldScore)/oldScore > getModel().UpdateThreshold {
+		decision.Action = mpaTypes.RecommendationDecisionApply
+		return newRecommendedResources(targetPodNum, targetPodResource, uncappedPodNum, uncappedPodResource, bounds, resourceFormat),
+			ApplyRecommendation, decision, nil
+	}
+
+	decision.Action = mpaTypes.RecommendationDecisionKeep
+	return &mpaTypes.RecommendedResources{}, SkipRecommendation, decision, nil
+}
+
+// EvaluatePlans 实现 PlanEvaluator 接口
+func (c *calculator) EvaluatePlans(
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+	constraints ...PlanConstraint,
+) ([]PlanCandidate, error) {
+	params := mmcParams{}
+	if err := DecodeParams(mpaWithSelector.Mpa, &params); err != nil {
+		return nil, err
+	}
+	serviceQps, _, _, err := getServiceQps(c.metricsClient, mpaWithSelector, controlledPod)
+	if err != nil {
+		return nil, err
+	}
+	plans := evaluatePlans(serviceQps, getExpectResponseTime(mpaWithSelector.Mpa, &params), constraints...)
+	candidates := make([]PlanCandidate, 0, len(plans))
+	for _, p := range plans {
+		if p.score > 0 {
+			candidates = append(candidates, PlanCandidate{PodNum: p.podNum, CpuMilli: p.cpu, Score: p.score})
+		}
+	}
+	return candidates, nil
+}
+
+// getExpectResponseTime 返回请求的期望响应时间(ms)
+// 算法参数中的 responseTimeMs 优先, 其次为第一个容器策略的 expRespTime, 最后为成本模型的默认值
+func getExpectResponseTime(mpa *mpaTypes.MultidimPodAutoscaler, params *mmcParams) int {
+	expectResponseTime := getModel().DefaultResponseTimeMs
+	if mpa.Spec.ResourcePolicy != nil &&
+		len(mpa.Spec.ResourcePolicy.ContainerPolicies) > 0 {
+		expectResponseTime = mpa.Spec.ResourcePolicy.ContainerPolicies[0].ExpRespTime
+	}
+	if params.ResponseTimeMs > 0 {
+		expectResponseTime = params.ResponseTimeMs
+	}
+	return expectResponseTime
+}
+
+// thresholdReason 描述新方案得分 score 与当前方案 current 得分 oldScore 的比较结果(是否超过更新阈值)
+func thresholdReason(score, oldScore float64, current string) string {
+	if oldScore < 0.0000001 {
+		return fmt.Sprintf("current plan %s cannot serve the qps (score 0)", current)
+	}
+	if score == oldScore {
+		return fmt.Sprintf("current plan %s is still the best plan", current)
+	}
+	improvement := (score - oldScore) / oldScore
+	threshold := getModel().UpdateThreshold
+	if improvement <= 0 {
+		return fmt.Sprintf("score %s of the best plan is not higher than %s of current plan %s",
+			formatDecimal(score), formatDecimal(oldScore), current)
+	}
+	if improvement > threshold {
+		return fmt.Sprintf("score %s of the best plan is %.1f%% higher than %s of current plan %s (threshold %.0f%%)",
+			formatDecimal(score), improvement*100, formatDecimal(oldScore), current, threshold*100)
+	}
+	return fmt.Sprintf("score %s of the best plan is only %.1f%% higher than %s of current plan %s (threshold %.0f%%)",
+		formatDecimal(score), improvement*100, formatDecimal(oldScore), current, threshold*100)
+}
+
+// getServiceQps 获取 pods 的 qps, 返回服务的总 qps、各 pod 的 qps 以及 metrics 的格式
+func getServiceQps(
+	metricsClient metrics.Client,
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+) (float64, []float64, resource.Format, error) {
 	klog.V(2).Infof("attempt to get qps in Namespace(%s) with podSelector(%s)", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Selector.String())
 	// 获取pods的qps
 	podsMetricsInfo, _, err :=
-		c.metricsClient.GetPodRawMetric("http_requests", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Selector, labels.NewSelector())
+		metricsClient.GetPodRawMetric("http_requests", mpaWithSelector.Mpa.Namespace, mpaWithSelector.Selector, labels.NewSelector())
 	if err != nil {
-		return nil, UnknownRecommendation, fmt.Errorf("failed to get pods' qps: %v", err.Error())
+		return 0, nil, "", fmt.Errorf("failed to get pods' qps: %v", err.Error())
 	}
 
 	klog.V(2).Infof("get qps metrics of pods: %v", podsMetricsInfo)
@@ -113,84 +311,170 @@ func (c *calculator) Calculate(
 
 	// 统计所有 pod副本的 qps
 	var serviceQps float64
+	podsQps := make([]float64, 0, len(controlledPod))
 	for _, pod := range controlledPod {
 		metricsInfo, exists := podsMetricsInfo[util.GetPodId(pod)]
 		if !exists {
 			klog.Infof("connot get the http_requests metrics of pod(%s/%s)", pod.Namespace, pod.Name)
 			continue
 		}
-		serviceQps += float64(metricsInfo.Value.MilliValue())
+		// 将 1000m 为单元的转为 1.0 为单元的数值
+		podQps := float64(metricsInfo.Value.MilliValue()) / 1000.0
+		podsQps = append(podsQps, podQps)
+		serviceQps += podQps
 		resourceFormat = metricsInfo.Value.Format
 	}
-	// 将 1000m 为单元的转为 1.0 为单元的数值
-	serviceQps = serviceQps / 1000.0
-	// 请求的期望响应时间
-	expectResponseTime := defaultResponseTime
-	if mpaWithSelector.Mpa.Spec.ResourcePolicy != nil &&
-		len(mpaWithSelector.Mpa.Spec.ResourcePolicy.ContainerPolicies) > 0 {
-		expectResponseTime = mpaWithSelector.Mpa.Spec.ResourcePolicy.ContainerPolicies[0].ExpRespTime
+	return serviceQps, podsQps, resourceFormat, nil
+}
+
+// oldPlan 返回 mpa 当前推荐方案在搜索空间中对应的方案(副本数及每个副本的 cpu)
+func oldPlan(mpa *mpaTypes.MultidimPodAutoscaler) (int64, int64, bool) {
+	if mpa.Status.RecommendationResources == nil || len(mpa.Status.RecommendationResources.ContainerRecommendations) == 0 {
+		return 0, 0, false
 	}
-	var oldScore float64
-	// 获取当前负载下的推荐方案
-	score, targetPodNum, targetPodResource := recommendResource(serviceQps, expectResponseTime)
-	targetQuantity := resource.NewMilliQuantity(targetPodResource, resourceFormat)
+	oldRecommendation := mpa.Status.RecommendationResources.ContainerRecommendations[0]
+	cpuQuantity := oldRecommendation.Target[corev1.ResourceCPU]
+	// 方案被 policy、limit range 调整过时(不在搜索空间中), 使用调整前的方案进行比较
+	if _, existed := getModel().PodCapacities[cpuQuantity.MilliValue()]; !existed {
+		if uncapped, existed := oldRecommendation.UncappedTarget[corev1.ResourceCPU]; existed {
+			cpuQuantity = uncapped
+		}
+	}
+	return int64(mpa.Status.RecommendationResources.TargetPodNum), cpuQuantity.MilliValue(), true
+}
 
-	// 计算旧的资源方案在新的qps下的得分
-	if mpaWithSelector.Mpa.Status.RecommendationResources != nil {
-		var cpuQuantity resource.Quantity
-		if len(mpaWithSelector.Mpa.Status.RecommendationResources.ContainerRecommendations) > 0 {
-			cpuQuantity = mpaWithSelector.Mpa.Status.RecommendationResources.ContainerRecommendations[0].Target[corev1.ResourceCPU]
+// newRecommendedResources 构造应用到所有容器("*")的推荐方案
+func newRecommendedResources(
+	targetPodNum, targetCpu, uncappedPodNum, uncappedCpu int64,
+	bounds *planBounds,
+	format resource.Format,
+) *mpaTypes.RecommendedResources {
+	return &mpaTypes.RecommendedResources{
+		TargetPodNum:         int(targetPodNum),
+		LowerBoundPodNum:     int(bounds.minPodNum),
+		UpperBoundPodNum:     int(bounds.maxPodNum),
+		UncappedTargetPodNum: int(uncappedPodNum),
+		ContainerRecommendations: []mpaTypes.RecommendedContainerResources{
+			{
+				Target:         corev1.ResourceList{corev1.ResourceCPU: *resource.NewMilliQuantity(targetCpu, format)},
+				LowerBound:     corev1.ResourceList{corev1.ResourceCPU: *resource.NewMilliQuantity(bounds.minCpu, format)},
+				UpperBound:     corev1.ResourceList{corev1.ResourceCPU: *resource.NewMilliQuantity(bounds.maxCpu, format)},
+				UncappedTarget: corev1.ResourceList{corev1.ResourceCPU: *resource.NewMilliQuantity(uncappedCpu, format)},
+				ContainerName:  mpaTypes.DefaultContainerResourcePolicy,
+			},
+		},
+	}
+}
+
+// plan 为搜索空间中的一个资源方案及其得分
+type plan struct {
+	podNum int64
+	// cpu 每个副本的 cpu(m)
+	cpu   int64
+	score float64
+	// 得分的组成(用于决策记录)
+	policyScore
+	serviceIntensity float64
+}
+
+// evaluation 返回方案的评估结果(用于决策记录)
+func (p plan) evaluation(format resource.Format) *mpaTypes.PlanEvaluation {
+	return &mpaTypes.PlanEvaluation{
+		PodNum:       int(p.podNum),
+		Cpu:          *resource.NewMilliQuantity(p.cpu, format),
+		Score:        formatDecimal(p.score),
+		ResourceCost: formatDecimal(p.resourceCost),
+		PenaltyCost:  formatDecimal(p.penaltyCost),
+		ServiceScore: formatDecimal(p.serviceScore),
+		Utilization:  formatDecimal(p.serviceIntensity),
+	}
+}
+
+// evaluatePlans 计算搜索空间中所有满足 constraints 的方案的得分
+func evaluatePlans(qps float64, expectRespTime int, constraints ...PlanConstraint) []plan {
+	m := getModel()
+	plans := make([]plan, 0, len(m.cpuLevels)*int(m.MaxPodNum-podNumMin+1))
+	// 按 cpu 升序遍历, 得分相同的方案中总是选择 cpu 较少的方案
+	for _, cpu := range m.cpuLevels {
+		for podNum := podNumMin; podNum <= m.MaxPodNum; podNum += 1 {
+			if rejected := RejectedBy(podNum, cpu, constraints); len(rejected) > 0 {
+				klog.V(5).Infof("policy(cpuQuantity=%dm,podNum=%d) rejected by %s", cpu, podNum, constraintNames(rejected))
+				continue
+			}
+			plans = append(plans, evaluatePlan(podNum, cpu, qps, expectRespTime, constraints))
 		}
-		podNum := mpaWithSelector.Mpa.Status.RecommendationResources.TargetPodNum
-		reqs := cpuRequestMap[cpuQuantity.MilliValue()]
-		if cpuQuantity.MilliValue() == targetPodResource && podNum == int(targetPodNum) {
-			oldScore = score
-		} else {
-			oldScore = evaluatePolicy(
-				cpuQuantity.MilliValue(),
-				int64(podNum),
-				reqs,
-				serviceQps,
-				float64(expectResponseTime),
-				serviceQps/float64(int64(podNum)*reqs),
-			)
+	}
+	return plans
+}
+
+// evaluatePlan 计算搜索空间中的方案(podNum 个副本, 每个副本 cpu(m))的得分
+func evaluatePlan(podNum, cpu int64, qps float64, expectRespTime int, constraints []PlanConstraint) plan {
+	reqs := getModel().PodCapacities[cpu]
+	waitTime := float64(expectRespTime) - 1.0/float64(reqs)
+	// 服务强度 ρ
+	serviceIntensity := qps / float64(podNum*reqs)
+
+	components := scorePolicy(cpu, podNum, reqs, qps, waitTime, serviceIntensity)
+	return plan{
+		podNum:           podNum,
+		cpu:              cpu,
+		score:            constrainedScore(components.score, podNum, cpu, constraints),
+		policyScore:      components,
+		serviceIntensity: serviceIntensity,
+	}
+}
+
+// findPlan 返回 plans 中的方案(podNum 个副本, 每个副本 cpu(m))
+func findPlan(plans []plan, podNum, cpu int64) plan {
+	for _, p := range plans {
+		if p.podNum == podNum && p.cpu == cpu {
+			return p
 		}
 	}
+	return plan{podNum: podNum, cpu: cpu}
+}
 
-	// 如果旧方案得分为零(无方案) 或 当前方案得分超出旧方案得分 threshold 则进行更新
-	if oldScore < 0.0000001 || (score-oldScore)/oldScore > recommendationBetterThresold {
-		return &mpaTypes.RecommendedResources{
-			TargetPodNum: int(targetPodNum),
-			ContainerRecommendations: []mpaTypes.RecommendedContainerResources{
-				{
-					Target:        corev1.ResourceList{corev1.ResourceCPU: *targetQuantity},
-					ContainerName: mpaTypes.DefaultContainerResourcePolicy,
-				},
-			},
-		}, ApplyRecommendation, nil
+// topAlternatives 返回除 chosen 外得分最高的 decisionAlternatives 个方案(得分为 0 的方案除外)
+func topAlternatives(plans []plan, chosen plan, format resource.Format) []mpaTypes.PlanEvaluation {
+	sorted := make([]plan, 0, len(plans))
+	for _, p := range plans {
+		if p.score > 0 && (p.podNum != chosen.podNum || p.cpu != chosen.cpu) {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].score != sorted[j].score {
+			return sorted[i].score > sorted[j].score
+		}
+		return sorted[i].podNum*sorted[i].cpu < sorted[j].podNum*sorted[j].cpu
+	})
+	alternatives := make([]mpaTypes.PlanEvaluation, 0, decisionAlternatives)
+	for i := 0; i < len(sorted) && i < decisionAlternatives; i += 1 {
+		alternatives = append(alternatives, *sorted[i].evaluation(format))
 	}
+	return alternatives
+}
 
-	return &mpaTypes.RecommendedResources{}, SkipRecommendation, nil
+// planString 返回方案的描述
+func planString(podNum, cpuMilli int64) string {
+	return fmt.Sprintf("%d pods × %dm", podNum, cpuMilli)
 }
 
-// recommendResource 通过伸缩推荐算法计算资源方案
-func recommendResource(qps float64, expectRespTime int) (float64, int64, int64) {
+// formatDecimal 将数值格式化为决策记录中的字符串(4 位有效数字)
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'g', 4, 64)
+}
+
+// bestPlan 返回得分最高的方案; 没有可行方案时返回的副本数为 0
+func bestPlan(plans []plan) (float64, int64, int64) {
 	var curPodNum, curCpuQuantity int64
 	var curScore float64
-
-	for cpu, reqs := range cpuRequestMap {
-		waitTime := float64(expectRespTime) - 1.0/float64(reqs)
-		for podNum := podNumMin; podNum <= podNumMax; podNum += 1 {
-			// 服务强度 ρ
-			serviceIntensity := qps / float64(podNum*reqs)
-
-			score := evaluatePolicy(cpu, podNum, reqs, qps, waitTime, serviceIntensity)
-			// 更新推荐方案
-			if score > curScore {
-				curScore = score
-				curPodNum = podNum
-				curCpuQuantity = cpu
-			}
+	for _, p := range plans {
+		// 更新推荐方案
+		if p.score > curScore {
+			curScore = p.score
+			curPodNum = p.podNum
+			curCpuQuantity = p.cpu
 		}
 	}
 
@@ -199,12 +483,113 @@ func recommendResource(qps float64, expectRespTime int) (float64, int64, int64)
 	return curScore, curPodNum, curCpuQuantity
 }
 
+// recommendResource 通过伸缩推荐算法计算资源方案
+// 不满足 constraints 的方案不参与选择; 没有可行方案时返回的副本数为 0
+func recommendResource(qps float64, expectRespTime int, constraints ...PlanConstraint) (float64, int64, int64) {
+	return bestPlan(evaluatePlans(qps, expectRespTime, constraints...))
+}
+
+// planBounds 推荐方案的上下界(副本数及每个副本的 cpu)
+type planBounds struct {
+	minPodNum, maxPodNum int64
+	minCpu, maxCpu       int64
+}
+
+// newPlanBounds 以推荐方案初始化上下界
+func newPlanBounds(podNum, cpu int64) *planBounds {
+	return &planBounds{minPodNum: podNum, maxPodNum: podNum, minCpu: cpu, maxCpu: cpu}
+}
+
+// add 扩展上下界使其包含该方案
+func (b *planBounds) add(podNum, cpu int64) {
+	if podNum < b.minPodNum {
+		b.minPodNum = podNum
+	}
+	if podNum > b.maxPodNum {
+		b.maxPodNum = podNum
+	}
+	if cpu < b.minCpu {
+		b.minCpu = cpu
+	}
+	if cpu > b.maxCpu {
+		b.maxCpu = cpu
+	}
+}
+
+// addNearOptimal 扩展上下界使其包含得分与最优得分相差不超过 Model.NearOptimalScoreRatio 的方案
+// 只考虑与初始方案副本数或 cpu 相同的方案
+func (b *planBounds) addNearOptimal(plans []plan, bestScore float64) {
+	threshold := bestScore * (1 - getModel().NearOptimalScoreRatio)
+	podNum, cpu := b.minPodNum, b.minCpu
+	for _, p := range plans {
+		if p.podNum != podNum && p.cpu != cpu {
+			continue
+		}
+		if p.score > 0 && p.score >= threshold {
+			b.add(p.podNum, p.cpu)
+		}
+	}
+}
+
+// fixedPlanConstraint 返回只允许副本数为 podNum 或 cpu 为 cpuMilli 的方案的约束(值为 0 时不限制)
+func fixedPlanConstraint(podNum, cpuMilli int64) PlanConstraint {
+	return PlanConstraint{
+		Name: "Fixed",
+		Allows: func(p, c int64) bool {
+			return (podNum == 0 || p == podNum) && (cpuMilli == 0 || c == cpuMilli)
+		},
+	}
+}
+
+// qpsConfidenceInterval 根据各 pod 的 qps 估计服务总 qps 的置信区间
+// 总 qps 的标准差按 sqrt(n) * 各 pod qps 的样本标准差估计, 且不小于 Model.QpsMinUncertainty 的相对误差
+func qpsConfidenceInterval(serviceQps float64, podsQps []float64) (float64, float64) {
+	delta := serviceQps * getModel().QpsMinUncertainty
+	if n := len(podsQps); n > 1 {
+		mean := serviceQps / float64(n)
+		var variance float64
+		for _, qps := range podsQps {
+			variance += (qps - mean) * (qps - mean)
+		}
+		variance /= float64(n - 1)
+		if d := qpsConfidenceZ * math.Sqrt(variance*float64(n)); d > delta {
+			delta = d
+		}
+	}
+	return math.Max(serviceQps-delta, 0), serviceQps + delta
+}
+
+// constraintNames 返回约束名(及其状态)的列表(逗号分隔)
+func constraintNames(constraints []PlanConstraint) string {
+	names := make([]string, len(constraints))
+	for i := range constraints {
+		names[i] = constraints[i].Name
+		if constraints[i].Description != "" {
+			names[i] += " (" + constraints[i].Description + ")"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// policyScore 方案的得分及其组成
+type policyScore struct {
+	score        float64
+	resourceCost float64
+	penaltyCost  float64
+	serviceScore float64
+}
+
 // evaluatePolicy 计算给定资源方案的得分
 func evaluatePolicy(res, podNum, reqs int64, qps float64, waitTime, serviceIntensity float64) float64 {
+	return scorePolicy(res, podNum, reqs, qps, waitTime, serviceIntensity).score
+}
+
+// scorePolicy 计算给定资源方案的得分及其组成
+func scorePolicy(res, podNum, reqs int64, qps float64, waitTime, serviceIntensity float64) policyScore {
 	// 如果出现无限排队 跳过
 	if serviceIntensity >= 1.0 {
 		klog.V(2).Infof("policy(cpuQuantity=%dm,podNum=%d,qps=%g,req/s=%d) maybe lead to infinite queueing, skipped this policy", res, podNum, qps, reqs)
-		return 0.0
+		return policyScore{}
 	}
 
 	serviceScore := queueRequests(reqs, podNum, qps, waitTime, serviceIntensity)
@@ -217,21 +602,23 @@ func evaluatePolicy(res, podNum, reqs int64, qps float64, waitTime, serviceInten
 		klog.V(4).Infof("policy(cpuQuantity=%dm,podNum=%d,req/s=%d,qps=%g,serviceIntensity=%g) with score(serviceScore=%g,resourceCost=%g,penaltyCost=%g,finalScore=%g)", res, podNum, reqs, qps, serviceIntensity, serviceScore, resCost, penaltyCost, score)
 	}
 
-	return score
+	return policyScore{score: score, resourceCost: resCost, penaltyCost: penaltyCost, serviceScore: serviceScore}
 }
 
 func calculateResourceCost(res int64, podNum int64) float64 {
 	// 资源量 * 单价
-	cost := float64(podNum*res) * cpuPrice
+	m := getModel()
+	cost := float64(podNum*res) * m.CpuPrice
 	// 最大最小归一化
-	return (resourceCostMax - cost) / (resourceCostMax - resourceCostMin)
+	return (m.resourceCostMax - cost) / (m.resourceCostMax - m.resourceCostMin)
 }
 
 // calculatePenaltyCost 计算违约成本
+// 按服务可用性的阈值从高到低匹配(map 的遍历顺序是随机的, 直接遍历会使同一方案的得分不确定)
 func calculatePenaltyCost(serviceScore float64) float64 {
-	for score, Cost := range servicePenaltyCostMap {
+	for _, score := range servicePenaltyThresholds {
 		if serviceScore >= score {
-			return Cost
+			return servicePenaltyCostMap[score]
 		}
 	}
 	return 1.0
@@ -239,7 +626,8 @@ func calculatePenaltyCost(serviceScore float64) float64 {
 
 // calculatePolicyScore 计算方案得分(归一化两个成本并乘以各自的权重)
 func calculatePolicyScore(resourceCost, penaltyCost float64) float64 {
-	return resourceCost*resourceCostRatio + penaltyCost*penaltyCostRatio
+	ratio := getModel().ResourceCostRatio
+	return resourceCost*ratio + penaltyCost*(1-ratio)
 }
 
 // queueRequests 通过 M/M/C 排队论模型，评估输入方案的服务可用性
@@ -261,3 +649,55 @@ func queueRequests(reqs, podNum int64, qps, waitTime float64, serviceIntensity f
 	serviceScore := (1 - math.Exp((waitTime/1000.0)*(qps-float64(podNum*reqs)))) * (100 * lengthQueue)
 	return serviceScore
 }
+
+// PodCapacity 返回 cpu 为 cpuMilli(m) 的副本每秒可处理的请求数
+// cpuMilli 不在搜索空间中(如: 被 policy、limit range 调整过)时, 使用不超过它的最大的 cpu 档位的处理能力
+func PodCapacity(cpuMilli int64) int64 {
+	var bestCpu, capacity int64
+	for cpu, reqs := range getModel().PodCapacities {
+		if cpu <= cpuMilli && cpu > bestCpu {
+			bestCpu, capacity = cpu, reqs
+		}
+	}
+	return capacity
+}
+
+// PlanCost 返回 podNum 个副本、每个副本 cpuMilli(m) 的方案每秒的资源成本
+func PlanCost(podNum, cpuMilli int64) float64 {
+	return float64(podNum*cpuMilli) / 1000.0 * getModel().CpuPrice
+}
+
+// WaitProbability 返回 M/M/c 模型下请求的排队时间超过 waitTimeMs 的概率(Erlang C)
+// podNum 个副本, 每个副本每秒可处理 podCapacity 个请求; 服务强度 >= 1 时返回 1
+func WaitProbability(podNum int64, podCapacity, qps, waitTimeMs float64) float64 {
+	if qps <= 0 {
+		return 0
+	}
+	if podNum <= 0 || podCapacity <= 0 || qps >= float64(podNum)*podCapacity {
+		return 1
+	}
+	// a = λ / μ, ρ = a / c
+	a := qps / podCapacity
+	serviceIntensity := a / float64(podNum)
+	// term 依次为 a^i / i!
+	term, sum := 1.0, 0.0
+	for i := int64(0); i < podNum; i += 1 {
+		sum += term
+		term *= a / float64(i+1)
+	}
+	last := term / (1 - serviceIntensity)
+	erlangC := last / (sum + last)
+	return erlangC * math.Exp(-(float64(podNum)*podCapacity-qps)*waitTimeMs/1000.0)
+}
+
+// ResponseTime 返回 M/M/c 模型下请求的平均响应时间(s), 即平均服务时间与平均排队时间之和
+// podNum 个副本, 每个副本每秒可处理 podCapacity 个请求; 服务强度 >= 1 时返回 +Inf
+func ResponseTime(podNum int64, podCapacity, qps float64) float64 {
+	if podNum <= 0 || podCapacity <= 0 || qps >= float64(podNum)*podCapacity {
+		return math.Inf(1)
+	}
+	if qps <= 0 {
+		return 1.0 / podCapacity
+	}
+	return 1.0/podCapacity + WaitProbability(podNum, podCapacity, qps, 0)/(float64(podNum)*podCapacity-qps)
+}
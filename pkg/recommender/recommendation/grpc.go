@@ -0,0 +1,268 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/metrics"
+	pluginApi "multidim-pod-autoscaler/pkg/recommender/plugin/v1"
+	"multidim-pod-autoscaler/pkg/recommender/util"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+const (
+	// GrpcAlgorithm 通过 gRPC 调用进程外推荐算法插件的算法名
+	GrpcAlgorithm = "grpc"
+
+	// 插件调用结果(metrics 的 result label)
+	pluginResultSuccess          = "success"
+	pluginResultRequestFailed    = "request_failed"
+	pluginResultCallFailed       = "call_failed"
+	pluginResultInvalidResponse  = "invalid_response"
+	pluginResultConstraintFailed = "constraint_rejected"
+)
+
+// grpcCalculator 通过 gRPC 调用进程外的推荐算法插件(见 pkg/recommender/plugin/v1/calculator.proto)
+// 调用失败(超时、返回不合法或不满足约束的方案)时使用 fallback 算法计算推荐方案
+type grpcCalculator struct {
+	metricsClient metrics.Client
+	client        pluginApi.CalculatorClient
+	timeout       time.Duration
+	fallback      Algorithm
+}
+
+// NewGrpcCalculator 返回调用 address 上的推荐算法插件的算法
+// 连接为非阻塞的明文连接(插件应与 recommender 部署在同一个 pod 或可信网络中)
+func NewGrpcCalculator(client metrics.Client, address string, timeout time.Duration, fallback Algorithm) (Algorithm, error) {
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial recommendation plugin %s: %v", address, err)
+	}
+	return &grpcCalculator{
+		metricsClient: client,
+		client:        pluginApi.NewCalculatorClient(conn),
+		timeout:       timeout,
+		fallback:      fallback,
+	}, nil
+}
+
+// Name 实现 Algorithm 接口
+func (c *grpcCalculator) Name() string {
+	return GrpcAlgorithm
+}
+
+// Version 实现 Algorithm 接口
+func (c *grpcCalculator) Version() string {
+	return "v1"
+}
+
+// Calculate 实现 Calculator 接口
+func (c *grpcCalculator) Calculate(
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+	constraints ...PlanConstraint,
+) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, error) {
+	res, action, decision, result, err := c.callPlugin(mpaWithSelector, controlledPod, constraints)
+	metrics.OnPluginCall(result)
+	if err == nil {
+		return res, action, decision, nil
+	}
+	klog.Warningf("recommendation plugin failed for MPA(%s/%s), fall back to %s: %v",
+		mpaWithSelector.Mpa.Namespace, mpaWithSelector.Mpa.Name, c.fallback.Name(), err)
+	// spec.algorithm.params 是插件的参数, fallback 算法使用默认参数(否则会因为未知字段而失败)
+	fallbackMpa := mpaWithSelector.Mpa.DeepCopy()
+	fallbackMpa.Spec.Algorithm = nil
+	res, action, decision, err = c.fallback.Calculate(
+		&utilMpa.MpaWithSelector{Mpa: fallbackMpa, Selector: mpaWithSelector.Selector}, controlledPod, constraints...)
+	if decision != nil {
+		decision.Reason = fmt.Sprintf("recommendation plugin failed, fell back to %s; %s", c.fallback.Name(), decision.Reason)
+	}
+	return res, action, decision, err
+}
+
+// callPlugin 调用插件计算推荐方案, 返回推荐方案、动作、决策记录、调用结果(metrics label)及错误
+func (c *grpcCalculator) callPlugin(
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+	constraints []PlanConstraint,
+) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, string, error) {
+	request, err := c.newRequest(mpaWithSelector, controlledPod, constraints)
+	if err != nil {
+		return nil, UnknownRecommendation, nil, pluginResultRequestFailed, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	response, err := c.client.Calculate(ctx, request)
+	if err != nil {
+		return nil, UnknownRecommendation, nil, pluginResultCallFailed, fmt.Errorf("call failed(%s): %v", status.Code(err), err)
+	}
+
+	// 插件不返回得分, 决策记录只包含观测到的 qps 及插件选择的方案
+	decision := &mpaTypes.RecommendationDecision{ObservedQps: formatDecimal(requestQps(request))}
+	switch response.Action {
+	case pluginApi.CalculateResponse_SKIP:
+		decision.Action, decision.Reason = mpaTypes.RecommendationDecisionKeep, "recommendation plugin kept the current plan"
+		return &mpaTypes.RecommendedResources{}, SkipRecommendation, decision, pluginResultSuccess, nil
+	case pluginApi.CalculateResponse_APPLY:
+	default:
+		return nil, UnknownRecommendation, nil, pluginResultInvalidResponse, fmt.Errorf("unknown action %s", response.Action)
+	}
+	res, err := fromPluginRecommendation(response.Recommendation)
+	if err != nil {
+		return nil, UnknownRecommendation, nil, pluginResultInvalidResponse, err
+	}
+	// 只检查应用到所有容器的 cpu(与内置算法的搜索空间相同)
+	if recommendation := res.ContainerRecommendations[0]; recommendation.ContainerName == mpaTypes.DefaultContainerResourcePolicy {
+		if cpu, existed := recommendation.Target[corev1.ResourceCPU]; existed {
+			if rejected := RejectedBy(int64(res.TargetPodNum), cpu.MilliValue(), constraints); len(rejected) > 0 {
+				return nil, UnknownRecommendation, nil, pluginResultConstraintFailed,
+					fmt.Errorf("plan %d pods × %s cpu is rejected by %s", res.TargetPodNum, cpu.String(), constraintNames(rejected))
+			}
+			decision.Chosen = &mpaTypes.PlanEvaluation{PodNum: res.TargetPodNum, Cpu: cpu}
+		}
+	}
+	decision.Action, decision.Reason = mpaTypes.RecommendationDecisionApply, "recommendation plugin provided a new plan"
+	return res, ApplyRecommendation, decision, pluginResultSuccess, nil
+}
+
+// requestQps 返回请求中所有 pod 的 qps 之和
+func requestQps(request *pluginApi.CalculateRequest) float64 {
+	var qps float64
+	for _, pod := range request.Pods {
+		for _, sample := range pod.Metrics {
+			if sample.Name == "http_requests" {
+				qps += sample.Value
+			}
+		}
+	}
+	return qps
+}
+
+// newRequest 构造插件的请求: MPA 的 spec、当前推荐方案、pods 及其 qps 样本、算法参数和约束
+func (c *grpcCalculator) newRequest(
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+	constraints []PlanConstraint,
+) (*pluginApi.CalculateRequest, error) {
+	mpa := mpaWithSelector.Mpa
+	spec, err := json.Marshal(mpa.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal MPA spec: %v", err)
+	}
+	request := &pluginApi.CalculateRequest{
+		Namespace: mpa.Namespace,
+		Name:      mpa.Name,
+		MpaSpec:   spec,
+	}
+	if mpa.Status.RecommendationResources != nil {
+		if request.CurrentRecommendation, err = json.Marshal(mpa.Status.RecommendationResources); err != nil {
+			return nil, fmt.Errorf("failed to marshal current recommendation: %v", err)
+		}
+	}
+	if mpa.Spec.Algorithm != nil && mpa.Spec.Algorithm.Params != nil {
+		request.AlgorithmParams = mpa.Spec.Algorithm.Params.Raw
+	}
+	for _, constraint := range constraints {
+		request.Constraints = append(request.Constraints, &pluginApi.Constraint{
+			Name:        constraint.Name,
+			Description: constraint.Description,
+		})
+	}
+
+	// metrics 获取失败时仍然调用插件(插件可以使用自己的数据源)
+	podsMetricsInfo, _, err :=
+		c.metricsClient.GetPodRawMetric("http_requests", mpa.Namespace, mpaWithSelector.Selector, labels.NewSelector())
+	if err != nil {
+		klog.Warningf("failed to get pods' qps for recommendation plugin: %v", err)
+	}
+	for _, pod := range controlledPod {
+		podSpec, err := json.Marshal(pod.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal spec of pod %s: %v", pod.Name, err)
+		}
+		pluginPod := &pluginApi.Pod{
+			Name: pod.Name,
+			Spec: podSpec,
+		}
+		if metricsInfo, exists := podsMetricsInfo[util.GetPodId(pod)]; exists {
+			pluginPod.Metrics = append(pluginPod.Metrics, &pluginApi.MetricSample{
+				Name:        "http_requests",
+				Value:       float64(metricsInfo.Value.MilliValue()) / 1000.0,
+				TimestampMs: metricsInfo.Timestamp.UnixNano() / int64(time.Millisecond),
+				WindowMs:    int64(metricsInfo.Window / time.Millisecond),
+			})
+		}
+		request.Pods = append(request.Pods, pluginPod)
+	}
+	return request, nil
+}
+
+// fromPluginRecommendation 将插件返回的推荐方案转换为 RecommendedResources
+func fromPluginRecommendation(recommendation *pluginApi.RecommendedResources) (*mpaTypes.RecommendedResources, error) {
+	if recommendation == nil || recommendation.TargetPodNum <= 0 || len(recommendation.ContainerRecommendations) == 0 {
+		return nil, fmt.Errorf("plugin returned an empty recommendation")
+	}
+	res := &mpaTypes.RecommendedResources{
+		TargetPodNum:         int(recommendation.TargetPodNum),
+		LowerBoundPodNum:     int(recommendation.LowerBoundPodNum),
+		UpperBoundPodNum:     int(recommendation.UpperBoundPodNum),
+		UncappedTargetPodNum: int(recommendation.UncappedTargetPodNum),
+	}
+	for _, container := range recommendation.ContainerRecommendations {
+		if container.ContainerName == "" {
+			return nil, fmt.Errorf("plugin returned a container recommendation without name")
+		}
+		target, err := toResourceList(container.Target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target of container %s: %v", container.ContainerName, err)
+		}
+		if len(target) == 0 {
+			return nil, fmt.Errorf("plugin returned an empty target for container %s", container.ContainerName)
+		}
+		lowerBound, err := toResourceList(container.LowerBound)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lower bound of container %s: %v", container.ContainerName, err)
+		}
+		upperBound, err := toResourceList(container.UpperBound)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upper bound of container %s: %v", container.ContainerName, err)
+		}
+		uncappedTarget, err := toResourceList(container.UncappedTarget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uncapped target of container %s: %v", container.ContainerName, err)
+		}
+		res.ContainerRecommendations = append(res.ContainerRecommendations, mpaTypes.RecommendedContainerResources{
+			ContainerName:  container.ContainerName,
+			Target:         target,
+			LowerBound:     lowerBound,
+			UpperBound:     upperBound,
+			UncappedTarget: uncappedTarget,
+		})
+	}
+	return res, nil
+}
+
+// toResourceList 将 资源名 -> Quantity 字符串 的映射转换为 ResourceList; 映射为空时返回 nil
+func toResourceList(resources map[string]string) (corev1.ResourceList, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	list := make(corev1.ResourceList, len(resources))
+	for name, value := range resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s=%q: %v", name, value, err)
+		}
+		list[corev1.ResourceName(name)] = quantity
+	}
+	return list, nil
+}
@@ -0,0 +1,89 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/metrics"
+	pluginApi "multidim-pod-autoscaler/pkg/recommender/plugin/v1"
+	"multidim-pod-autoscaler/pkg/recommender/util"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeMetricsClient 返回固定的 pods qps
+type fakeMetricsClient struct {
+	qps metrics.PodMetricsInfo
+}
+
+func (c *fakeMetricsClient) GetPodRawMetric(string, string, labels.Selector, labels.Selector) (metrics.PodMetricsInfo, time.Time, error) {
+	return c.qps, time.Now(), nil
+}
+
+// failingPluginClient 总是调用失败的插件
+type failingPluginClient struct{}
+
+func (c *failingPluginClient) Calculate(context.Context, *pluginApi.CalculateRequest, ...grpc.CallOption) (*pluginApi.CalculateResponse, error) {
+	return nil, fmt.Errorf("plugin unavailable")
+}
+
+func TestGrpcCalculatorFallback(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-0"}}
+	metricsClient := &fakeMetricsClient{qps: metrics.PodMetricsInfo{
+		util.GetPodId(pod): {Name: "http_requests", Timestamp: time.Now(), Window: time.Minute, Value: resource.MustParse("10")},
+	}}
+	calculator := &grpcCalculator{
+		metricsClient: metricsClient,
+		client:        &failingPluginClient{},
+		timeout:       time.Second,
+		fallback:      NewCalculator(metricsClient),
+	}
+
+	tests := []struct {
+		name      string
+		algorithm *mpaTypes.RecommendationAlgorithmSpec
+	}{
+		{
+			name:      "plugin without params",
+			algorithm: &mpaTypes.RecommendationAlgorithmSpec{Name: GrpcAlgorithm},
+		},
+		{
+			name: "plugin params unknown to the fallback",
+			algorithm: &mpaTypes.RecommendationAlgorithmSpec{
+				Name:   GrpcAlgorithm,
+				Params: &runtime.RawExtension{Raw: []byte(`{"qpsPerPod": 20, "cpu": "500m"}`)},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mpa := &mpaTypes.MultidimPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+				Spec:       mpaTypes.MultidimPodAutoscalerSpec{Algorithm: tc.algorithm},
+			}
+			res, action, decision, err := calculator.Calculate(
+				&utilMpa.MpaWithSelector{Mpa: mpa, Selector: labels.Everything()}, []*corev1.Pod{pod})
+			if err != nil {
+				t.Fatalf("expected the fallback to succeed, got %v", err)
+			}
+			if action != ApplyRecommendation || res == nil || res.TargetPodNum <= 0 {
+				t.Errorf("expected a new plan from the fallback, got %s %v", action, res)
+			}
+			if decision == nil || !strings.HasPrefix(decision.Reason, "recommendation plugin failed") {
+				t.Errorf("expected the decision to record the plugin failure, got %v", decision)
+			}
+			if mpa.Spec.Algorithm != tc.algorithm {
+				t.Errorf("expected the MPA spec not to be modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package recommendation
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	configv1alpha1 "multidim-pod-autoscaler/pkg/apis/config/v1alpha1"
+)
+
+// maxPodNumLimit 搜索空间中副本数的上限(受 M/M/c 模型中阶乘常量表长度的限制)
+const maxPodNumLimit int64 = 16
+
+// Model 推荐算法(成本模型)的可配置参数, 可以通过 SetModel 在运行时替换
+type Model struct {
+	// PodCapacities 每个 cpu 档位(m)的副本每秒可处理的请求数, 同时决定了搜索空间中的 cpu
+	PodCapacities map[int64]int64
+	// MaxPodNum 搜索空间中的最大副本数(1 ~ 16)
+	MaxPodNum int64
+	// CpuPrice cpu 单价 vCore/s
+	CpuPrice float64
+	// ResourceCostRatio 资源成本在方案得分中的占比, 违约成本的占比为 1 - ResourceCostRatio
+	ResourceCostRatio float64
+	// UpdateThreshold 新方案的得分超出当前方案得分的比例超过该阈值时才更新推荐方案
+	UpdateThreshold float64
+	// DefaultResponseTimeMs MPA 未指定 expRespTime 时请求的期望响应时间(ms)
+	DefaultResponseTimeMs int
+	// PreferenceWeight 约束的偏好程度对方案得分的影响权重
+	PreferenceWeight float64
+	// NearOptimalScoreRatio 得分不低于最优方案得分 (1 - ratio) 的方案视为近似最优, 用于计算推荐方案的上下界
+	NearOptimalScoreRatio float64
+	// QpsMinUncertainty qps 估计值的最小相对误差, 用于计算推荐方案的上下界
+	QpsMinUncertainty float64
+	// DefaultTargetUtilization target-utilization 算法默认的目标利用率
+	DefaultTargetUtilization float64
+}
+
+// DefaultModel 返回默认的模型参数
+func DefaultModel() Model {
+	return Model{
+		PodCapacities: map[int64]int64{
+			250:  6,
+			500:  12,
+			750:  20,
+			1000: 26,
+			1250: 34,
+			1500: 40,
+			1750: 46,
+			2000: 52,
+			2250: 60,
+		},
+		MaxPodNum:                16,
+		CpuPrice:                 0.00003334,
+		ResourceCostRatio:        0.6,
+		UpdateThreshold:          0.1,
+		DefaultResponseTimeMs:    300,
+		PreferenceWeight:         0.05,
+		NearOptimalScoreRatio:    0.05,
+		QpsMinUncertainty:        0.1,
+		DefaultTargetUtilization: 0.7,
+	}
+}
+
+// model 当前使用的模型参数及由其计算得到的值
+type model struct {
+	Model
+	// cpuLevels PodCapacities 中的 cpu(升序)
+	cpuLevels []int64
+	// resourceCostMax、resourceCostMin 资源成本的最值(用于归一化)
+	resourceCostMax float64
+	resourceCostMin float64
+}
+
+// currentModel 保存 *model
+var currentModel atomic.Value
+
+func init() {
+	if err := SetModel(DefaultModel()); err != nil {
+		panic(err)
+	}
+}
+
+// ModelFromConfiguration 将配置文件中的成本模型转换为 Model, 未指定的字段使用 DefaultModel 中的值
+// 返回的 Model 已通过校验
+func ModelFromConfiguration(c *configv1alpha1.CostModelConfiguration) (Model, error) {
+	m := DefaultModel()
+	if len(c.PodCapacities) > 0 {
+		m.PodCapacities = make(map[int64]int64, len(c.PodCapacities))
+		for _, capacity := range c.PodCapacities {
+			m.PodCapacities[capacity.CPU.MilliValue()] = capacity.QPS
+		}
+	}
+	if c.MaxReplicas != 0 {
+		m.MaxPodNum = c.MaxReplicas
+	}
+	if c.CpuPrice != 0 {
+		m.CpuPrice = c.CpuPrice
+	}
+	if c.ResourceCostRatio != nil {
+		m.ResourceCostRatio = *c.ResourceCostRatio
+	}
+	if c.UpdateThreshold != nil {
+		m.UpdateThreshold = *c.UpdateThreshold
+	}
+	if c.DefaultResponseTime.Duration != 0 {
+		m.DefaultResponseTimeMs = int(c.DefaultResponseTime.Duration / time.Millisecond)
+	}
+	if c.PreferenceWeight != nil {
+		m.PreferenceWeight = *c.PreferenceWeight
+	}
+	if c.NearOptimalScoreRatio != nil {
+		m.NearOptimalScoreRatio = *c.NearOptimalScoreRatio
+	}
+	if c.QpsMinUncertainty != nil {
+		m.QpsMinUncertainty = *c.QpsMinUncertainty
+	}
+	if c.DefaultTargetUtilization != 0 {
+		m.DefaultTargetUtilization = c.DefaultTargetUtilization
+	}
+	if _, err := newModel(m); err != nil {
+		return Model{}, err
+	}
+	return m, nil
+}
+
+// SetModel 替换推荐算法使用的模型参数, 参数不合法时返回 error 且不做替换
+// 之后开始的计算使用新的参数
+func SetModel(m Model) error {
+	current, err := newModel(m)
+	if err != nil {
+		return err
+	}
+	currentModel.Store(current)
+	return nil
+}
+
+// newModel 校验 m 并计算 model 中的其他值
+func newModel(m Model) (*model, error) {
+	if len(m.PodCapacities) == 0 {
+		return nil, fmt.Errorf("no pod capacity is specified")
+	}
+	if m.MaxPodNum < 1 || m.MaxPodNum > maxPodNumLimit {
+		return nil, fmt.Errorf("max pod num %d must be in [1, %d]", m.MaxPodNum, maxPodNumLimit)
+	}
+	capacities := make(map[int64]int64, len(m.PodCapacities))
+	levels := make([]int64, 0, len(m.PodCapacities))
+	for cpu, qps := range m.PodCapacities {
+		if cpu <= 0 || qps <= 0 {
+			return nil, fmt.Errorf("invalid pod capacity %dm: %d qps", cpu, qps)
+		}
+		capacities[cpu] = qps
+		levels = append(levels, cpu)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	if m.MaxPodNum == podNumMin && len(levels) == 1 {
+		return nil, fmt.Errorf("the search space contains only one plan")
+	}
+	if m.DefaultResponseTimeMs <= 0 {
+		return nil, fmt.Errorf("default response time must be positive")
+	}
+	if m.CpuPrice <= 0 {
+		return nil, fmt.Errorf("cpu price must be positive")
+	}
+	if m.ResourceCostRatio < 0 || m.ResourceCostRatio > 1 {
+		return nil, fmt.Errorf("resource cost ratio %g must be in [0, 1]", m.ResourceCostRatio)
+	}
+	if m.DefaultTargetUtilization <= 0 || m.DefaultTargetUtilization > 1 {
+		return nil, fmt.Errorf("default target utilization %g must be in (0, 1]", m.DefaultTargetUtilization)
+	}
+	m.PodCapacities = capacities
+
+	return &model{
+		Model:           m,
+		cpuLevels:       levels,
+		resourceCostMax: float64(m.MaxPodNum*levels[len(levels)-1]) * m.CpuPrice,
+		resourceCostMin: float64(podNumMin*levels[0]) * m.CpuPrice,
+	}, nil
+}
+
+// getModel 返回当前的模型参数
+func getModel() *model {
+	return currentModel.Load().(*model)
+}
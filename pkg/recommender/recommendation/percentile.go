@@ -0,0 +1,261 @@
+package recommendation
+
+import (
+	"fmt"
+	"math"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/metrics"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+const (
+	// defaultPercentile 推荐方案使用的 qps 分位数
+	defaultPercentile = 0.95
+	// defaultLowerPercentile、defaultUpperPercentile 推荐方案上下界使用的 qps 分位数
+	defaultLowerPercentile = 0.5
+	defaultUpperPercentile = 0.99
+	// defaultPercentileUtilization 按分位数 qps 计算方案时副本的目标利用率
+	defaultPercentileUtilization = 0.8
+	// defaultHistogramHalfLife qps 样本权重的默认半衰期
+	defaultHistogramHalfLife = time.Hour
+
+	// 直方图桶的划分: 第一个桶的宽度为 histogramFirstBucketSize, 之后每个桶的宽度按 histogramBucketRatio 递增
+	histogramFirstBucketSize = 0.1
+	histogramBucketRatio     = 1.05
+	histogramMaxQps          = 1e6
+	// histogramMaxDecayExponent 权重的指数超过该值时重新归一化, 避免浮点数溢出
+	histogramMaxDecayExponent = 100
+	// histogramExpireHalfLives 超过该数量的半衰期未更新的直方图(如 MPA 已删除)会被清理
+	histogramExpireHalfLives = 24
+)
+
+// percentileHistogramCalculator 基于 qps 衰减直方图分位数的推荐算法
+// 每次计算时将服务的 qps 加入该 MPA 的直方图(样本权重按半衰期指数衰减), 按分位数 qps 计算满足目标利用率的方案
+// 直方图保存在内存中, recommender 重启后重新积累
+type percentileHistogramCalculator struct {
+	metricsClient metrics.Client
+	clock         clock.Clock
+
+	lock sync.Mutex
+	// histograms MPA("namespace/name") -> qps 直方图
+	histograms map[string]*decayingHistogram
+}
+
+// percentileHistogramParams 分位数直方图算法的参数
+type percentileHistogramParams struct {
+	// Percentile 推荐方案使用的 qps 分位数, (0, 1]
+	Percentile float64 `json:"percentile,omitempty"`
+	// LowerPercentile、UpperPercentile 推荐方案上下界使用的 qps 分位数
+	LowerPercentile float64 `json:"lowerPercentile,omitempty"`
+	UpperPercentile float64 `json:"upperPercentile,omitempty"`
+	// TargetUtilization 按分位数 qps 计算方案时副本的目标利用率, (0, 1]
+	TargetUtilization float64 `json:"targetUtilization,omitempty"`
+	// HalfLife qps 样本权重的半衰期(如: "1h")
+	HalfLife string `json:"halfLife,omitempty"`
+
+	halfLife time.Duration
+}
+
+// validate 校验参数并设置默认值
+func (p *percentileHistogramParams) validate() error {
+	if p.Percentile == 0 {
+		p.Percentile = defaultPercentile
+	}
+	if p.LowerPercentile == 0 {
+		p.LowerPercentile = math.Min(defaultLowerPercentile, p.Percentile)
+	}
+	if p.UpperPercentile == 0 {
+		p.UpperPercentile = math.Max(defaultUpperPercentile, p.Percentile)
+	}
+	if p.TargetUtilization == 0 {
+		p.TargetUtilization = defaultPercentileUtilization
+	}
+	p.halfLife = defaultHistogramHalfLife
+	if p.HalfLife != "" {
+		halfLife, err := time.ParseDuration(p.HalfLife)
+		if err != nil || halfLife <= 0 {
+			return fmt.Errorf("halfLife must be a positive duration, got %q", p.HalfLife)
+		}
+		p.halfLife = halfLife
+	}
+	if p.LowerPercentile <= 0 || p.LowerPercentile > p.Percentile || p.Percentile > p.UpperPercentile || p.UpperPercentile > 1 {
+		return fmt.Errorf("percentiles must satisfy 0 < lowerPercentile <= percentile <= upperPercentile <= 1, got %g, %g, %g",
+			p.LowerPercentile, p.Percentile, p.UpperPercentile)
+	}
+	if p.TargetUtilization <= 0 || p.TargetUtilization > 1 {
+		return fmt.Errorf("targetUtilization must be in (0, 1], got %g", p.TargetUtilization)
+	}
+	return nil
+}
+
+// NewPercentileHistogramCalculator 返回分位数直方图推荐算法
+func NewPercentileHistogramCalculator(client metrics.Client) Algorithm {
+	return NewPercentileHistogramCalculatorWithClock(client, clock.RealClock{})
+}
+
+// NewPercentileHistogramCalculatorWithClock 返回使用 clock 作为样本时间的分位数直方图推荐算法(如: 离线模拟时使用 qps 序列的时间)
+func NewPercentileHistogramCalculatorWithClock(client metrics.Client, clock clock.Clock) Algorithm {
+	return &percentileHistogramCalculator{
+		metricsClient: client,
+		clock:         clock,
+		histograms:    make(map[string]*decayingHistogram),
+	}
+}
+
+// Name 实现 Algorithm 接口
+func (c *percentileHistogramCalculator) Name() string {
+	return PercentileHistogramAlgorithm
+}
+
+// Version 实现 Algorithm 接口
+func (c *percentileHistogramCalculator) Version() string {
+	return "v1"
+}
+
+// Calculate 实现 Calculator 接口
+func (c *percentileHistogramCalculator) Calculate(
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+	constraints ...PlanConstraint,
+) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, error) {
+	params := percentileHistogramParams{}
+	if err := DecodeParams(mpaWithSelector.Mpa, &params); err != nil {
+		return nil, UnknownRecommendation, nil, err
+	}
+	if err := params.validate(); err != nil {
+		return nil, UnknownRecommendation, nil, fmt.Errorf("invalid params of recommendation algorithm %q: %v", c.Name(), err)
+	}
+	serviceQps, _, resourceFormat, err := getServiceQps(c.metricsClient, mpaWithSelector, controlledPod)
+	if err != nil {
+		return nil, UnknownRecommendation, nil, err
+	}
+
+	key := mpaWithSelector.Mpa.Namespace + "/" + mpaWithSelector.Mpa.Name
+	now := c.clock.Now()
+	c.lock.Lock()
+	histogram := c.histogramFor(key, params.halfLife, now)
+	histogram.addSample(serviceQps, now)
+	qps := []float64{
+		histogram.percentile(params.Percentile),
+		histogram.percentile(params.LowerPercentile),
+		histogram.percentile(params.UpperPercentile),
+	}
+	c.lock.Unlock()
+
+	res, action, decision, err := sizeForUtilization(
+		mpaWithSelector.Mpa,
+		qps,
+		[]float64{params.TargetUtilization, params.TargetUtilization, params.TargetUtilization},
+		resourceFormat,
+		constraints,
+	)
+	if decision != nil {
+		// 方案按分位数 qps 计算, 决策记录中保留实际观测到的 qps
+		decision.ObservedQps = formatDecimal(serviceQps)
+		decision.Reason = fmt.Sprintf("sized for p%g qps %s; %s", params.Percentile*100, formatDecimal(qps[0]), decision.Reason)
+	}
+	return res, action, decision, err
+}
+
+// histogramFor 返回 key 对应的直方图(半衰期改变时重建), 同时清理过期的直方图
+// 调用者需持有 c.lock
+func (c *percentileHistogramCalculator) histogramFor(key string, halfLife time.Duration, now time.Time) *decayingHistogram {
+	for k, histogram := range c.histograms {
+		if now.Sub(histogram.lastUpdate) > histogramExpireHalfLives*histogram.halfLife {
+			delete(c.histograms, k)
+		}
+	}
+	histogram, existed := c.histograms[key]
+	if !existed || histogram.halfLife != halfLife {
+		histogram = newDecayingHistogram(halfLife, now)
+		c.histograms[key] = histogram
+	}
+	return histogram
+}
+
+// decayingHistogram 样本权重按半衰期指数衰减的直方图
+// 为避免修改所有已有的权重, 新样本的权重按 2^((t - reference) / halfLife) 递增, 效果与旧样本权重衰减相同
+type decayingHistogram struct {
+	halfLife   time.Duration
+	reference  time.Time
+	lastUpdate time.Time
+	// weights 每个桶的权重; 桶 i 的范围为 [bucketStart(i), bucketStart(i+1))
+	weights     []float64
+	totalWeight float64
+}
+
+// newDecayingHistogram 构造空的直方图
+func newDecayingHistogram(halfLife time.Duration, now time.Time) *decayingHistogram {
+	return &decayingHistogram{
+		halfLife:   halfLife,
+		reference:  now,
+		lastUpdate: now,
+		weights:    make([]float64, histogramBucketIndex(histogramMaxQps)+1),
+	}
+}
+
+// addSample 加入时刻为 now 的 qps 样本
+func (h *decayingHistogram) addSample(qps float64, now time.Time) {
+	exponent := float64(now.Sub(h.reference)) / float64(h.halfLife)
+	if exponent > histogramMaxDecayExponent {
+		// 重新归一化: 将参考时间移动到 now
+		scale := math.Pow(2, -exponent)
+		for i := range h.weights {
+			h.weights[i] *= scale
+		}
+		h.totalWeight *= scale
+		h.reference = now
+		exponent = 0
+	}
+	weight := math.Pow(2, exponent)
+	h.weights[histogramBucketIndex(qps)] += weight
+	h.totalWeight += weight
+	h.lastUpdate = now
+}
+
+// percentile 返回分位数 p 对应的 qps(所在桶的上界); 直方图为空时返回 0
+func (h *decayingHistogram) percentile(p float64) float64 {
+	if h.totalWeight <= 0 {
+		return 0
+	}
+	threshold := p * h.totalWeight
+	var cumulative float64
+	last := 0
+	for i, weight := range h.weights {
+		if weight <= 0 {
+			continue
+		}
+		cumulative += weight
+		last = i
+		if cumulative >= threshold {
+			return histogramBucketStart(i + 1)
+		}
+	}
+	// 浮点误差导致累计权重略小于 threshold 时, 返回最后一个非空桶
+	return histogramBucketStart(last + 1)
+}
+
+// histogramBucketStart 返回桶 i 的下界
+func histogramBucketStart(i int) float64 {
+	if i <= 0 {
+		return 0
+	}
+	return histogramFirstBucketSize * (math.Pow(histogramBucketRatio, float64(i)) - 1) / (histogramBucketRatio - 1)
+}
+
+// histogramBucketIndex 返回 qps 所在的桶
+func histogramBucketIndex(qps float64) int {
+	if qps <= 0 {
+		return 0
+	}
+	if qps > histogramMaxQps {
+		qps = histogramMaxQps
+	}
+	// bucketStart(i) <= qps  <=>  i <= log(1 + qps * (ratio - 1) / firstBucketSize) / log(ratio)
+	return int(math.Floor(math.Log(1+qps*(histogramBucketRatio-1)/histogramFirstBucketSize) / math.Log(histogramBucketRatio)))
+}
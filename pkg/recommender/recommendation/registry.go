@@ -0,0 +1,105 @@
+package recommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// MMCAlgorithm 基于 M/M/c 排队论模型的推荐算法(默认)
+	MMCAlgorithm = "mmc"
+	// TargetUtilizationAlgorithm 使副本的利用率(qps / 副本的处理能力)接近目标值的推荐算法
+	TargetUtilizationAlgorithm = "target-utilization"
+	// PercentileHistogramAlgorithm 基于 qps 衰减直方图分位数的推荐算法
+	PercentileHistogramAlgorithm = "percentile-histogram"
+)
+
+// Algorithm 为可注册到 Registry 的推荐算法
+type Algorithm interface {
+	Calculator
+	// Name 算法名, 对应 MPA 的 spec.algorithm.name
+	Name() string
+	// Version 算法版本, 与算法名一起记录在 MPA 的状态中
+	Version() string
+}
+
+// Registry 保存所有可用的推荐算法, 根据 MPA 的 spec.algorithm 选择算法
+type Registry struct {
+	algorithms map[string]Algorithm
+
+	lock             sync.RWMutex
+	defaultAlgorithm string
+}
+
+// NewRegistry 使用 algorithms 构造 Registry
+// defaultAlgorithm 为 MPA 未指定算法时使用的算法名, 必须已注册
+func NewRegistry(defaultAlgorithm string, algorithms ...Algorithm) (*Registry, error) {
+	r := &Registry{
+		algorithms:       make(map[string]Algorithm, len(algorithms)),
+		defaultAlgorithm: defaultAlgorithm,
+	}
+	for _, algorithm := range algorithms {
+		if _, existed := r.algorithms[algorithm.Name()]; existed {
+			return nil, fmt.Errorf("recommendation algorithm %q registered twice", algorithm.Name())
+		}
+		r.algorithms[algorithm.Name()] = algorithm
+	}
+	if _, existed := r.algorithms[defaultAlgorithm]; !existed {
+		return nil, fmt.Errorf("unknown default recommendation algorithm %q, available: %s", defaultAlgorithm, r.names())
+	}
+	return r, nil
+}
+
+// SetDefault 修改 MPA 未指定算法时使用的算法, 算法未注册时返回 error 且不做修改
+func (r *Registry) SetDefault(defaultAlgorithm string) error {
+	if _, existed := r.algorithms[defaultAlgorithm]; !existed {
+		return fmt.Errorf("unknown default recommendation algorithm %q, available: %s", defaultAlgorithm, r.names())
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.defaultAlgorithm = defaultAlgorithm
+	return nil
+}
+
+// Get 返回 mpa 选择的推荐算法; 未指定时返回默认算法
+func (r *Registry) Get(mpa *mpaTypes.MultidimPodAutoscaler) (Algorithm, error) {
+	r.lock.RLock()
+	name := r.defaultAlgorithm
+	r.lock.RUnlock()
+	if mpa.Spec.Algorithm != nil && mpa.Spec.Algorithm.Name != "" {
+		name = mpa.Spec.Algorithm.Name
+	}
+	algorithm, existed := r.algorithms[name]
+	if !existed {
+		return nil, fmt.Errorf("unknown recommendation algorithm %q, available: %s", name, r.names())
+	}
+	return algorithm, nil
+}
+
+// names 返回所有已注册的算法名(逗号分隔)
+func (r *Registry) names() string {
+	names := make([]string, 0, len(r.algorithms))
+	for name := range r.algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// DecodeParams 将 mpa 的算法参数(JSON)解析到 params 中, 未指定参数时保持 params 不变
+// 不允许未知的参数, 避免参数名拼写错误时被静默忽略
+func DecodeParams(mpa *mpaTypes.MultidimPodAutoscaler, params interface{}) error {
+	if mpa.Spec.Algorithm == nil || mpa.Spec.Algorithm.Params == nil || len(mpa.Spec.Algorithm.Params.Raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(mpa.Spec.Algorithm.Params.Raw)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return fmt.Errorf("invalid params of recommendation algorithm %q: %v", mpa.Spec.Algorithm.Name, err)
+	}
+	return nil
+}
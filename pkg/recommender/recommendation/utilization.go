@@ -0,0 +1,222 @@
+package recommendation
+
+import (
+	"fmt"
+	"math"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/recommender/metrics"
+	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
+)
+
+// 副本的默认目标利用率见 Model.DefaultTargetUtilization
+const (
+	// defaultUtilizationTolerance 利用率的默认容忍范围, 用于计算推荐方案的上下界
+	defaultUtilizationTolerance = 0.1
+)
+
+// targetUtilizationCalculator 使副本的利用率(qps / 副本的处理能力)不超过目标值的推荐算法
+// 副本的处理能力由其 cpu 决定(Model.PodCapacities), 在满足目标利用率的方案中选择总 cpu 最少的方案
+type targetUtilizationCalculator struct {
+	metricsClient metrics.Client
+}
+
+// targetUtilizationParams 目标利用率算法的参数
+type targetUtilizationParams struct {
+	// TargetUtilization 目标利用率, (0, 1)
+	TargetUtilization float64 `json:"targetUtilization,omitempty"`
+	// Tolerance 利用率的容忍范围, 利用率在 [target - tolerance, target + tolerance] 内的方案无需更新
+	Tolerance float64 `json:"tolerance,omitempty"`
+}
+
+// validate 校验参数并设置默认值
+func (p *targetUtilizationParams) validate() error {
+	if p.TargetUtilization == 0 {
+		p.TargetUtilization = getModel().DefaultTargetUtilization
+	}
+	if p.Tolerance == 0 {
+		p.Tolerance = defaultUtilizationTolerance
+	}
+	if p.TargetUtilization <= 0 || p.TargetUtilization >= 1 {
+		return fmt.Errorf("targetUtilization must be in (0, 1), got %g", p.TargetUtilization)
+	}
+	if p.Tolerance < 0 || p.Tolerance >= p.TargetUtilization {
+		return fmt.Errorf("tolerance must be in [0, targetUtilization), got %g", p.Tolerance)
+	}
+	return nil
+}
+
+// NewTargetUtilizationCalculator 返回目标利用率推荐算法
+func NewTargetUtilizationCalculator(client metrics.Client) Algorithm {
+	return &targetUtilizationCalculator{
+		metricsClient: client,
+	}
+}
+
+// Name 实现 Algorithm 接口
+func (c *targetUtilizationCalculator) Name() string {
+	return TargetUtilizationAlgorithm
+}
+
+// Version 实现 Algorithm 接口
+func (c *targetUtilizationCalculator) Version() string {
+	return "v1"
+}
+
+// Calculate 实现 Calculator 接口
+func (c *targetUtilizationCalculator) Calculate(
+	mpaWithSelector *utilMpa.MpaWithSelector,
+	controlledPod []*corev1.Pod,
+	constraints ...PlanConstraint,
+) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, error) {
+	params := targetUtilizationParams{}
+	if err := DecodeParams(mpaWithSelector.Mpa, &params); err != nil {
+		return nil, UnknownRecommendation, nil, err
+	}
+	if err := params.validate(); err != nil {
+		return nil, UnknownRecommendation, nil, fmt.Errorf("invalid params of recommendation algorithm %q: %v", c.Name(), err)
+	}
+	serviceQps, _, resourceFormat, err := getServiceQps(c.metricsClient, mpaWithSelector, controlledPod)
+	if err != nil {
+		return nil, UnknownRecommendation, nil, err
+	}
+
+	// 利用率较高(较低)的方案作为推荐方案的下界(上界)
+	return sizeForUtilization(
+		mpaWithSelector.Mpa,
+		[]float64{serviceQps, serviceQps, serviceQps},
+		[]float64{params.TargetUtilization, params.TargetUtilization + params.Tolerance, params.TargetUtilization - params.Tolerance},
+		resourceFormat,
+		constraints,
+	)
+}
+
+// sizeForUtilization 按 qps[0] 与 utilization[0] 计算推荐方案, 其余 (qps, utilization) 对应的方案用于扩展上下界
+// 旧方案不存在、不满足约束或不在新方案的上下界内时应用新方案
+// 决策记录中的方案按总 cpu 排序, 利用率按 qps[0] 计算
+func sizeForUtilization(
+	mpa *mpaTypes.MultidimPodAutoscaler,
+	qps, utilization []float64,
+	resourceFormat resource.Format,
+	constraints []PlanConstraint,
+) (*mpaTypes.RecommendedResources, RecommendationAction, *mpaTypes.RecommendationDecision, error) {
+	uncappedPodNum, uncappedPodResource := utilizationPlan(qps[0], utilization[0])
+	targetPodNum, targetPodResource := utilizationPlan(qps[0], utilization[0], constraints...)
+	if targetPodNum == 0 {
+		return nil, UnknownRecommendation, nil, fmt.Errorf("no plan satisfies the constraints(the best plan %d pods × %dm is rejected by %s)",
+			uncappedPodNum, uncappedPodResource, constraintNames(RejectedBy(uncappedPodNum, uncappedPodResource, constraints)))
+	}
+	bounds := newPlanBounds(targetPodNum, targetPodResource)
+	for i := 1; i < len(qps); i += 1 {
+		if podNum, podResource := utilizationPlan(qps[i], utilization[i], constraints...); podNum > 0 {
+			bounds.add(podNum, podResource)
+		}
+	}
+	klog.V(4).Infof("final policy(qps=%g, utilization=%g): instance number=%d, instance resources=%dm, pods in [%d, %d], cpu in [%dm, %dm]",
+		qps[0], utilization[0], targetPodNum, targetPodResource, bounds.minPodNum, bounds.maxPodNum, bounds.minCpu, bounds.maxCpu)
+
+	decision := &mpaTypes.RecommendationDecision{
+		ObservedQps: formatDecimal(qps[0]),
+		Chosen:      utilizationEvaluation(targetPodNum, targetPodResource, qps[0], resourceFormat),
+	}
+	for _, candidate := range utilizationCandidates(qps[0], utilization[0], constraints) {
+		if len(decision.Alternatives) >= decisionAlternatives {
+			break
+		}
+		if candidate[0] != targetPodNum || candidate[1] != targetPodResource {
+			decision.Alternatives = append(decision.Alternatives, *utilizationEvaluation(candidate[0], candidate[1], qps[0], resourceFormat))
+		}
+	}
+
+	podNum, cpu, existed := oldPlan(mpa)
+	if !existed {
+		decision.Action, decision.Reason = mpaTypes.RecommendationDecisionApply, "no current recommendation"
+	} else {
+		current := planString(podNum, cpu)
+		decision.Current = utilizationEvaluation(podNum, cpu, qps[0], resourceFormat)
+		if rejected := RejectedBy(podNum, cpu, constraints); len(rejected) > 0 {
+			decision.Action = mpaTypes.RecommendationDecisionApply
+			decision.Reason = fmt.Sprintf("current plan %s is rejected by %s", current, constraintNames(rejected))
+		} else if podNum >= bounds.minPodNum && podNum <= bounds.maxPodNum && cpu >= bounds.minCpu && cpu <= bounds.maxCpu {
+			decision.Action = mpaTypes.RecommendationDecisionKeep
+			decision.Reason = fmt.Sprintf("current plan %s is within the bounds (pods in [%d, %d], cpu in [%dm, %dm]) of the best plan %s",
+				current, bounds.minPodNum, bounds.maxPodNum, bounds.minCpu, bounds.maxCpu, planString(targetPodNum, targetPodResource))
+			return &mpaTypes.RecommendedResources{}, SkipRecommendation, decision, nil
+		} else {
+			decision.Action = mpaTypes.RecommendationDecisionApply
+			decision.Reason = fmt.Sprintf("current plan %s is outside the bounds (pods in [%d, %d], cpu in [%dm, %dm]) of the best plan %s",
+				current, bounds.minPodNum, bounds.maxPodNum, bounds.minCpu, bounds.maxCpu, planString(targetPodNum, targetPodResource))
+		}
+	}
+	return newRecommendedResources(targetPodNum, targetPodResource, uncappedPodNum, uncappedPodResource, bounds, resourceFormat),
+		ApplyRecommendation, decision, nil
+}
+
+// utilizationEvaluation 返回方案在 qps 下的利用率(用于决策记录)
+func utilizationEvaluation(podNum, cpu int64, qps float64, format resource.Format) *mpaTypes.PlanEvaluation {
+	evaluation := &mpaTypes.PlanEvaluation{
+		PodNum: int(podNum),
+		Cpu:    *resource.NewMilliQuantity(cpu, format),
+	}
+	if capacity := podNum * PodCapacity(cpu); capacity > 0 {
+		evaluation.Utilization = formatDecimal(qps / float64(capacity))
+	}
+	return evaluation
+}
+
+// utilizationCandidates 返回每个 cpu 下利用率不超过 utilization 且满足 constraints 的最少副本的方案(副本数, cpu)
+// 按总 cpu 升序排序(总 cpu 相同时副本数少的在前)
+func utilizationCandidates(qps, utilization float64, constraints []PlanConstraint) [][2]int64 {
+	m := getModel()
+	candidates := make([][2]int64, 0, len(m.cpuLevels))
+	for _, cpu := range m.cpuLevels {
+		reqs := m.PodCapacities[cpu]
+		podNum := int64(math.Ceil(qps / (float64(reqs) * utilization)))
+		if podNum < podNumMin {
+			podNum = podNumMin
+		}
+		for ; podNum <= m.MaxPodNum; podNum += 1 {
+			if len(RejectedBy(podNum, cpu, constraints)) == 0 {
+				candidates = append(candidates, [2]int64{podNum, cpu})
+				break
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := candidates[i][0]*candidates[i][1], candidates[j][0]*candidates[j][1]
+		if ci != cj {
+			return ci < cj
+		}
+		return candidates[i][0] < candidates[j][0]
+	})
+	return candidates
+}
+
+// utilizationPlan 返回利用率不超过 utilization 的方案中总 cpu 最少的方案(总 cpu 相同时选择副本数较少的方案)
+// 不满足 constraints 的方案不参与选择; 没有可行方案时返回的副本数为 0
+// 负载超出搜索空间的处理能力时, 返回处理能力最大的可行方案
+func utilizationPlan(qps, utilization float64, constraints ...PlanConstraint) (int64, int64) {
+	if candidates := utilizationCandidates(qps, utilization, constraints); len(candidates) > 0 {
+		return candidates[0][0], candidates[0][1]
+	}
+
+	// 负载超出处理能力时的兜底方案
+	m := getModel()
+	var maxPodNum, maxCpu, maxCapacity int64
+	for _, cpu := range m.cpuLevels {
+		reqs := m.PodCapacities[cpu]
+		for podNum := m.MaxPodNum; podNum >= podNumMin; podNum -= 1 {
+			if len(RejectedBy(podNum, cpu, constraints)) == 0 {
+				if podNum*reqs > maxCapacity || (podNum*reqs == maxCapacity && cpu < maxCpu) {
+					maxPodNum, maxCpu, maxCapacity = podNum, cpu, podNum*reqs
+				}
+				break
+			}
+		}
+	}
+	return maxPodNum, maxCpu
+}
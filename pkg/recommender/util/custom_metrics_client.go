@@ -15,15 +15,15 @@ const (
 	discoveryResetPeriod = 5 * time.Minute
 )
 
-// NewCustomMetricsClient 返回一个新的 CustomMetricsClient
-func NewCustomMetricsClient(config *rest.Config) custom_metrics.CustomMetricsClient {
+// NewCustomMetricsClient 返回一个新的 CustomMetricsClient, stopCh 关闭时停止定期重置 REST mapper
+func NewCustomMetricsClient(config *rest.Config, stopCh <-chan struct{}) custom_metrics.CustomMetricsClient {
 	discoveryClient := discovery.NewDiscoveryClientForConfigOrDie(config)
 	cachedDiscoveryClient := cachedDiscovery.NewMemCacheClient(discoveryClient)
 	restMapper := restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscoveryClient)
 
 	go wait.Until(func() {
 		restMapper.Reset()
-	}, discoveryResetPeriod, make(chan struct{}))
+	}, discoveryResetPeriod, stopCh)
 
 	apiVersionGetter := custom_metrics.NewAvailableAPIsGetter(discoveryClient)
 	customClient := custom_metrics.NewForConfig(config, restMapper, apiVersionGetter)
@@ -7,6 +7,7 @@ import (
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1Beta1 "k8s.io/api/batch/v1beta1"
 	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	apiMeta "k8s.io/apimachinery/pkg/api/meta"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -29,12 +30,16 @@ import (
 const (
 	// discovery client 缓存重置的时间间隔
 	discoveryResetPeriod = 5 * time.Minute
+	// ownerReference 链的最大查找深度
+	maxOwnerChainDepth = 5
 )
 
 // MpaTargetSelectorFetch 获取 labelSelector，用于选择被指定 MPA 控制的 PODs
 type MpaTargetSelectorFetch interface {
 	// Fetch 如果返回 error == nil, 则 selector 不是 nil
 	Fetch(mpa *mpaTypes.MultidimPodAutoscaler) (labels.Selector, error)
+	// PodControlledByTarget 判断 pod 的 ownerReference 链中是否包含 mpa 的 targetRef
+	PodControlledByTarget(pod *coreV1.Pod, mpa *mpaTypes.MultidimPodAutoscaler) (bool, error)
 }
 
 // WellKnownController 常见控制器枚举 枚举常量类型定义
@@ -53,9 +58,10 @@ const (
 )
 
 // NewMpaTargetSelectorFetcher 返回 MpaTargetSelectorFetcher 接口，来获指定 mpa 的label选择器
-// config - client配置；kubeClient - client；factory - 用于创建informer对象
+// config - client配置；kubeClient - client；factory - 用于创建informer对象；stopCh 关闭时停止 informer
 // 使用 sharedInformer (一个mpa上可能包含多个controller，每个控制器注册自己的回调，共享store)
-func NewMpaTargetSelectorFetcher(config *rest.Config, kubeClient kubeClient.Interface, factory informers.SharedInformerFactory) MpaTargetSelectorFetch {
+func NewMpaTargetSelectorFetcher(config *rest.Config, kubeClient kubeClient.Interface, factory informers.SharedInformerFactory,
+	stopCh <-chan struct{}) MpaTargetSelectorFetch {
 	// 用于获取 api-server 支持的资源组、版本、信息
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
@@ -71,11 +77,10 @@ func NewMpaTargetSelectorFetcher(config *rest.Config, kubeClient kubeClient.Inte
 	// 懒加载 rest mapper(将resource映射到kind)
 	mapper := restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscoveryClient)
 
-	// channel 必须使用 make 构造
 	go wait.Until(func() {
 		// 重置缓存信息(会发出mapping请求，初始化/更新REST mapper)
 		mapper.Reset()
-	}, discoveryResetPeriod, make(chan struct{}))
+	}, discoveryResetPeriod, stopCh)
 
 	// 构造 informers map, informer 使用 factory 工厂创建
 	informersMap := map[WellKnownController]cache.SharedIndexInformer{
@@ -90,11 +95,9 @@ func NewMpaTargetSelectorFetcher(config *rest.Config, kubeClient kubeClient.Inte
 
 	for kind, informer := range informersMap {
 		// 启动informer
-		stopChan := make(chan struct{})
-		go informer.Run(stopChan)
-		// 等待informer的cache被填充(只可能返回true/false)
-		// stopChan在这里不会被主动关闭
-		synced := cache.WaitForCacheSync(stopChan, informer.HasSynced)
+		go informer.Run(stopCh)
+		// 等待informer的cache被填充(stopCh 被关闭时返回 false)
+		synced := cache.WaitForCacheSync(stopCh, informer.HasSynced)
 		if !synced {
 			klog.Fatalf("cannot sync cache for %s: %v", kind, err)
 		} else {
@@ -104,6 +107,7 @@ func NewMpaTargetSelectorFetcher(config *rest.Config, kubeClient kubeClient.Inte
 	// 创建scale子资源接口(用于获取或更新某个namespace下定义了的scale子资源)
 	scaleNamespacer := scale.New(restClient, mapper, dynamic.LegacyAPIPathResolverFunc, resolver)
 	return &mpaTargetSelectorFetcher{
+		kubeclient:      kubeClient,
 		scaleNamespacer: scaleNamespacer,
 		mapper:          mapper,
 		informersMap:    informersMap,
@@ -113,6 +117,8 @@ func NewMpaTargetSelectorFetcher(config *rest.Config, kubeClient kubeClient.Inte
 // mpaTargetSelectorFetcher 实现 MpaTargetSelectorFetcher 接口
 // 通过 API server 查询 mpa 指向的 controller
 type mpaTargetSelectorFetcher struct {
+	// kubeclient 用于查询 informer 缓存中还没有的属主
+	kubeclient      kubeClient.Interface
 	scaleNamespacer scale.ScalesGetter
 	mapper          apiMeta.RESTMapper
 	informersMap    map[WellKnownController]cache.SharedIndexInformer
@@ -249,3 +255,82 @@ func (fetch *mpaTargetSelectorFetcher) getLabelSelectorFromResource(groupKind sc
 
 	return nil, retError
 }
+
+// PodControlledByTarget 实现 MpaTargetSelectorFetcher 接口
+// 沿着 pod 的 ownerReference 链(只考虑 controller 属主)向上查找 mpa 的 targetRef
+// 属主为未知的 controller 时无法继续查找，视为不匹配
+// 属主不在 informer 缓存中时(如: 滚动更新时新创建的 ReplicaSet)直接向 api-server 查询;
+// 查询失败、无法确认属主链时只按 label selector 匹配, 不视为不匹配
+func (fetch *mpaTargetSelectorFetcher) PodControlledByTarget(pod *coreV1.Pod, mpa *mpaTypes.MultidimPodAutoscaler) (bool, error) {
+	if mpa.Spec.TargetRef == nil {
+		return false, fmt.Errorf("targetRef undefined")
+	}
+	targetGroupVersion, err := schema.ParseGroupVersion(mpa.Spec.TargetRef.APIVersion)
+	if err != nil {
+		return false, err
+	}
+
+	ownerRef := metaV1.GetControllerOf(pod)
+	for depth := 0; ownerRef != nil && depth < maxOwnerChainDepth; depth += 1 {
+		ownerGroupVersion, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+		if err != nil {
+			return false, err
+		}
+		if ownerRef.Kind == mpa.Spec.TargetRef.Kind &&
+			ownerRef.Name == mpa.Spec.TargetRef.Name &&
+			ownerGroupVersion.Group == targetGroupVersion.Group {
+			return true, nil
+		}
+
+		informer, existed := fetch.informersMap[WellKnownController(ownerRef.Kind)]
+		if !existed {
+			return false, nil
+		}
+		item, exists, err := informer.GetStore().GetByKey(pod.Namespace + "/" + ownerRef.Name)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			item, err = fetch.getOwner(WellKnownController(ownerRef.Kind), pod.Namespace, ownerRef.Name)
+			if errors.IsNotFound(err) {
+				return false, fmt.Errorf("%s %s/%s does not exist", ownerRef.Kind, pod.Namespace, ownerRef.Name)
+			}
+			if err != nil {
+				klog.V(4).Infof("cannot get %s %s/%s, owner chain of pod %s/%s is unverifiable, matched by labels only: %v",
+					ownerRef.Kind, pod.Namespace, ownerRef.Name, pod.Namespace, pod.Name, err)
+				return true, nil
+			}
+		}
+		owner, err := apiMeta.Accessor(item)
+		if err != nil {
+			return false, err
+		}
+		ownerRef = metaV1.GetControllerOf(owner)
+	}
+	return false, nil
+}
+
+// getOwner 直接从 api-server 获取 namespace/name 对应的 controller
+func (fetch *mpaTargetSelectorFetcher) getOwner(kind WellKnownController, namespace, name string) (interface{}, error) {
+	if fetch.kubeclient == nil {
+		return nil, fmt.Errorf("no client to get %s %s/%s", kind, namespace, name)
+	}
+	ctx := context.TODO()
+	switch kind {
+	case DaemonSet:
+		return fetch.kubeclient.AppsV1().DaemonSets(namespace).Get(ctx, name, metaV1.GetOptions{})
+	case Deployment:
+		return fetch.kubeclient.AppsV1().Deployments(namespace).Get(ctx, name, metaV1.GetOptions{})
+	case ReplicaSet:
+		return fetch.kubeclient.AppsV1().ReplicaSets(namespace).Get(ctx, name, metaV1.GetOptions{})
+	case StatefulSet:
+		return fetch.kubeclient.AppsV1().StatefulSets(namespace).Get(ctx, name, metaV1.GetOptions{})
+	case ReplicationController:
+		return fetch.kubeclient.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metaV1.GetOptions{})
+	case Job:
+		return fetch.kubeclient.BatchV1().Jobs(namespace).Get(ctx, name, metaV1.GetOptions{})
+	case CronJob:
+		return fetch.kubeclient.BatchV1beta1().CronJobs(namespace).Get(ctx, name, metaV1.GetOptions{})
+	}
+	return nil, fmt.Errorf("unknown controller kind %s", kind)
+}
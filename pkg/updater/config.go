@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+	configv1alpha1 "multidim-pod-autoscaler/pkg/apis/config/v1alpha1"
+	"multidim-pod-autoscaler/pkg/updater/logic"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+	"multidim-pod-autoscaler/pkg/util/diagnostics"
+)
+
+var (
+	configFile, configReloadPeriod = utilConfig.RegisterFlags(flag.CommandLine, configv1alpha1.UpdaterConfigurationKind)
+)
+
+// loadConfiguration 解析配置文件(data 为 nil 时只使用命令行参数), 设置默认值,
+// 用命令行中显式指定的参数覆盖文件中的值, 最后进行校验
+func loadConfiguration(data []byte) (*configv1alpha1.UpdaterConfiguration, error) {
+	c := &configv1alpha1.UpdaterConfiguration{}
+	if data != nil {
+		gvk := configv1alpha1.SchemeGroupVersion.WithKind(configv1alpha1.UpdaterConfigurationKind)
+		if err := utilConfig.Decode(data, gvk, c); err != nil {
+			return nil, err
+		}
+	}
+	configv1alpha1.SetDefaults_UpdaterConfiguration(c)
+	for name := range utilConfig.ExplicitFlags() {
+		switch name {
+		case "updater-interval":
+			c.UpdaterInterval.Duration = *updaterInterval
+		case "min-replicas":
+			c.MinReplicas = int32(*minReplicasToUpdate)
+		case "eviction-fraction":
+			fraction := *evictionFraction
+			c.EvictionFraction = &fraction
+		case "address":
+			c.Diagnostics.Address = *prometheusAddress
+		case "profiling":
+			c.Diagnostics.EnableProfiling = *profiling
+		case "kube-api-qps":
+			c.ClientConnection.QPS = float32(*kubeApiQps)
+		case "kube-api-burst":
+			c.ClientConnection.Burst = int32(*kubeApiBurst)
+		case "mpa-object-namespace":
+			c.MpaObjectNamespace = *mpaObjectNamespace
+		}
+	}
+	if errs := configv1alpha1.ValidateUpdaterConfiguration(c); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return c, nil
+}
+
+// configurationReloader 在配置文件变化时应用新的配置
+// 主流程间隔、最少副本数及驱逐比例从下一轮主流程开始生效, 其他字段被修改时只打印警告(重启后生效)
+type configurationReloader struct {
+	// initial 启动时的配置
+	initial  *configv1alpha1.UpdaterConfiguration
+	updater  logic.Updater
+	progress *diagnostics.Progress
+
+	lock     sync.RWMutex
+	interval time.Duration
+}
+
+func newConfigurationReloader(initial *configv1alpha1.UpdaterConfiguration, updater logic.Updater, progress *diagnostics.Progress) *configurationReloader {
+	return &configurationReloader{
+		initial:  initial,
+		updater:  updater,
+		progress: progress,
+		interval: initial.UpdaterInterval.Duration,
+	}
+}
+
+// updaterInterval 返回当前的主流程间隔
+func (r *configurationReloader) updaterInterval() time.Duration {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.interval
+}
+
+// reload 加载并应用 data 中的配置, 配置不合法时保持当前配置
+func (r *configurationReloader) reload(data []byte) {
+	c, err := loadConfiguration(data)
+	if err != nil {
+		klog.Errorf("invalid configuration, keep using the current one: %v", err)
+		return
+	}
+	r.updater.SetEvictionLimits(int(c.MinReplicas), *c.EvictionFraction)
+	r.lock.Lock()
+	r.interval = c.UpdaterInterval.Duration
+	r.lock.Unlock()
+	r.progress.SetInterval(c.UpdaterInterval.Duration)
+
+	old := r.initial
+	if !reflect.DeepEqual(old.ClientConnection, c.ClientConnection) || !reflect.DeepEqual(old.Diagnostics, c.Diagnostics) ||
+		old.MpaObjectNamespace != c.MpaObjectNamespace {
+		klog.Warningf("clientConnection, diagnostics and mpaObjectNamespace take effect after restart")
+	}
+	klog.Infof("configuration reloaded: updaterInterval=%v, minReplicas=%d, evictionFraction=%v",
+		c.UpdaterInterval.Duration, c.MinReplicas, *c.EvictionFraction)
+}
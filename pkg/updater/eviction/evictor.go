@@ -12,12 +12,27 @@ import (
 	"k8s.io/klog"
 	"multidim-pod-autoscaler/pkg/target"
 	updaterUtil "multidim-pod-autoscaler/pkg/updater/util"
+	"sync"
 	"time"
 )
 
 const (
 	// informer 缓存的同步时间间隔
 	defaultResyncPeriod time.Duration = 1 * time.Minute
+	// ownerReference 链的最大查找深度
+	maxOwnerChainDepth = 5
+)
+
+var (
+	// supportedControllers 为 evictor 可以获取副本配置的 controller
+	supportedControllers = []target.WellKnownController{
+		target.ReplicaSet,
+		target.ReplicationController,
+		target.StatefulSet,
+		target.Deployment,
+		target.DaemonSet,
+		target.Job,
+	}
 )
 
 // PodEvictor 驱逐指定pod
@@ -25,7 +40,7 @@ const (
 // 即每次updater run一次都会创建新的 PodEvictor
 type PodEvictor interface {
 	// Evict 驱逐指定pod，并上报event(使用eventRecorder)
-	Evict(pod *corev1.Pod, eventRecorder record.EventRecorder) error
+	Evict(ctx context.Context, pod *corev1.Pod, eventRecorder record.EventRecorder) error
 	// Evictable 判断指定pod是否可以被驱逐
 	Evictable(pod *corev1.Pod) bool
 }
@@ -33,10 +48,12 @@ type PodEvictor interface {
 // PodEvictorFactory 创建新的 PodEvictor
 type PodEvictorFactory interface {
 	NewPodEvictor(pods []*corev1.Pod) PodEvictor
+	// SetLimits 修改可被更新的最少副本数量及可驱逐的比例(配置热加载时调用), 之后创建的 PodEvictor 使用新的值
+	SetLimits(minReplicasToUpdate int, evictionFraction float64)
 }
 
-// podManagedController 为管理 pod 的controller信息
-// 如: replicaset、statefulset等
+// podManagedController 为管理 pod 的(顶层)controller信息
+// 如: deployment、statefulset、daemonset等
 type podManagedController struct {
 	Namespace string
 	Name      string
@@ -48,13 +65,15 @@ type podManagedController struct {
 // pending - pending 状态的个数
 // running - running 状态的个数
 // evicted - 被驱逐的pod的个数
-// evictable - 可以被驱逐的数量(configured * [rate])
+// evictable - 可以被驱逐的数量(configured * [rate], daemonset 还受 maxUnavailable 限制)
 type managingControllerStates struct {
 	configured int
 	pending    int
 	running    int
 	evicted    int
 	evictable  int
+	// evictableLimited evictable 来自 controller 自身的滚动更新预算(如: DaemonSet 的 maxUnavailable), 不能超出
+	evictableLimited bool
 }
 
 // podEvictor 实现 PodEvictor 接口
@@ -71,43 +90,52 @@ type podEvictor struct {
 // minReplicasToUpdate 为可被更新的最少的副本数量
 // evictionFraction 表示最多可驱逐的pod的比例(相对于预配置的replicas的比例)
 type podEvictorFactory struct {
-	client              kubeClient.Interface
-	informersMap        map[target.WellKnownController]cache.SharedIndexInformer
+	client       kubeClient.Interface
+	informersMap map[target.WellKnownController]cache.SharedIndexInformer
+
+	lock                sync.RWMutex
 	minReplicasToUpdate int
 	evictionFraction    float64
 }
 
-// NewPodEvictorFactory 返回PodEvictorFactory
-func NewPodEvictorFactory(client kubeClient.Interface, minReplicasToUpdate int, evictionFraction float64) (PodEvictorFactory, error) {
-	replicaSetInformer, err := updaterUtil.GetInformer(client, target.ReplicaSet, defaultResyncPeriod)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create ReplicaSet informer: %v", err)
-	}
-	replicaControllerInformer, err := updaterUtil.GetInformer(client, target.ReplicationController, defaultResyncPeriod)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create ReplicationController informer: %v", err)
-	}
-	statefulSetInformer, err := updaterUtil.GetInformer(client, target.StatefulSet, defaultResyncPeriod)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create StatefulSet informer: %v", err)
+// NewPodEvictorFactory 返回PodEvictorFactory, stopCh 关闭时停止 controller 的 informer
+func NewPodEvictorFactory(client kubeClient.Interface, minReplicasToUpdate int, evictionFraction float64, stopCh <-chan struct{}) (PodEvictorFactory, error) {
+	informersMap := make(map[target.WellKnownController]cache.SharedIndexInformer)
+	for _, kind := range supportedControllers {
+		informer, err := updaterUtil.GetInformer(client, kind, defaultResyncPeriod, stopCh)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s informer: %v", kind, err)
+		}
+		informersMap[kind] = informer
 	}
 
 	return &podEvictorFactory{
-		client: client,
-		informersMap: map[target.WellKnownController]cache.SharedIndexInformer{
-			target.ReplicaSet:            replicaSetInformer,
-			target.ReplicationController: replicaControllerInformer,
-			target.StatefulSet:           statefulSetInformer,
-		},
+		client:              client,
+		informersMap:        informersMap,
+		minReplicasToUpdate: minReplicasToUpdate,
+		evictionFraction:    evictionFraction,
 	}, nil
 }
 
+// SetLimits 实现 PodEvictorFactory 接口
+func (factory *podEvictorFactory) SetLimits(minReplicasToUpdate int, evictionFraction float64) {
+	factory.lock.Lock()
+	defer factory.lock.Unlock()
+	factory.minReplicasToUpdate = minReplicasToUpdate
+	factory.evictionFraction = evictionFraction
+}
+
 // NewPodEvictor 创建一个新的 PodEvictor
 func (factory *podEvictorFactory) NewPodEvictor(pods []*corev1.Pod) PodEvictor {
+	factory.lock.RLock()
+	minReplicasToUpdate, evictionFraction := factory.minReplicasToUpdate, factory.evictionFraction
+	factory.lock.RUnlock()
+
 	controllerPods := make(map[podManagedController][]*corev1.Pod)
-	// 获取每个controller管理的pod集合
+	// 获取每个(顶层)controller管理的pod集合
+	// 如 Deployment 滚动更新过程中, 新旧 ReplicaSet 下的pod都归属于同一个 Deployment
 	for _, pod := range pods {
-		controller, err := getPodManagedController(pod)
+		controller, err := factory.getPodManagedController(pod)
 		if err != nil {
 			klog.Error(err)
 			continue
@@ -121,32 +149,33 @@ func (factory *podEvictorFactory) NewPodEvictor(pods []*corev1.Pod) PodEvictor {
 	for controller, pods := range controllerPods {
 		// 实际的副本个数
 		actualReplicas := len(pods)
-		if actualReplicas < factory.minReplicasToUpdate {
+		if actualReplicas < minReplicasToUpdate {
 			klog.V(2).Infof("too few replicas to Execute MPA Update for %s controller(%s/%s)", controller.Kind, controller.Namespace, controller.Name)
 			continue
 		}
-		var err error
-		var configured int
 
+		informer, exists := factory.informersMap[controller.Kind]
+		if !exists {
+			klog.V(4).Infof("cannot found the informer of %s Controller(%s/%s)", controller.Kind, controller.Namespace, controller.Name)
+			continue
+		}
 		// 获取预配置的replicas
-		if controller.Kind == target.Job {
-			configured = actualReplicas
-		} else {
-			informer, exists := factory.informersMap[controller.Kind]
-			if !exists {
-				klog.V(4).Infof("cannot found the informer of %s Controller(%s/%s)", controller.Kind, controller.Namespace, controller.Name)
-				continue
-			}
-			configured, err = updaterUtil.GetControllerReplicaCount(controller.Namespace, controller.Name, controller.Kind, informer)
-			if err != nil {
-				klog.Errorf("failed to fetch replicas configuration for %v %s/%s: %v", controller.Kind, controller.Namespace, controller.Name, err)
-				continue
-			}
+		configured, err := updaterUtil.GetControllerReplicaCount(controller.Namespace, controller.Name, controller.Kind, informer)
+		if err != nil {
+			klog.Errorf("failed to fetch replicas configuration for %v %s/%s: %v", controller.Kind, controller.Namespace, controller.Name, err)
+			continue
+		}
+		// 获取可以被驱逐的副本个数
+		evictable, evictableLimited, err := factory.getEvictableReplicas(controller, configured, evictionFraction, informer)
+		if err != nil {
+			klog.Errorf("failed to calculate evictable replicas for %v %s/%s: %v", controller.Kind, controller.Namespace, controller.Name, err)
+			continue
 		}
 		// 统计该controller的副本状态信息
 		controllerStates := managingControllerStates{
-			configured: configured,
-			evictable:  int(float64(configured) * factory.evictionFraction),
+			configured:       configured,
+			evictable:        evictable,
+			evictableLimited: evictableLimited,
 		}
 		// 为该controller下所有副本注册controller引用信息
 		for _, pod := range pods {
@@ -168,9 +197,72 @@ func (factory *podEvictorFactory) NewPodEvictor(pods []*corev1.Pod) PodEvictor {
 	}
 }
 
+// getEvictableReplicas 获取指定controller下可以被驱逐的副本个数, 及该个数是否由 controller 的滚动更新预算决定
+// DaemonSet 的副本按节点分布, 使用其滚动更新策略中的 maxUnavailable 作为上限
+// 其他controller为 configured * evictionFraction
+func (factory *podEvictorFactory) getEvictableReplicas(
+	controller podManagedController,
+	configured int,
+	evictionFraction float64,
+	informer cache.SharedIndexInformer,
+) (int, bool, error) {
+	evictable := int(float64(configured) * evictionFraction)
+	if controller.Kind != target.DaemonSet {
+		return evictable, false, nil
+	}
+	maxUnavailable, err := updaterUtil.GetDaemonSetMaxUnavailable(controller.Namespace, controller.Name, configured, informer)
+	if err != nil {
+		return 0, false, err
+	}
+	if maxUnavailable <= evictable {
+		return maxUnavailable, true, nil
+	}
+	return evictable, false, nil
+}
+
+// getPodManagedController 获取管理指定pod的顶层controller
+// 沿着 ownerReference 链向上查找, 直到属主不再是可识别的controller
+// 如: Pod -> ReplicaSet -> Deployment 返回 Deployment
+func (factory *podEvictorFactory) getPodManagedController(pod *corev1.Pod) (*podManagedController, error) {
+	ownerRef := updaterUtil.GetPodManagedControllerRef(pod)
+	if ownerRef == nil {
+		return nil, fmt.Errorf("connot found the ownerReference(points to Controller) for pod(%s)", pod.Name)
+	}
+	controller := &podManagedController{
+		Namespace: pod.Namespace,
+		Name:      ownerRef.Name,
+		Kind:      target.WellKnownController(ownerRef.Kind),
+	}
+
+	for depth := 0; depth < maxOwnerChainDepth; depth += 1 {
+		informer, exists := factory.informersMap[controller.Kind]
+		if !exists {
+			break
+		}
+		ownerRef, err := updaterUtil.GetControllerOwnerRef(controller.Namespace, controller.Name, controller.Kind, informer)
+		if err != nil {
+			return nil, err
+		}
+		if ownerRef == nil {
+			break
+		}
+		ownerKind := target.WellKnownController(ownerRef.Kind)
+		// 属主无法获取其副本配置(如: Job -> CronJob), 以当前controller为顶层controller
+		if _, exists := factory.informersMap[ownerKind]; !exists {
+			break
+		}
+		controller = &podManagedController{
+			Namespace: controller.Namespace,
+			Name:      ownerRef.Name,
+			Kind:      ownerKind,
+		}
+	}
+	return controller, nil
+}
+
 // Evict 驱逐指定pod，并上报event(使用eventRecorder)
 // 不会检查处于驱逐宽限期的pod状态
-func (evictor *podEvictor) Evict(pod *corev1.Pod, eventRecorder record.EventRecorder) error {
+func (evictor *podEvictor) Evict(ctx context.Context, pod *corev1.Pod, eventRecorder record.EventRecorder) error {
 	controller, exists := evictor.podControllerMap[updaterUtil.GetPodId(pod)]
 	if !exists {
 		return fmt.Errorf("does found the owner controller of pod(%s/%s), cannot evict the pod", pod.Namespace, pod.Name)
@@ -187,7 +279,7 @@ func (evictor *podEvictor) Evict(pod *corev1.Pod, eventRecorder record.EventReco
 		},
 	}
 
-	err := evictor.client.CoreV1().Pods(pod.Namespace).Evict(context.TODO(), eviction)
+	err := evictor.client.CoreV1().Pods(pod.Namespace).Evict(ctx, eviction)
 	if err != nil {
 		klog.Errorf("failed to evict pod %s/%s, error: %v", pod.Namespace, pod.Name, err)
 		return err
@@ -225,7 +317,9 @@ func (evictor *podEvictor) Evictable(pod *corev1.Pod) bool {
 			// 所有pod都在运行状态, 且 evictFraction 很小(使得evictable为0), 且当前为驱逐其他pod
 			// 此时可以驱逐一个pod(再次回到这里会不满足 evicted == 0 而不会驱逐其他pod)
 			// (在这之前已经对可update的最小副本数量做了限制)
+			// evictable 由 controller 的滚动更新预算决定时(如: DaemonSet 的 maxUnavailable 为 0)不能超出
 			if controllerStates.running == controllerStates.configured &&
+				!controllerStates.evictableLimited &&
 				controllerStates.evictable == 0 &&
 				controllerStates.evicted == 0 {
 				return true
@@ -235,16 +329,3 @@ func (evictor *podEvictor) Evictable(pod *corev1.Pod) bool {
 
 	return false
 }
-
-func getPodManagedController(pod *corev1.Pod) (*podManagedController, error) {
-	ownerRef := updaterUtil.GetPodManagedControllerRef(pod)
-	if ownerRef == nil {
-		return nil, fmt.Errorf("connot found the ownerReference(points to Controller) for pod(%s)", pod.Name)
-	}
-	controller := &podManagedController{
-		Namespace: pod.Namespace,
-		Name:      ownerRef.Name,
-		Kind:      target.WellKnownController(ownerRef.Kind),
-	}
-	return controller, nil
-}
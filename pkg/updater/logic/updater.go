@@ -5,6 +5,7 @@ import (
 	"fmt"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -13,6 +14,7 @@ import (
 	clientListers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
 	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
 	mpaClientset "multidim-pod-autoscaler/pkg/client/clientset/versioned"
@@ -21,8 +23,17 @@ import (
 	"multidim-pod-autoscaler/pkg/updater/eviction"
 	"multidim-pod-autoscaler/pkg/updater/priority"
 	updaterUtil "multidim-pod-autoscaler/pkg/updater/util"
+	"multidim-pod-autoscaler/pkg/util"
 	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
 	utilPod "multidim-pod-autoscaler/pkg/util/pod"
+	"multidim-pod-autoscaler/pkg/util/policy"
+	utilRecommendation "multidim-pod-autoscaler/pkg/util/recommendation"
+	"time"
+)
+
+const (
+	// evictionTimeout 单个pod驱逐请求(含重试)的超时时间
+	evictionTimeout = 30 * time.Second
 )
 
 // Updater 用于更新pod来应用recommender的推荐资源方案
@@ -30,6 +41,8 @@ type Updater interface {
 	// MainProcedure 为updater的一个主流程
 	// (updater为一个定时循环任务)
 	MainProcedure(ctx context.Context)
+	// SetEvictionLimits 修改执行update的最少副本数量及可驱逐的比例(配置热加载时调用), 从下一轮主流程开始生效
+	SetEvictionLimits(minReplicasToUpdate int, evictionFraction float64)
 }
 
 // updater 实现 Updater 接口
@@ -40,10 +53,12 @@ type updater struct {
 	mapper                    meta.RESTMapper
 	mpaLister                 mpaListers.MultidimPodAutoscalerLister
 	podLister                 clientListers.PodLister
+	skipFilter                utilPod.SkipFilter
 	eventRecorder             record.EventRecorder
 	evictorFactory            eviction.PodEvictorFactory
 	mpaTargetSelectorFetcher  target.MpaTargetSelectorFetch
 	evictionPriorityProcessor priority.Processor
+	policyResolver            policy.Resolver
 }
 
 func NewUpdater(
@@ -54,25 +69,41 @@ func NewUpdater(
 	mpaTargetSelectorFetcher target.MpaTargetSelectorFetch,
 	evictionPriorityProcessor priority.Processor,
 	namespace string,
+	stopCh <-chan struct{},
 ) (Updater, error) {
-	evictorFactory, err := eviction.NewPodEvictorFactory(kubeclient, minReplicasToUpdate, evictionFraction)
+	evictorFactory, err := eviction.NewPodEvictorFactory(kubeclient, minReplicasToUpdate, evictionFraction, stopCh)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create evictor factory: %v", err)
 	}
+	policyResolver, err := policy.NewResolver(kubeclient, mpaClient, namespace, nil, stopCh)
+	if err != nil {
+		return nil, err
+	}
+	skipFilter, err := utilPod.NewSkipFilter(kubeclient, namespace, stopCh)
+	if err != nil {
+		return nil, err
+	}
 	return &updater{
 		kubeclientset:             kubeclient,
 		mpaclientset:              mpaClient,
 		scaleNamespacer:           scaleNamespacer,
 		mapper:                    mapper,
-		mpaLister:                 utilMpa.NewMpasLister(mpaClient, namespace, make(chan struct{})),
-		podLister:                 utilPod.NewPodLister(kubeclient, namespace, make(chan struct{})),
-		eventRecorder:             updaterUtil.NewEventRecorder(kubeclient, "mpa-updater"),
+		mpaLister:                 utilMpa.NewMpasLister(mpaClient, namespace, stopCh),
+		podLister:                 utilPod.NewPodLister(kubeclient, namespace, stopCh),
+		skipFilter:                skipFilter,
+		eventRecorder:             util.NewEventRecorder(kubeclient, "mpa-updater"),
 		evictorFactory:            evictorFactory,
 		mpaTargetSelectorFetcher:  mpaTargetSelectorFetcher,
 		evictionPriorityProcessor: evictionPriorityProcessor,
+		policyResolver:            policyResolver,
 	}, nil
 }
 
+// SetEvictionLimits 实现 Updater 接口
+func (u *updater) SetEvictionLimits(minReplicasToUpdate int, evictionFraction float64) {
+	u.evictorFactory.SetLimits(minReplicasToUpdate, evictionFraction)
+}
+
 // MainProcedure 实现 Updater 接口，执行updater主流程
 func (u *updater) MainProcedure(ctx context.Context) {
 	executionTimer := updaterUtil.NewExecutionTimer()
@@ -81,10 +112,21 @@ func (u *updater) MainProcedure(ctx context.Context) {
 
 	mpaList, err := u.mpaLister.List(labels.Everything())
 	if err != nil {
-		klog.Fatalf("faied to get MPA Object list: %v", err)
+		// 下一轮主流程重试
+		klog.Errorf("failed to get MPA Object list: %v", err)
+		return
+	}
+	// 清理已被删除的 mpa 的 metrics
+	existing := make(map[string]bool, len(mpaList))
+	for _, mpa := range mpaList {
+		existing[mpa.Namespace+"/"+mpa.Name] = true
 	}
+	updaterUtil.ForgetDeletedMpas(existing)
+
 	mpas := make([]*utilMpa.MpaWithSelector, 0)
 	for _, mpa := range mpaList {
+		// 使用合并默认策略后生效的配置(更新模式、容器策略等)
+		mpa, _ = u.policyResolver.Resolve(mpa)
 		updateMode := utilMpa.GetMpaUpdateMode(mpa)
 		if updateMode != mpaTypes.UpdateModeAuto {
 			klog.V(3).Infof("skipped MPA Object %v/%v(its update mode was set to off(default is Auto))", mpa.Namespace, mpa.Name)
@@ -128,9 +170,11 @@ func (u *updater) MainProcedure(ctx context.Context) {
 	executionTimer.ObserveStep("GetPods")
 
 	livingPods := filterDeletedPods(podList)
+	// admission 不会修改不受 MPA 管理的 pods, 驱逐它们无法应用推荐方案
+	livingPods = utilPod.FilterSkippedPods(livingPods, u.skipFilter)
 	mpaControlledPods := make(map[*mpaTypes.MultidimPodAutoscaler][]*corev1.Pod)
 	for _, pod := range livingPods {
-		controllingMpa := utilMpa.GetControllingMpaForPod(pod, mpas)
+		controllingMpa := utilMpa.GetControllingMpaForPod(pod, mpas, u.mpaTargetSelectorFetcher)
 		if controllingMpa != nil {
 			mpaControlledPods[controllingMpa.Mpa] = append(mpaControlledPods[controllingMpa.Mpa], pod)
 		}
@@ -139,30 +183,50 @@ func (u *updater) MainProcedure(ctx context.Context) {
 	executionTimer.ObserveStep("FilterPods")
 
 	for mpa, pods := range mpaControlledPods {
-		scaleObj, targetGroupResource, err := u.getScaleResource(mpa)
+		if ctx.Err() != nil {
+			klog.Infof("stopping updater main procedure: %v", ctx.Err())
+			break
+		}
+		scaleObj, targetGroupResource, err := u.getScaleResource(ctx, mpa)
 		if err != nil {
 			klog.Warningf("failed to get targetRef scale resource of MPA %s/%s: %v", mpa.Namespace, mpa.Name, err)
 		} else {
-			err = u.updateScaleResourceReplicas(mpa, scaleObj, targetGroupResource)
-			if err != nil {
+			if utilRecommendation.ReplicasWithinBounds(scaleObj.Spec.Replicas, mpa.Status.RecommendationResources) {
+				klog.V(4).Infof("targetRef scale's replicas(%d) of MPA %s/%s is within the recommended bounds, no need to rescale", scaleObj.Spec.Replicas, mpa.Namespace, mpa.Name)
+			} else if err = u.updateScaleResourceReplicas(ctx, mpa, scaleObj, targetGroupResource); err != nil {
 				klog.Warningf("failed to update targetRef scale resource of MPA %s/%s: %v", mpa.Namespace, mpa.Name, err)
+				updaterUtil.OnScaleOperation(mpa.Namespace, mpa.Name, updaterUtil.Failed)
 			} else {
+				updaterUtil.OnScaleOperation(mpa.Namespace, mpa.Name, updaterUtil.Succeeded)
 				klog.V(4).Infof("successful to update targetRef scale's replicas of MPA %s/%s: size-%d", mpa.Namespace, mpa.Name, mpa.Status.RecommendationResources.TargetPodNum)
 			}
 		}
 
+		// evictor 需要根据 controller 的全部 pods 计算存活副本数与可驱逐数
+		// 资源量在推荐方案上下界内的 pods 只是无需更新, 不能从中去掉
 		evictor := u.evictorFactory.NewPodEvictor(pods)
-		podsUpdateOrder := u.evictionPriorityProcessor.GetPodsUpdateOrder(filterNonEvictablePods(pods, evictor), mpa)
+		podsToUpdate := filterPodsWithinBounds(filterNonEvictablePods(pods, evictor), mpa)
+		if len(podsToUpdate) == 0 {
+			continue
+		}
+		podsUpdateOrder := u.evictionPriorityProcessor.GetPodsUpdateOrder(podsToUpdate, mpa)
 		for _, pod := range podsUpdateOrder {
+			// 退出时不再发起新的驱逐, 已发起的驱逐在下面完成后再返回
+			if ctx.Err() != nil {
+				break
+			}
 			// 同一个controller下的pod被驱逐后，可能影响到其他pod的可驱逐状态
 			// 需要二次检查
 			if !evictor.Evictable(pod) {
 				continue
 			}
 			klog.V(2).Infof("evicting pod %s/%s", pod.Namespace, pod.Name)
-			err := evictor.Evict(pod, u.eventRecorder)
+			err := u.evict(evictor, pod)
 			if err != nil {
 				klog.Warningf("failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				updaterUtil.OnEviction(mpa.Namespace, mpa.Name, updaterUtil.Failed)
+			} else {
+				updaterUtil.OnEviction(mpa.Namespace, mpa.Name, updaterUtil.Succeeded)
 			}
 		}
 	}
@@ -180,6 +244,19 @@ func filterNonEvictablePods(pods []*corev1.Pod, evictor eviction.PodEvictor) []*
 	return result
 }
 
+// filterPodsWithinBounds 过滤资源量在推荐方案上下界内的pods, 返回需要更新的pods
+func filterPodsWithinBounds(pods []*corev1.Pod, mpa *mpaTypes.MultidimPodAutoscaler) []*corev1.Pod {
+	result := make([]*corev1.Pod, 0)
+	for _, pod := range pods {
+		if utilRecommendation.PodNeedsUpdate(pod, mpa) {
+			result = append(result, pod)
+		} else {
+			klog.V(4).Infof("resources of pod %s/%s are within the recommended bounds, no need to update", pod.Namespace, pod.Name)
+		}
+	}
+	return result
+}
+
 // filterDeletedPods 过滤已被删除的pods
 func filterDeletedPods(pods []*corev1.Pod) []*corev1.Pod {
 	result := make([]*corev1.Pod, 0)
@@ -192,8 +269,21 @@ func filterDeletedPods(pods []*corev1.Pod) []*corev1.Pod {
 	return result
 }
 
+// evict 驱逐 pod, 出现暂时性错误时重试
+// 驱逐请求不随主流程的 ctx 取消(只受 evictionTimeout 限制), 保证退出时已发起的驱逐能够完成
+// PodDisruptionBudget 不允许驱逐时(429)不重试, 下一轮主流程再尝试
+func (u *updater) evict(evictor eviction.PodEvictor, pod *corev1.Pod) error {
+	ctx, cancel := context.WithTimeout(context.Background(), evictionTimeout)
+	defer cancel()
+	return retry.OnError(util.TransientErrorBackoff, func(err error) bool {
+		return util.IsTransientError(err) && !errors.IsTooManyRequests(err)
+	}, func() error {
+		return evictor.Evict(ctx, pod, u.eventRecorder)
+	})
+}
+
 // getScaleResource 获取mpa指向的target对应的scale resource 及其对应的 groupResource
-func (u *updater) getScaleResource(mpa *mpaTypes.MultidimPodAutoscaler) (*autoscalingv1.Scale, schema.GroupResource, error) {
+func (u *updater) getScaleResource(ctx context.Context, mpa *mpaTypes.MultidimPodAutoscaler) (*autoscalingv1.Scale, schema.GroupResource, error) {
 	targetGroupVersion, err := schema.ParseGroupVersion(mpa.Spec.TargetRef.APIVersion)
 	if err != nil {
 		u.eventRecorder.Event(mpa, corev1.EventTypeWarning, "FailedGetScale", err.Error())
@@ -221,8 +311,11 @@ func (u *updater) getScaleResource(mpa *mpaTypes.MultidimPodAutoscaler) (*autosc
 	)
 	for _, mapping := range mappings {
 		targetGroupResource = mapping.Resource.GroupResource()
-		scaleObj, err =
-			u.scaleNamespacer.Scales(mpa.Namespace).Get(context.TODO(), targetGroupResource, mpa.Spec.TargetRef.Name, metav1.GetOptions{})
+		err = util.RetryOnTransientError(ctx, func() error {
+			var getErr error
+			scaleObj, getErr = u.scaleNamespacer.Scales(mpa.Namespace).Get(ctx, targetGroupResource, mpa.Spec.TargetRef.Name, metav1.GetOptions{})
+			return getErr
+		})
 		if err == nil {
 			break
 		}
@@ -239,6 +332,7 @@ func (u *updater) getScaleResource(mpa *mpaTypes.MultidimPodAutoscaler) (*autosc
 }
 
 func (u *updater) updateScaleResourceReplicas(
+	ctx context.Context,
 	mpa *mpaTypes.MultidimPodAutoscaler,
 	scaleObj *autoscalingv1.Scale,
 	targetGR schema.GroupResource,
@@ -248,7 +342,10 @@ func (u *updater) updateScaleResourceReplicas(
 
 	scaleObj.Spec.Replicas = newReplicas
 
-	_, err := u.scaleNamespacer.Scales(mpa.Namespace).Update(context.TODO(), targetGR, scaleObj, metav1.UpdateOptions{})
+	err := util.RetryOnTransientError(ctx, func() error {
+		_, updateErr := u.scaleNamespacer.Scales(mpa.Namespace).Update(ctx, targetGR, scaleObj, metav1.UpdateOptions{})
+		return updateErr
+	})
 
 	if err != nil {
 		u.eventRecorder.Eventf(mpa, corev1.EventTypeWarning, "FailedScale", "New size: %d; error: %v", newReplicas, err.Error())
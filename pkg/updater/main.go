@@ -14,7 +14,9 @@ import (
 	"multidim-pod-autoscaler/pkg/updater/priority"
 	updaterUtil "multidim-pod-autoscaler/pkg/updater/util"
 	"multidim-pod-autoscaler/pkg/util"
-	"multidim-pod-autoscaler/pkg/util/metrics"
+	utilConfig "multidim-pod-autoscaler/pkg/util/config"
+	"multidim-pod-autoscaler/pkg/util/diagnostics"
+	"multidim-pod-autoscaler/pkg/util/leaderelection"
 	utilMpa "multidim-pod-autoscaler/pkg/util/mpa"
 	"time"
 )
@@ -34,12 +36,15 @@ var (
 	evictionFraction = flag.Float64("eviction-fraction", 1,
 		"可以驱逐的副本个数占预配置个数的比例")
 
-	prometheusAddress = flag.String("address", ":8945", "Prometheus metrics对外暴露的地址")
+	prometheusAddress = flag.String("address", ":8945", "诊断 server(Prometheus metrics、/healthz、/readyz)对外暴露的地址")
+	profiling         = flag.Bool("profiling", false, "在诊断 server 上暴露 /debug/pprof")
 	kubeconfig        = flag.String("kubeconfig", "", "Path to kubeconfig. 使用out-cluster配置时指定")
 	kubeApiQps        = flag.Float64("kube-api-qps", 5.0, "访问API-Server的 QPS 限制")
 	kubeApiBurst      = flag.Int("kube-api-burst", 10, "访问API-Server的 QPS 峰值限制")
 
 	mpaObjectNamespace = flag.String("mpa-object-namespace", corev1.NamespaceAll, "搜索MPA Objects的命名空间")
+
+	leaderElection = leaderelection.RegisterFlags(flag.CommandLine, "mpa-updater")
 )
 
 func main() {
@@ -47,35 +52,91 @@ func main() {
 	cliFlag.InitFlags()
 	klog.V(1).Infof("Multidim Pod Autoscaler %s Updater", utilMpa.MultidimPodAutoscalerVersion)
 
-	metrics.InitializeMetrics(*prometheusAddress)
+	// 配置文件(可选)中的值被命令行中显式指定的参数覆盖
+	configData, err := utilConfig.ReadFile(*configFile)
+	if err != nil {
+		klog.Fatalf("failed to load configuration: %v", err)
+	}
+	componentConfig, err := loadConfiguration(configData)
+	if err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
+
+	// 收到 SIGTERM 时结束根 context, 停止主流程及 informer
+	ctx := util.SetupSignalContext()
+	stopCh := ctx.Done()
+
+	// 诊断 server: informer 缓存同步完成、主流程(成为 leader 后)持续运行时 ready
+	diagnosticsServer := diagnostics.NewServer(componentConfig.Diagnostics.Address, componentConfig.Diagnostics.EnableProfiling)
+	informersSynced := diagnostics.NewCondition("informer caches have not synced")
+	progress := diagnostics.NewProgress(componentConfig.UpdaterInterval.Duration)
+	diagnosticsServer.AddReadyCheck("informers", informersSynced.Check)
+	diagnosticsServer.AddReadyCheck("main-procedure", progress.Check)
+	diagnosticsServer.Start()
 	updaterUtil.RegisterMetrics()
+	leaderelection.RegisterMetrics()
 
-	config := util.CreateKubeConfig(*kubeconfig, float32(*kubeApiQps), *kubeApiBurst)
+	config := util.CreateKubeConfig(*kubeconfig, componentConfig.ClientConnection.QPS, int(componentConfig.ClientConnection.Burst))
 	kubeclient := kubeClient.NewForConfigOrDie(config)
 	mpaClient := mpaClientset.NewForConfigOrDie(config)
 	factory := informers.NewSharedInformerFactory(kubeclient, defaultResyncPeriod)
-	targetSelectorFetcher := target.NewMpaTargetSelectorFetcher(config, kubeclient, factory)
+	targetSelectorFetcher := target.NewMpaTargetSelectorFetcher(config, kubeclient, factory, stopCh)
 
-	mapper, scaleNamespacer := updaterUtil.NewMapperAndScaleGetter(config, kubeclient)
+	mapper, scaleNamespacer := updaterUtil.NewMapperAndScaleGetter(config, kubeclient, stopCh)
 
 	updater, err := logic.NewUpdater(
 		kubeclient,
 		mpaClient,
 		scaleNamespacer,
 		mapper,
-		*minReplicasToUpdate,
-		*evictionFraction,
+		int(componentConfig.MinReplicas),
+		*componentConfig.EvictionFraction,
 		targetSelectorFetcher,
 		priority.NewProcessor(),
-		*mpaObjectNamespace,
+		componentConfig.MpaObjectNamespace,
+		stopCh,
 	)
 	if err != nil {
 		klog.Fatalf("failed to create MPA updater: %v", err)
 	}
-	ticker := time.Tick(*updaterInterval)
-	for range ticker {
-		ctx, cancel := context.WithTimeout(context.Background(), *updaterInterval)
-		defer cancel()
-		updater.MainProcedure(ctx)
+	informersSynced.Set()
+	reloader := newConfigurationReloader(componentConfig, updater, progress)
+	if *configFile != "" {
+		// 配置文件变化时热加载
+		go utilConfig.NewWatcher(*configFile, *configReloadPeriod, configData, reloader.reload).Run(ctx)
+	}
+	// 只有 leader 执行主流程; 其他副本的 informer 缓存保持同步, 随时接替
+	klog.V(1).Infof("leader election: %v", leaderElection)
+	// ctx 结束时, 等待当前一轮主流程(处理中的驱逐)完成后退出
+	leaderelection.Run(ctx, kubeclient, leaderElection, "mpa-updater", func(leaderCtx context.Context) {
+		progress.Begin()
+		defer progress.End()
+		interval := reloader.updaterInterval()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-leaderCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			runCtx, cancel := context.WithTimeout(leaderCtx, interval)
+			updater.MainProcedure(runCtx)
+			cancel()
+			progress.Done()
+			// 主流程间隔被热加载修改时, 从下一轮开始使用新的间隔
+			if current := reloader.updaterInterval(); current != interval {
+				interval = current
+				ticker.Reset(interval)
+			}
+		}
+	})
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := diagnosticsServer.Shutdown(shutdownCtx); err != nil {
+		klog.Errorf("failed to shutdown diagnostics server: %v", err)
 	}
+	klog.Infof("MPA updater stopped")
+	klog.Flush()
 }
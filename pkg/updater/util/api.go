@@ -3,53 +3,34 @@ package util
 import (
 	"fmt"
 	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	cachedDiscovery "k8s.io/client-go/discovery/cached"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	kubeClient "k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/fake"
-	"k8s.io/client-go/kubernetes/scheme"
-	clientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 
-	mpascheme "multidim-pod-autoscaler/pkg/client/clientset/versioned/scheme"
 	"multidim-pod-autoscaler/pkg/target"
 	"time"
 )
 
-// NewEventRecorder 返回一个新的 EvenetRecorder 用于事件上报
-// component 为上报事件的组件名
-func NewEventRecorder(client kubeClient.Interface, component string) record.EventRecorder {
-	utilruntime.Must(mpascheme.AddToScheme(scheme.Scheme))
-	eventBroadcaster := record.NewBroadcaster()
-	eventBroadcaster.StartLogging(klog.V(4).Infof)
-	if _, isFake := client.(*fake.Clientset); !isFake {
-		eventBroadcaster.StartRecordingToSink(
-			&clientv1.EventSinkImpl{
-				Interface: clientv1.New(client.CoreV1().RESTClient()).Events(""),
-			},
-		)
-	}
-	return eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
-}
-
-// GetInformer 启动一个指定 controller kind 的informer
+// GetInformer 启动一个指定 controller kind 的informer, stopCh 关闭时停止
 // (使用 shared informer factory 创建)
 func GetInformer(
 	kubeclient kubeClient.Interface,
 	controllerKind target.WellKnownController,
 	resyncPeriod time.Duration,
+	stopCh <-chan struct{},
 ) (cache.SharedIndexInformer, error) {
 
 	var informer cache.SharedIndexInformer
@@ -62,11 +43,16 @@ func GetInformer(
 		informer = factory.Core().V1().ReplicationControllers().Informer()
 	case target.StatefulSet:
 		informer = factory.Apps().V1().StatefulSets().Informer()
+	case target.Deployment:
+		informer = factory.Apps().V1().Deployments().Informer()
+	case target.DaemonSet:
+		informer = factory.Apps().V1().DaemonSets().Informer()
+	case target.Job:
+		informer = factory.Batch().V1().Jobs().Informer()
 	default:
 		return nil, fmt.Errorf("unsupported controller kind: %s", controllerKind)
 	}
 	// 运行informer并等待local store缓存完成
-	stopCh := make(chan struct{})
 	go informer.Run(stopCh)
 	if synced := cache.WaitForCacheSync(stopCh, informer.HasSynced); !synced {
 		return nil, fmt.Errorf("failed to sync %s store", controllerKind)
@@ -76,16 +62,36 @@ func GetInformer(
 }
 
 // GetPodManagedControllerRef 获取指定pod的属主(且该属主指向的是Controller)
+// 没有属主时返回 nil
 func GetPodManagedControllerRef(pod *corev1.Pod) *metaV1.OwnerReference {
-	var managingController metaV1.OwnerReference
-
-	for _, owerRef := range pod.OwnerReferences {
-		if *owerRef.Controller {
-			managingController = owerRef
+	for i, owerRef := range pod.OwnerReferences {
+		if owerRef.Controller != nil && *owerRef.Controller {
+			return &pod.OwnerReferences[i]
 		}
 	}
+	return nil
+}
+
+// GetControllerOwnerRef 获取指定controller的属主(且该属主指向的是Controller)
+// 如: ReplicaSet -> Deployment; controller 没有属主时返回 nil
+// informer 为对应controller的informer
+func GetControllerOwnerRef(
+	controllerNamespace, controllerName string,
+	controllerKind target.WellKnownController,
+	informer cache.SharedIndexInformer) (*metaV1.OwnerReference, error) {
 
-	return &managingController
+	itemObj, exists, err := informer.GetStore().GetByKey(controllerNamespace + "/" + controllerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s controller Ojbect(%s/%s): %v", controllerKind, controllerNamespace, controllerName, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s controller Object(%s/%s) does not exists", controllerKind, controllerNamespace, controllerName)
+	}
+	accessor, err := meta.Accessor(itemObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s controller(%s/%s): %v", controllerKind, controllerNamespace, controllerName, err)
+	}
+	return metaV1.GetControllerOf(accessor), nil
 }
 
 // GetControllerReplicaCount 获取指定controller的replicas配置
@@ -104,7 +110,6 @@ func GetControllerReplicaCount(
 		return 0, fmt.Errorf("%s controller Object(%s/%s) does not exists", controllerKind, controllerNamespace, controllerName)
 	}
 	// 根据kind解析为不同的controller
-	// 暂时只支持下面三种controller kind
 	switch controllerKind {
 	case target.ReplicaSet:
 		replicaSet, ok := itemObj.(*appsV1.ReplicaSet)
@@ -133,11 +138,71 @@ func GetControllerReplicaCount(
 			return 0, fmt.Errorf("statefulSet controller(%s/%s) has no replicas configuration", controllerNamespace, controllerName)
 		}
 		return int(*statefulSet.Spec.Replicas), nil
+	case target.Deployment:
+		deployment, ok := itemObj.(*appsV1.Deployment)
+		if !ok {
+			return 0, fmt.Errorf("failed to parse deployment controller(%s/%s)", controllerNamespace, controllerName)
+		}
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas == 0 {
+			return 0, fmt.Errorf("deployment controller(%s/%s) has no replicas configuration", controllerNamespace, controllerName)
+		}
+		return int(*deployment.Spec.Replicas), nil
+	case target.DaemonSet:
+		// daemonSet 没有 replicas 配置, 每个可调度节点上运行一个副本
+		daemonSet, ok := itemObj.(*appsV1.DaemonSet)
+		if !ok {
+			return 0, fmt.Errorf("failed to parse daemonSet controller(%s/%s)", controllerNamespace, controllerName)
+		}
+		if daemonSet.Status.DesiredNumberScheduled == 0 {
+			return 0, fmt.Errorf("daemonSet controller(%s/%s) has no desired scheduled pods", controllerNamespace, controllerName)
+		}
+		return int(daemonSet.Status.DesiredNumberScheduled), nil
+	case target.Job:
+		// job 的并发数即为同时运行的副本个数(默认为1)
+		job, ok := itemObj.(*batchV1.Job)
+		if !ok {
+			return 0, fmt.Errorf("failed to parse job controller(%s/%s)", controllerNamespace, controllerName)
+		}
+		if job.Spec.Parallelism == nil {
+			return 1, nil
+		}
+		if *job.Spec.Parallelism == 0 {
+			return 0, fmt.Errorf("job controller(%s/%s) has no parallelism configuration", controllerNamespace, controllerName)
+		}
+		return int(*job.Spec.Parallelism), nil
 	}
 
 	return 0, fmt.Errorf("unsupported controller kind(%s) for now to get its replica count", controllerKind)
 }
 
+// GetDaemonSetMaxUnavailable 获取指定daemonSet滚动更新时最多不可用的副本个数
+// desired 为daemonSet期望调度的副本个数(用于计算百分比形式的配置)
+// 未配置滚动更新策略时, 默认为 1
+func GetDaemonSetMaxUnavailable(
+	namespace, name string, desired int,
+	informer cache.SharedIndexInformer) (int, error) {
+
+	itemObj, exists, err := informer.GetStore().GetByKey(namespace + "/" + name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get DaemonSet controller Ojbect(%s/%s): %v", namespace, name, err)
+	}
+	if !exists {
+		return 0, fmt.Errorf("DaemonSet controller Object(%s/%s) does not exists", namespace, name)
+	}
+	daemonSet, ok := itemObj.(*appsV1.DaemonSet)
+	if !ok {
+		return 0, fmt.Errorf("failed to parse daemonSet controller(%s/%s)", namespace, name)
+	}
+	if daemonSet.Spec.UpdateStrategy.RollingUpdate == nil || daemonSet.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable == nil {
+		return 1, nil
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(daemonSet.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable, desired, true)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxUnavailable of daemonSet controller(%s/%s): %v", namespace, name, err)
+	}
+	return maxUnavailable, nil
+}
+
 // GetPodId 获取pod的id，"[namespace]/[name]"
 func GetPodId(pod *corev1.Pod) string {
 	if pod == nil {
@@ -146,7 +211,8 @@ func GetPodId(pod *corev1.Pod) string {
 	return pod.Namespace + "/" + pod.Name
 }
 
-func NewMapperAndScaleGetter(config *rest.Config, kubeclientset kubeClient.Interface) (meta.RESTMapper, scale.ScalesGetter) {
+// NewMapperAndScaleGetter 返回 REST mapper 及 scale 子资源接口, stopCh 关闭时停止定期重置 mapper
+func NewMapperAndScaleGetter(config *rest.Config, kubeclientset kubeClient.Interface, stopCh <-chan struct{}) (meta.RESTMapper, scale.ScalesGetter) {
 	// 用于获取 api-server 支持的资源组、版本、信息
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
@@ -165,7 +231,7 @@ func NewMapperAndScaleGetter(config *rest.Config, kubeclientset kubeClient.Inter
 	go wait.Until(func() {
 		// 重置缓存信息(会发出mapping请求，初始化/更新REST mapper)
 		mapper.Reset()
-	}, 30*time.Second, make(chan struct{}))
+	}, 30*time.Second, stopCh)
 
 	scaleNamespacer := scale.New(restClient, mapper, dynamic.LegacyAPIPathResolverFunc, resolver)
 
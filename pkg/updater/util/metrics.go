@@ -9,12 +9,58 @@ const (
 	metricsNamespace = metrics.TopNamespace + "updater"
 )
 
+// Outcome 表示 updater 一次操作(驱逐、伸缩)的结果
+type Outcome string
+
+const (
+	// Succeeded 操作成功
+	Succeeded Outcome = "success"
+	// Failed 操作失败
+	Failed Outcome = "failure"
+)
+
 var (
 	updaterLatency = metrics.CreateExecutionTimeMetric(metricsNamespace, "mpa updater主流程中的执行时间")
+	evictions      = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "evictions_total",
+			Help:      "updater 为应用 MPA 的推荐方案驱逐 pod 的次数(按结果区分)",
+		},
+		append(metrics.MpaLabels, "outcome"),
+	)
+	scaleOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scale_operations_total",
+			Help:      "updater 为应用 MPA 的推荐方案伸缩 targetRef 副本数的次数(按结果区分)",
+		},
+		append(metrics.MpaLabels, "outcome"),
+	)
+
+	// mpaSeries 记录每个 MPA 产生的时间序列, MPA 被删除后一并删除
+	mpaSeries = metrics.NewMpaSeries()
 )
 
 func RegisterMetrics() {
-	prometheus.MustRegister(updaterLatency)
+	prometheus.MustRegister(updaterLatency, evictions, scaleOperations)
+}
+
+// OnEviction 记录一次为 MPA 驱逐 pod 的结果
+func OnEviction(namespace, name string, outcome Outcome) {
+	evictions.WithLabelValues(namespace, name, string(outcome)).Inc()
+	mpaSeries.Track(evictions, namespace, name, string(outcome))
+}
+
+// OnScaleOperation 记录一次伸缩 MPA targetRef 副本数的结果
+func OnScaleOperation(namespace, name string, outcome Outcome) {
+	scaleOperations.WithLabelValues(namespace, name, string(outcome)).Inc()
+	mpaSeries.Track(scaleOperations, namespace, name, string(outcome))
+}
+
+// ForgetDeletedMpas 删除不在 existing(namespace/name)中的 MPA 的所有时间序列
+func ForgetDeletedMpas(existing map[string]bool) {
+	mpaSeries.ForgetExcept(existing)
 }
 
 func NewExecutionTimer() *metrics.ExecutionTimer {
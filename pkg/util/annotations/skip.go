@@ -0,0 +1,34 @@
+package annotations
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MpaSkipAnnotation 设置为 "true" 时, MPA 不处理该对象
+	// 作用于 pod 时, admission 不修改该 pod; 作用于 namespace 时, 该命名空间下的所有 pod 都不被修改
+	// 同名的 label 可用于 webhook 的 namespaceSelector / objectSelector, 使 api-server 不调用 webhook
+	MpaSkipAnnotation = "mpa.k8s.io/skip"
+)
+
+// IsPodSkipped 判断 pod 是否通过 annotation 跳过 MPA
+func IsPodSkipped(pod *corev1.Pod) bool {
+	return isSkipped(pod.Annotations)
+}
+
+// IsNamespaceSkipped 判断命名空间是否通过 annotation 禁用 MPA
+func IsNamespaceSkipped(namespace *corev1.Namespace) bool {
+	return isSkipped(namespace.Annotations)
+}
+
+// isSkipped 判断 annotations 中 MpaSkipAnnotation 是否为 true
+func isSkipped(annotations map[string]string) bool {
+	value, existed := annotations[MpaSkipAnnotation]
+	if !existed {
+		return false
+	}
+	skipped, err := strconv.ParseBool(value)
+	return err == nil && skipped
+}
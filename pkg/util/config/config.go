@@ -0,0 +1,114 @@
+// Package config 读取组件的配置文件(pkg/apis/config), 并在文件变化时通知组件重新加载
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog"
+)
+
+// RegisterFlags 在 fs 中注册配置文件相关的命令行参数, 返回配置文件的路径及检查文件变化的时间间隔
+func RegisterFlags(fs *flag.FlagSet, kind string) (*string, *time.Duration) {
+	path := fs.String("config", "", fmt.Sprintf("组件配置文件(%s)的路径, 可以挂载自 ConfigMap; 命令行中显式指定的参数覆盖文件中的值", kind))
+	reloadPeriod := fs.Duration("config-reload-period", 10*time.Second, "检查配置文件是否变化的时间间隔, 变化后重新加载")
+	return path, reloadPeriod
+}
+
+// Decode 将 YAML/JSON 格式的配置解析到 obj 中
+// 配置的 apiVersion、kind 必须与 gvk 一致; 不允许未知字段, 避免字段名拼写错误时被静默忽略
+func Decode(data []byte, gvk schema.GroupVersionKind, obj interface{}) error {
+	jsonData, err := yaml.ToJSON(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration: %v", err)
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(jsonData, &typeMeta); err != nil {
+		return fmt.Errorf("failed to parse configuration: %v", err)
+	}
+	if typeMeta.APIVersion != gvk.GroupVersion().String() || typeMeta.Kind != gvk.Kind {
+		return fmt.Errorf("unexpected configuration %s(%s), want %s(%s)",
+			typeMeta.Kind, typeMeta.APIVersion, gvk.Kind, gvk.GroupVersion().String())
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", gvk.Kind, err)
+	}
+	return nil
+}
+
+// ExplicitFlags 返回命令行中显式指定的参数名
+// 组件通过 cliFlag.InitFlags 使用 pflag 解析命令行, 标准库 flag 中不会记录参数是否被指定
+func ExplicitFlags() map[string]bool {
+	explicit := map[string]bool{}
+	pflag.Visit(func(f *pflag.Flag) {
+		explicit[f.Name] = true
+	})
+	return explicit
+}
+
+// ReadFile 读取配置文件, path 为空(未指定 --config)时返回 nil
+func ReadFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %v", err)
+	}
+	return data, nil
+}
+
+// Watcher 定期读取配置文件, 内容变化时调用 onChange
+// ConfigMap 挂载的文件通过替换符号链接的方式更新, 轮询文件内容可以同时兼容普通文件和 ConfigMap
+type Watcher struct {
+	path     string
+	period   time.Duration
+	last     []byte
+	onChange func(data []byte)
+}
+
+// NewWatcher 创建 Watcher, initial 为组件启动时读取的内容
+func NewWatcher(path string, period time.Duration, initial []byte, onChange func(data []byte)) *Watcher {
+	return &Watcher{
+		path:     path,
+		period:   period,
+		last:     initial,
+		onChange: onChange,
+	}
+}
+
+// Run 每隔 period 检查一次配置文件, 直到 ctx 结束
+// 读取失败(如: ConfigMap 更新过程中)时保持当前配置, 下次检查时重试
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		data, err := ioutil.ReadFile(w.path)
+		if err != nil {
+			klog.Errorf("failed to read configuration file %s: %v", w.path, err)
+			continue
+		}
+		if bytes.Equal(data, w.last) {
+			continue
+		}
+		w.last = data
+		klog.Infof("configuration file %s changed, reloading", w.path)
+		w.onChange(data)
+	}
+}
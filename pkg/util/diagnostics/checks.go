@@ -0,0 +1,96 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Condition 由组件手动设置的检查项(如: 初始化时 informer 缓存是否同步完成)
+type Condition struct {
+	lock    sync.RWMutex
+	message string
+	ok      bool
+}
+
+// NewCondition 创建未满足的 Condition, message 为未满足时的原因
+func NewCondition(message string) *Condition {
+	return &Condition{message: message}
+}
+
+// Set 标记 Condition 已满足
+func (c *Condition) Set() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ok = true
+}
+
+// Check 实现 Check
+func (c *Condition) Check() error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if !c.ok {
+		return fmt.Errorf("%s", c.message)
+	}
+	return nil
+}
+
+// Progress 记录组件主流程的进度
+// 主流程运行期间(如: 成为 leader 后), 最近一次完成距今超过 2 倍的间隔时认为主流程卡住
+// 主流程未运行(如: 非 leader 副本)时不检查
+type Progress struct {
+	lock     sync.RWMutex
+	clock    clock.Clock
+	interval time.Duration
+	running  bool
+	last     time.Time
+}
+
+// NewProgress 创建 Progress, interval 为主流程的运行间隔
+func NewProgress(interval time.Duration) *Progress {
+	return &Progress{clock: clock.RealClock{}, interval: interval}
+}
+
+// SetInterval 修改主流程的运行间隔(配置热加载时调用)
+func (p *Progress) SetInterval(interval time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.interval = interval
+}
+
+// Begin 标记主流程开始运行
+func (p *Progress) Begin() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.running = true
+	p.last = p.clock.Now()
+}
+
+// Done 记录主流程完成了一轮
+func (p *Progress) Done() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.last = p.clock.Now()
+}
+
+// End 标记主流程停止运行
+func (p *Progress) End() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.running = false
+}
+
+// Check 实现 Check
+func (p *Progress) Check() error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if !p.running {
+		return nil
+	}
+	if elapsed := p.clock.Since(p.last); elapsed > 2*p.interval {
+		return fmt.Errorf("main procedure has not completed for %v(interval %v)", elapsed.Round(time.Second), p.interval)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package diagnostics 为 recommender、updater、admission 提供统一的诊断 server
+// 在同一个地址上暴露 /metrics、/healthz、/readyz 及可选的 /debug/pprof
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog"
+)
+
+// Check 检查组件的某项状态, 不满足时返回原因
+type Check func() error
+
+// Server 诊断 server
+// /healthz 表示进程是否存活(health check 均通过), /readyz 表示组件是否可以正常工作(ready check 均通过)
+type Server struct {
+	server *http.Server
+
+	lock         sync.RWMutex
+	healthChecks map[string]Check
+	readyChecks  map[string]Check
+}
+
+// NewServer 创建监听 address 的诊断 server, enableProfiling 为 true 时同时暴露 /debug/pprof
+func NewServer(address string, enableProfiling bool) *Server {
+	s := &Server{
+		healthChecks: map[string]Check{},
+		readyChecks:  map[string]Check{},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		s.serveChecks(w, r, s.healthChecks)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		s.serveChecks(w, r, s.readyChecks)
+	})
+	if enableProfiling {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	s.server = &http.Server{Addr: address, Handler: mux}
+	return s
+}
+
+// AddHealthCheck 添加 /healthz 的检查项
+func (s *Server) AddHealthCheck(name string, check Check) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.healthChecks[name] = check
+}
+
+// AddReadyCheck 添加 /readyz 的检查项
+func (s *Server) AddReadyCheck(name string, check Check) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.readyChecks[name] = check
+}
+
+// Start 在后台启动 server
+func (s *Server) Start() {
+	go func() {
+		klog.V(1).Infof("serving diagnostics(/metrics, /healthz, /readyz) on %s", s.server.Addr)
+		if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
+			klog.Fatalf("Error occured while start diagnostics server: %v", err)
+		}
+	}()
+}
+
+// Shutdown 停止 server, 等待处理中的请求完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// serveChecks 执行 checks, 全部通过时返回 200, 否则返回 503 及未通过的检查项
+// 请求带有 verbose 参数时列出每个检查项的结果
+func (s *Server) serveChecks(w http.ResponseWriter, r *http.Request, checks map[string]Check) {
+	s.lock.RLock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	failed := false
+	for _, name := range names {
+		if err := checks[name](); err != nil {
+			failed = true
+			fmt.Fprintf(&builder, "[-]%s failed: %v\n", name, err)
+		} else {
+			fmt.Fprintf(&builder, "[+]%s ok\n", name)
+		}
+	}
+	s.lock.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if failed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, builder.String())
+		return
+	}
+	if _, verbose := r.URL.Query()["verbose"]; verbose {
+		fmt.Fprint(w, builder.String())
+	}
+	fmt.Fprint(w, "ok")
+}
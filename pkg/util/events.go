@@ -0,0 +1,29 @@
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	kubeClient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+	mpascheme "multidim-pod-autoscaler/pkg/client/clientset/versioned/scheme"
+)
+
+// NewEventRecorder 返回一个新的 EvenetRecorder 用于事件上报
+// component 为上报事件的组件名
+func NewEventRecorder(client kubeClient.Interface, component string) record.EventRecorder {
+	utilruntime.Must(mpascheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(klog.V(4).Infof)
+	if _, isFake := client.(*fake.Clientset); !isFake {
+		eventBroadcaster.StartRecordingToSink(
+			&clientv1.EventSinkImpl{
+				Interface: clientv1.New(client.CoreV1().RESTClient()).Events(""),
+			},
+		)
+	}
+	return eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+}
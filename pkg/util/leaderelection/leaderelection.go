@@ -0,0 +1,187 @@
+// Package leaderelection 为 recommender、updater 等组件提供基于 Lease 的选主
+// 多副本部署时只有 leader 执行主流程, 其他副本保持 informer 缓存同步, 随时接替
+package leaderelection
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	kubeClient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+	"multidim-pod-autoscaler/pkg/util/metrics"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// metricsNamespace 选主相关 metrics 的namespace
+	metricsNamespace = metrics.TopNamespace + "leader_election"
+)
+
+var (
+	isLeader = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "is_leader",
+			Help:      "当前副本是否为leader(1 为 leader)",
+		},
+		[]string{"component"},
+	)
+	leaderTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "transitions_total",
+			Help:      "当前副本观测到的 leader 变更次数",
+		},
+		[]string{"component"},
+	)
+)
+
+// Config 选主配置
+type Config struct {
+	// Enabled 是否开启选主; 未开启时直接执行主流程
+	Enabled bool
+	// LeaseDuration 非leader副本在获取leader身份前需要等待的时间
+	LeaseDuration time.Duration
+	// RenewDeadline leader 续约的超时时间
+	RenewDeadline time.Duration
+	// RetryPeriod 获取或续约 leader 身份的重试间隔
+	RetryPeriod time.Duration
+	// ResourceNamespace、ResourceName 为 Lease 对象的命名空间和名字
+	ResourceNamespace string
+	ResourceName      string
+}
+
+// RegisterFlags 在 fs 中注册选主相关的命令行参数
+// defaultResourceName 为 Lease 对象的默认名字(每个组件不同)
+func RegisterFlags(fs *flag.FlagSet, defaultResourceName string) *Config {
+	config := &Config{}
+	fs.BoolVar(&config.Enabled, "leader-elect", true, "是否开启选主(多副本部署时只有leader执行主流程)")
+	fs.DurationVar(&config.LeaseDuration, "leader-elect-lease-duration", 15*time.Second, "非leader副本在获取leader身份前需要等待的时间")
+	fs.DurationVar(&config.RenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "leader续约的超时时间, 需小于 lease duration")
+	fs.DurationVar(&config.RetryPeriod, "leader-elect-retry-period", 2*time.Second, "获取或续约leader身份的重试间隔")
+	fs.StringVar(&config.ResourceNamespace, "leader-elect-resource-namespace", "kube-system", "选主使用的 Lease 对象的命名空间")
+	fs.StringVar(&config.ResourceName, "leader-elect-resource-name", defaultResourceName, "选主使用的 Lease 对象的名字")
+	return config
+}
+
+// RegisterMetrics 注册选主相关的 metrics
+func RegisterMetrics() {
+	prometheus.MustRegister(isLeader, leaderTransitions)
+}
+
+// Run 执行组件的主流程 run, 直到 ctx 结束且 run 返回
+// 开启选主时, 只有成为 leader 后才执行 run; 失去 leader 身份时退出进程(由 Deployment 重启)
+// ctx 结束(正常退出)时先等待 run 返回(如: 处理中的驱逐完成), 期间继续续约, 之后再释放 leader 身份,
+// 避免新的 leader 在旧 leader 退出前开始执行主流程
+// component 为组件名, 用于日志及 metrics
+func Run(ctx context.Context, client kubeClient.Interface, config *Config, component string, run func(ctx context.Context)) {
+	if !config.Enabled {
+		run(ctx)
+		return
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("failed to get hostname for leader election: %v", err)
+	}
+	// 同一节点上可能运行多个副本, 加上 uuid 保证唯一
+	identity := hostname + "_" + string(uuid.NewUUID())
+
+	resourceLock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		config.ResourceNamespace,
+		config.ResourceName,
+		client.CoreV1(),
+		client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		klog.Fatalf("failed to create resource lock for leader election: %v", err)
+	}
+
+	// electionCtx 结束时 RunOrDie 释放 leader 身份; 它只在 ctx 结束且 run 返回后才被取消
+	electionCtx, cancelElection := context.WithCancel(context.Background())
+	defer cancelElection()
+	// started 在开始执行 run 时关闭, done 在 run 返回时关闭
+	// stopping 为 true 后不再开始执行 run
+	started, done := make(chan struct{}), make(chan struct{})
+	var lock sync.Mutex
+	stopping := false
+	go func() {
+		<-ctx.Done()
+		lock.Lock()
+		stopping = true
+		lock.Unlock()
+		select {
+		case <-started:
+			<-done
+		default:
+		}
+		cancelElection()
+	}()
+
+	isLeader.WithLabelValues(component).Set(0)
+	leaderelection.RunOrDie(electionCtx, leaderelection.LeaderElectionConfig{
+		Lock:            resourceLock,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            component,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				lock.Lock()
+				if stopping {
+					lock.Unlock()
+					return
+				}
+				close(started)
+				lock.Unlock()
+				defer close(done)
+				klog.Infof("%s(%s) became the leader, start running", component, identity)
+				isLeader.WithLabelValues(component).Set(1)
+				// run 在 ctx 结束(正常退出)或失去 leader 身份时结束
+				runCtx, cancelRun := context.WithCancel(leaderCtx)
+				defer cancelRun()
+				go func() {
+					select {
+					case <-ctx.Done():
+						cancelRun()
+					case <-runCtx.Done():
+					}
+				}()
+				run(runCtx)
+			},
+			OnStoppedLeading: func() {
+				isLeader.WithLabelValues(component).Set(0)
+				if ctx.Err() != nil {
+					klog.Infof("%s(%s) released the leadership, shutting down", component, identity)
+					return
+				}
+				klog.Fatalf("%s(%s) lost the leadership, exiting", component, identity)
+			},
+			OnNewLeader: func(currentLeader string) {
+				leaderTransitions.WithLabelValues(component).Inc()
+				if currentLeader == identity {
+					return
+				}
+				klog.Infof("%s leader changed to %s", component, currentLeader)
+			},
+		},
+	})
+}
+
+// String 返回选主配置的描述, 用于日志
+func (c *Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("lease=%s/%s,leaseDuration=%v,renewDeadline=%v,retryPeriod=%v",
+		c.ResourceNamespace, c.ResourceName, c.LeaseDuration, c.RenewDeadline, c.RetryPeriod)
+}
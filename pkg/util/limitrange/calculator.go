@@ -31,15 +31,14 @@ func (l *limitCalculator) GetPodLimitRangeItem(namespace string) (*corev1.LimitR
 	return l.getLimitRangeItem(namespace, corev1.LimitTypePod)
 }
 
-// NewCalculator 返回一个新的 limit range Calculator
-func NewCalculator(factory informers.SharedInformerFactory) (Calculator, error) {
+// NewCalculator 返回一个新的 limit range Calculator, stopCh 关闭时停止 informer
+func NewCalculator(factory informers.SharedInformerFactory, stopCh <-chan struct{}) (Calculator, error) {
 	if factory == nil {
 		return nil, fmt.Errorf("NewLimitRangeCalculator required a SharedInformerFactory but got nil")
 	}
 	limitRangeLister := factory.Core().V1().LimitRanges().Lister()
 
 	// 需要等待informer的store中同步得到limitrange 数据
-	stopCh := make(chan struct{})
 	factory.Start(stopCh)
 
 	for _, ok := range factory.WaitForCacheSync(stopCh) {
@@ -60,7 +59,7 @@ func (l *limitCalculator) getLimitRangeItem(namespace string, resourceType corev
 
 	for _, limitRange := range limitRanges {
 		for _, limitItem := range limitRange.Spec.Limits {
-			if limitItem.Type == resourceType && (limitItem.Min != nil || limitItem.Max != nil || limitItem.Default != nil) {
+			if limitItem.Type == resourceType && (limitItem.Min != nil || limitItem.Max != nil || limitItem.Default != nil || limitItem.MaxLimitRequestRatio != nil) {
 				if limitItem.Default != nil {
 					targetLimitRangeItem.Default = limitItem.Default
 				}
@@ -72,10 +71,13 @@ func (l *limitCalculator) getLimitRangeItem(namespace string, resourceType corev
 				targetLimitRangeItem.Max = updateResource(targetLimitRangeItem.Max, limitItem.Max, corev1.ResourceCPU, chooseMinUpperBound)
 				// 更新 memory 的最小上界
 				targetLimitRangeItem.Max = updateResource(targetLimitRangeItem.Max, limitItem.Max, corev1.ResourceMemory, chooseMinUpperBound)
+				// 更新 CPU、memory 的最小 limit:request 比例
+				targetLimitRangeItem.MaxLimitRequestRatio = updateResource(targetLimitRangeItem.MaxLimitRequestRatio, limitItem.MaxLimitRequestRatio, corev1.ResourceCPU, chooseMinUpperBound)
+				targetLimitRangeItem.MaxLimitRequestRatio = updateResource(targetLimitRangeItem.MaxLimitRequestRatio, limitItem.MaxLimitRequestRatio, corev1.ResourceMemory, chooseMinUpperBound)
 			}
 		}
 	}
-	if targetLimitRangeItem.Max != nil || targetLimitRangeItem.Min != nil || targetLimitRangeItem.Default != nil {
+	if targetLimitRangeItem.Max != nil || targetLimitRangeItem.Min != nil || targetLimitRangeItem.Default != nil || targetLimitRangeItem.MaxLimitRequestRatio != nil {
 		return targetLimitRangeItem, nil
 	}
 	return nil, nil
@@ -2,22 +2,10 @@ package metrics
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"k8s.io/klog"
-	"net/http"
 	"time"
 )
 
-// InitializeMetrics 初始化 prometheus 处理 "/metrics" 的请求
-func InitializeMetrics(address string) {
-	go func() {
-		// 注册 "/metrics" 请求 的 handler
-		http.Handle("/metrics", promhttp.Handler())
-		// "/" 地址的请求 handler 为空
-		err := http.ListenAndServe(address, nil)
-		klog.Fatalf("Error occured while start metrics: %v", err)
-	}()
-}
+// "/metrics" 的请求由 diagnostics.Server 处理
 
 // CreateExecutionTimeMetric 创建一个新的 执行时间 度量直方图序列，一个histogram对应Buckets中的一个上界
 // 对应 histogram 只在创建响应的 Observation 时由prometheus自动创建
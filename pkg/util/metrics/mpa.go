@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+)
+
+// MpaLabels 按 MPA 划分的 metrics 的标签
+// 标签的基数由 MPA 的个数(namespace/name)限定, MPA 被删除后需要通过 MpaSeries 删除对应的时间序列
+var MpaLabels = []string{"namespace", "name"}
+
+// labelDeleter 可以按标签值删除时间序列的 metrics(GaugeVec、CounterVec 等)
+type labelDeleter interface {
+	DeleteLabelValues(lvs ...string) bool
+}
+
+// mpaSeriesEntry MPA 产生过的一个时间序列
+type mpaSeriesEntry struct {
+	vec         labelDeleter
+	labelValues []string
+}
+
+// mpaSeriesId 时间序列在 MPA 内的唯一标识
+type mpaSeriesId struct {
+	vec         labelDeleter
+	labelValues string
+}
+
+// MpaSeries 记录每个 MPA 产生过的时间序列(标签值以 namespace、name 开头), 用于在 MPA 被删除后删除它们
+type MpaSeries struct {
+	lock   sync.Mutex
+	series map[string]map[mpaSeriesId]mpaSeriesEntry
+}
+
+// NewMpaSeries 创建 MpaSeries
+func NewMpaSeries() *MpaSeries {
+	return &MpaSeries{series: map[string]map[mpaSeriesId]mpaSeriesEntry{}}
+}
+
+// Track 记录 MPA 产生的时间序列, labelValues 以 MPA 的 namespace、name 开头
+func (s *MpaSeries) Track(vec labelDeleter, labelValues ...string) {
+	if len(labelValues) < 2 {
+		return
+	}
+	key := labelValues[0] + "/" + labelValues[1]
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entries, existed := s.series[key]
+	if !existed {
+		entries = map[mpaSeriesId]mpaSeriesEntry{}
+		s.series[key] = entries
+	}
+	entries[mpaSeriesId{vec: vec, labelValues: strings.Join(labelValues, "\x00")}] = mpaSeriesEntry{vec: vec, labelValues: labelValues}
+}
+
+// ForgetVec 删除 MPA 在 vec 中产生的时间序列(如: 容器被删除后不再上报的推荐值)
+func (s *MpaSeries) ForgetVec(vec labelDeleter, namespace, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entries := s.series[namespace+"/"+name]
+	for id, entry := range entries {
+		if entry.vec == vec {
+			entry.vec.DeleteLabelValues(entry.labelValues...)
+			delete(entries, id)
+		}
+	}
+}
+
+// Forget 删除 MPA 产生的所有时间序列
+func (s *MpaSeries) Forget(namespace, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.forget(namespace + "/" + name)
+}
+
+// ForgetExcept 删除不在 existing(namespace/name)中的 MPA 产生的所有时间序列
+// 用于没有 MPA 删除事件的组件(如 updater)在每轮处理后清理已被删除的 MPA
+func (s *MpaSeries) ForgetExcept(existing map[string]bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key := range s.series {
+		if !existing[key] {
+			s.forget(key)
+		}
+	}
+}
+
+func (s *MpaSeries) forget(key string) {
+	for _, entry := range s.series[key] {
+		entry.vec.DeleteLabelValues(entry.labelValues...)
+	}
+	delete(s.series, key)
+}
@@ -15,6 +15,7 @@ import (
 	clientset "multidim-pod-autoscaler/pkg/client/clientset/versioned"
 	clientType "multidim-pod-autoscaler/pkg/client/clientset/versioned/typed/autoscaling/v1"
 	lister "multidim-pod-autoscaler/pkg/client/listers/autoscaling/v1"
+	"multidim-pod-autoscaler/pkg/target"
 	"multidim-pod-autoscaler/pkg/util/patch"
 	"time"
 )
@@ -48,7 +49,7 @@ func NewMpasLister(
 	// controller 启动 reflector(通过lister watcher获取资源数据并存入DeltaFIFO, 最终存储到local store)
 	go controller.Run(stopChan)
 	// 等待本地缓存填充完成
-	if !cache.WaitForCacheSync(make(chan struct{}), controller.HasSynced) {
+	if !cache.WaitForCacheSync(stopChan, controller.HasSynced) {
 		klog.Fatalf("Failed to sync MPA cache during initialization")
 	} else {
 		klog.Infof("Initial MPA synced successful")
@@ -66,17 +67,63 @@ func GetMpaUpdateMode(mpa *mpaTypes.MultidimPodAutoscaler) mpaTypes.UpdateMode {
 	return *mpa.Spec.UpdatePolicy.UpdateMode
 }
 
-// GetMpaLatestCondition 获取mpa最新的状态条件
+// GetMpaLatestCondition 获取mpa最新的推荐方案状态条件
+// 只考虑 RecommendationProvided 和 RecommendationSkipped, 其他状态条件仅用于描述
 func GetMpaLatestCondition(mpa *mpaTypes.MultidimPodAutoscaler) mpaTypes.MultidimPodAutoscalerCondition {
-	if mpa.Status.Conditions == nil || len(mpa.Status.Conditions) <= 0 {
-		return mpaTypes.MultidimPodAutoscalerCondition{
-			Type:               mpaTypes.RecommendationProvided,
-			Status:             corev1.ConditionTrue,
-			LastTransitionTime: metav1.Now(),
-			Reason:             "NoConditionGenerated",
+	for i := len(mpa.Status.Conditions) - 1; i >= 0; i -= 1 {
+		conditionType := mpa.Status.Conditions[i].Type
+		if conditionType == mpaTypes.RecommendationProvided || conditionType == mpaTypes.RecommendationSkipped {
+			return mpa.Status.Conditions[i]
 		}
 	}
-	return mpa.Status.Conditions[len(mpa.Status.Conditions)-1]
+	return mpaTypes.MultidimPodAutoscalerCondition{
+		Type:               mpaTypes.RecommendationProvided,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "NoConditionGenerated",
+	}
+}
+
+// GetMpaCondition 获取mpa指定类型的状态条件
+// 不存在时返回 nil
+func GetMpaCondition(status *mpaTypes.MultidimPodAutoscalerStatus, conditionType mpaTypes.MultidimPodAutoscalerConditionType) *mpaTypes.MultidimPodAutoscalerCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetMpaCondition 设置mpa指定类型的状态条件(同一类型只保留一个)
+// 状态不变时保留原有的 LastTransitionTime; 返回状态条件是否发生了变化
+func SetMpaCondition(
+	status *mpaTypes.MultidimPodAutoscalerStatus,
+	conditionType mpaTypes.MultidimPodAutoscalerConditionType,
+	conditionStatus corev1.ConditionStatus,
+	reason, message string,
+) bool {
+	existing := GetMpaCondition(status, conditionType)
+	if existing == nil {
+		status.Conditions = append(status.Conditions, mpaTypes.MultidimPodAutoscalerCondition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			LastTransitionTime: metav1.Now(),
+			Reason:             reason,
+			Message:            message,
+		})
+		return true
+	}
+	if existing.Status == conditionStatus && existing.Reason == reason && existing.Message == message {
+		return false
+	}
+	if existing.Status != conditionStatus {
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Status = conditionStatus
+	existing.Reason = reason
+	existing.Message = message
+	return true
 }
 
 // GetContainerResourcePolicy 获取指定容器的资源策略
@@ -99,6 +146,26 @@ func GetContainerResourcePolicy(containerName string, podPolicy *mpaTypes.PodRes
 	return defaultPolicy
 }
 
+// GetContainerScalingMode 获取伸缩器是否应用到容器
+// 默认为应用(Auto)
+func GetContainerScalingMode(containerName string, podPolicy *mpaTypes.PodResourcePolicy) mpaTypes.ContainerScalingMode {
+	containerPolicy := GetContainerResourcePolicy(containerName, podPolicy)
+	if containerPolicy == nil || containerPolicy.Mode == nil {
+		return mpaTypes.ContainerScalingModeAuto
+	}
+	return *containerPolicy.Mode
+}
+
+// GetContainerControlledResources 获取容器被伸缩器控制的资源种类
+// 默认为 [ResourceCPU, ResourceMemory]
+func GetContainerControlledResources(containerName string, podPolicy *mpaTypes.PodResourcePolicy) []corev1.ResourceName {
+	containerPolicy := GetContainerResourcePolicy(containerName, podPolicy)
+	if containerPolicy == nil || containerPolicy.ControlledResources == nil {
+		return []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+	}
+	return *containerPolicy.ControlledResources
+}
+
 // GetContainerControlledMode 获取容器 request limit 的控制方式
 // 默认为 request limit 同时控制(按比例伸缩)
 func GetContainerControlledMode(containerName string, podPolicy *mpaTypes.PodResourcePolicy) mpaTypes.ContainerControlledMode {
@@ -109,15 +176,52 @@ func GetContainerControlledMode(containerName string, podPolicy *mpaTypes.PodRes
 	return *containerPolicy.ControlledMode
 }
 
+// PodMpaMatch 为 pod 与一组 mpa 的匹配结果
+type PodMpaMatch struct {
+	// Controlling 为管理该pod的mpa(优先级最高), 可能为 nil
+	Controlling *MpaWithSelector
+	// Overlapped 为同样匹配到该pod, 但优先级低于 Controlling 的mpa
+	Overlapped []*MpaWithSelector
+	// Mismatched 为 label selector 匹配到了该pod, 但 targetRef 不是该pod属主的mpa
+	Mismatched []*MpaWithSelector
+}
+
 // GetControllingMpaForPod 获取管理指定pod的mpa(with labelSelector)
-func GetControllingMpaForPod(pod *corev1.Pod, mpas []*MpaWithSelector) *MpaWithSelector {
-	var controlling *MpaWithSelector
+// fetcher 不为空时, 要求 pod 的属主链中包含 mpa 的 targetRef
+func GetControllingMpaForPod(pod *corev1.Pod, mpas []*MpaWithSelector, fetcher target.MpaTargetSelectorFetch) *MpaWithSelector {
+	return MatchPodToMpas(pod, mpas, fetcher).Controlling
+}
+
+// MatchPodToMpas 获取指定pod与给定mpas的匹配结果
+// fetcher 为空时不检查pod的属主
+func MatchPodToMpas(pod *corev1.Pod, mpas []*MpaWithSelector, fetcher target.MpaTargetSelectorFetch) *PodMpaMatch {
+	result := &PodMpaMatch{}
+	matched := make([]*MpaWithSelector, 0)
 	for _, mpa := range mpas {
-		if PodMatchesMpa(pod, mpa) && (controlling == nil || strongerMpa(mpa.Mpa, controlling.Mpa)) {
-			controlling = mpa
+		if !PodMatchesMpa(pod, mpa) {
+			continue
+		}
+		if fetcher != nil {
+			controlled, err := fetcher.PodControlledByTarget(pod, mpa.Mpa)
+			if err != nil {
+				klog.V(4).Infof("cannot verify the owner of pod(%s/%s) for MPA(%s/%s): %v", pod.Namespace, pod.Name, mpa.Mpa.Namespace, mpa.Mpa.Name, err)
+			}
+			if !controlled {
+				result.Mismatched = append(result.Mismatched, mpa)
+				continue
+			}
+		}
+		matched = append(matched, mpa)
+		if result.Controlling == nil || strongerMpa(mpa.Mpa, result.Controlling.Mpa) {
+			result.Controlling = mpa
+		}
+	}
+	for _, mpa := range matched {
+		if mpa != result.Controlling {
+			result.Overlapped = append(result.Overlapped, mpa)
 		}
 	}
-	return controlling
+	return result
 }
 
 // UpdateMpaStatusIfNeeded 根据新旧状态是否一致来确定是否需要更新MPA Object的状态(状态变化时更新，即不一致 -> 一致)
@@ -152,13 +256,26 @@ func PodLabelsMatchMpa(podNamespece string, labels labels.Set, mpaNamespace stri
 	return mpaSelector.Matches(labels)
 }
 
+// GetMpaPriority 获取mpa的优先级(spec.priority), 默认为 0
+func GetMpaPriority(mpa *mpaTypes.MultidimPodAutoscaler) int32 {
+	if mpa.Spec.Priority == nil {
+		return 0
+	}
+	return *mpa.Spec.Priority
+}
+
 // strongerMpa 判断管理一个相同的pod的两个MPA对象的优先级
-// 1. 创建时间早的优先
-// 2. name 字母序靠前的优先
+// 1. spec.priority 大的优先
+// 2. 创建时间早的优先
+// 3. name 字母序靠前的优先
 func strongerMpa(a, b *mpaTypes.MultidimPodAutoscaler) bool {
 	if b == nil {
 		return true
 	}
+	// 比较用户指定的优先级
+	if aPriority, bPriority := GetMpaPriority(a), GetMpaPriority(b); aPriority != bPriority {
+		return aPriority > bPriority
+	}
 	// 比较创建时间
 	var aTime, bTime metav1.Time
 	aTime = a.GetCreationTimestamp()
@@ -7,6 +7,7 @@ import (
 	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
 	lister "multidim-pod-autoscaler/pkg/client/listers/autoscaling/v1"
 	"multidim-pod-autoscaler/pkg/target"
+	"multidim-pod-autoscaler/pkg/util/policy"
 )
 
 // Matcher 返回与给定pod匹配的MPA Object
@@ -18,13 +19,16 @@ type Matcher interface {
 type matcher struct {
 	mpaLister       lister.MultidimPodAutoscalerLister
 	selectorFetcher target.MpaTargetSelectorFetch
+	policyResolver  policy.Resolver
 }
 
 // NewMatcher 返回一个新的Matcher
-func NewMatcher(mpaLister lister.MultidimPodAutoscalerLister, selectorFetcher target.MpaTargetSelectorFetch) Matcher {
+// 返回的MPA的 spec 为合并默认策略(policyResolver)后生效的配置
+func NewMatcher(mpaLister lister.MultidimPodAutoscalerLister, selectorFetcher target.MpaTargetSelectorFetch, policyResolver policy.Resolver) Matcher {
 	return &matcher{
 		mpaLister:       mpaLister,
 		selectorFetcher: selectorFetcher,
+		policyResolver:  policyResolver,
 	}
 }
 
@@ -38,6 +42,7 @@ func (m *matcher) GetPodMatchingMpa(pod *corev1.Pod) *mpaTypes.MultidimPodAutosc
 
 	mpasWithSelector := make([]*MpaWithSelector, 0)
 	for _, mpa := range mpas {
+		mpa, _ = m.policyResolver.Resolve(mpa)
 		// 如果该MPA不需要更新，跳过
 		if GetMpaUpdateMode(mpa) == mpaTypes.UpdateModeOff {
 			continue
@@ -56,8 +61,8 @@ func (m *matcher) GetPodMatchingMpa(pod *corev1.Pod) *mpaTypes.MultidimPodAutosc
 			},
 		)
 	}
-	// 选择匹配的MPA Object
-	targetMpa := GetControllingMpaForPod(pod, mpasWithSelector)
+	// 选择匹配的MPA Object(pod 的属主链需指向 MPA 的 targetRef)
+	targetMpa := GetControllingMpaForPod(pod, mpasWithSelector, m.selectorFetcher)
 
 	if targetMpa != nil {
 		return targetMpa.Mpa
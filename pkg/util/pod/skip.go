@@ -0,0 +1,164 @@
+package pod
+
+import (
+	"fmt"
+	configv1alpha1 "multidim-pod-autoscaler/pkg/apis/config/v1alpha1"
+	"multidim-pod-autoscaler/pkg/util/annotations"
+	"sync"
+	"time"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	kubeClient "k8s.io/client-go/kubernetes"
+	admissionlisters "k8s.io/client-go/listers/admissionregistration/v1"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// WebhookConfigName admission 注册的 MutatingWebhookConfiguration 名
+const WebhookConfigName = "mpa-webhook-config"
+
+// SkipFilter 判断 pod 是否不受 MPA 管理
+// admission 放行这些 pod 而不修改其资源, updater 不能驱逐它们(否则每一轮都会驱逐), recommender 也不将它们计入 mpa 控制的 pods
+type SkipFilter interface {
+	// Skipped 判断 pod 是否不受 MPA 管理
+	Skipped(pod *corev1.Pod) bool
+}
+
+// skipFilter 实现 SkipFilter 接口
+// 与 admission 的判断一致: pod 或命名空间的 mpa.k8s.io/skip annotation 为 true,
+// 或 pod 不匹配 admission 注册的 webhook 的 namespaceSelector / objectSelector(api-server 不会为其调用 webhook)
+// webhook 配置不存在时使用默认的 selector
+type skipFilter struct {
+	namespaceLister listers.NamespaceLister
+	webhookLister   admissionlisters.MutatingWebhookConfigurationLister
+
+	lock sync.Mutex
+	// selectors 为 resourceVersion 对应的 webhook 配置解析后的 selector
+	resourceVersion string
+	selectors       []webhookSelector
+}
+
+// webhookSelector 一个 webhook 的 namespaceSelector 及 objectSelector
+type webhookSelector struct {
+	namespace labels.Selector
+	object    labels.Selector
+}
+
+// NewSkipFilter 创建 SkipFilter, 并等待命名空间及 webhook 配置的缓存同步完成
+// namespace 不为空时只关注该命名空间
+func NewSkipFilter(kubeclient kubeClient.Interface, namespace string, stopCh <-chan struct{}) (SkipFilter, error) {
+	selector := fields.Everything()
+	if namespace != "" {
+		selector = fields.OneTermEqualSelector("metadata.name", namespace)
+	}
+	namespaceListWatch := cache.NewListWatchFromClient(kubeclient.CoreV1().RESTClient(), "namespaces", metav1.NamespaceAll, selector)
+	namespaceInformer := cache.NewSharedIndexInformer(namespaceListWatch, &corev1.Namespace{}, time.Hour, cache.Indexers{})
+	webhookListWatch := cache.NewListWatchFromClient(kubeclient.AdmissionregistrationV1().RESTClient(), "mutatingwebhookconfigurations",
+		metav1.NamespaceAll, fields.OneTermEqualSelector("metadata.name", WebhookConfigName))
+	webhookInformer := cache.NewSharedIndexInformer(webhookListWatch, &admissionregistration.MutatingWebhookConfiguration{}, time.Hour, cache.Indexers{})
+	go namespaceInformer.Run(stopCh)
+	go webhookInformer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, namespaceInformer.HasSynced, webhookInformer.HasSynced) {
+		return nil, fmt.Errorf("failed to sync namespace and webhook configuration cache during initialization")
+	}
+	return &skipFilter{
+		namespaceLister: listers.NewNamespaceLister(namespaceInformer.GetIndexer()),
+		webhookLister:   admissionlisters.NewMutatingWebhookConfigurationLister(webhookInformer.GetIndexer()),
+	}, nil
+}
+
+// Skipped 实现 SkipFilter 接口
+func (f *skipFilter) Skipped(pod *corev1.Pod) bool {
+	if annotations.IsPodSkipped(pod) {
+		return true
+	}
+	var namespaceLabels labels.Set
+	namespace, err := f.namespaceLister.Get(pod.Namespace)
+	if err == nil {
+		if annotations.IsNamespaceSkipped(namespace) {
+			return true
+		}
+		namespaceLabels = namespace.Labels
+	} else if !errors.IsNotFound(err) {
+		klog.Errorf("failed to get namespace %s: %v", pod.Namespace, err)
+	}
+	// 任意一个 webhook 匹配该 pod 时, admission 会处理它
+	for _, selector := range f.getSelectors() {
+		if selector.namespace.Matches(namespaceLabels) && selector.object.Matches(labels.Set(pod.Labels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// getSelectors 返回 webhook 配置中的 selector, webhook 配置变化时重新解析
+func (f *skipFilter) getSelectors() []webhookSelector {
+	webhookConfig, err := f.webhookLister.Get(WebhookConfigName)
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("failed to get webhook configuration %s: %v", WebhookConfigName, err)
+	}
+	resourceVersion := ""
+	if webhookConfig != nil {
+		resourceVersion = webhookConfig.ResourceVersion
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.selectors != nil && f.resourceVersion == resourceVersion {
+		return f.selectors
+	}
+	selectors := make([]webhookSelector, 0)
+	if webhookConfig == nil {
+		// webhook 配置不存在(admission 未运行或已删除配置), 使用默认的 selector
+		namespaceSelector, _ := labels.Parse(configv1alpha1.DefaultWebhookNamespaceSelector)
+		objectSelector, _ := labels.Parse(configv1alpha1.DefaultWebhookObjectSelector)
+		selectors = append(selectors, webhookSelector{namespace: namespaceSelector, object: objectSelector})
+	} else {
+		for _, webhook := range webhookConfig.Webhooks {
+			namespaceSelector, err := labelSelectorAsSelector(webhook.NamespaceSelector)
+			if err != nil {
+				klog.Errorf("invalid namespaceSelector of webhook %s: %v", webhook.Name, err)
+				continue
+			}
+			objectSelector, err := labelSelectorAsSelector(webhook.ObjectSelector)
+			if err != nil {
+				klog.Errorf("invalid objectSelector of webhook %s: %v", webhook.Name, err)
+				continue
+			}
+			selectors = append(selectors, webhookSelector{namespace: namespaceSelector, object: objectSelector})
+		}
+	}
+	f.resourceVersion = resourceVersion
+	f.selectors = selectors
+	return selectors
+}
+
+// labelSelectorAsSelector 与 api-server 一致, 为空的 selector 匹配所有对象
+func labelSelectorAsSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// FilterSkippedPods 过滤不受 MPA 管理的 pods, filter 为 nil 时不过滤
+func FilterSkippedPods(pods []*corev1.Pod, filter SkipFilter) []*corev1.Pod {
+	if filter == nil {
+		return pods
+	}
+	result := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if filter.Skipped(pod) {
+			klog.V(4).Infof("pod %s/%s skipped, it is opted out of MPA", pod.Namespace, pod.Name)
+			continue
+		}
+		result = append(result, pod)
+	}
+	return result
+}
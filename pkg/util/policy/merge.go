@@ -0,0 +1,135 @@
+package policy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+)
+
+// Merge 将 spec 合并到 policies 之上, 返回生效的配置
+// policies 按顺序合并(后面的策略覆盖前面的策略), spec 中指定的配置覆盖所有策略
+func Merge(spec *mpaTypes.MultidimPodAutoscalerSpec, policies ...*mpaTypes.DefaultPolicy) mpaTypes.DefaultPolicy {
+	merged := mpaTypes.DefaultPolicy{}
+	for _, policy := range policies {
+		merged = overlay(merged, policy)
+	}
+	return overlay(merged, &mpaTypes.DefaultPolicy{
+		UpdatePolicy:   spec.UpdatePolicy,
+		ResourcePolicy: spec.ResourcePolicy,
+		Algorithm:      spec.Algorithm,
+		Replicas:       spec.Replicas,
+	})
+}
+
+// ApplyTo 用生效的配置替换 spec 中对应的字段
+func ApplyTo(spec *mpaTypes.MultidimPodAutoscalerSpec, effective *mpaTypes.DefaultPolicy) {
+	effective = effective.DeepCopy()
+	spec.UpdatePolicy = effective.UpdatePolicy
+	spec.ResourcePolicy = effective.ResourcePolicy
+	spec.Algorithm = effective.Algorithm
+	spec.Replicas = effective.Replicas
+}
+
+// overlay 返回 top 覆盖 base 后的配置, 不修改 base 和 top
+func overlay(base mpaTypes.DefaultPolicy, top *mpaTypes.DefaultPolicy) mpaTypes.DefaultPolicy {
+	if top == nil {
+		return base
+	}
+	result := *base.DeepCopy()
+	if top.UpdatePolicy != nil && top.UpdatePolicy.UpdateMode != nil && *top.UpdatePolicy.UpdateMode != "" {
+		result.UpdatePolicy = top.UpdatePolicy.DeepCopy()
+	}
+	if top.ResourcePolicy != nil {
+		result.ResourcePolicy = overlayResourcePolicy(result.ResourcePolicy, top.ResourcePolicy)
+	}
+	if top.Algorithm != nil {
+		result.Algorithm = overlayAlgorithm(result.Algorithm, top.Algorithm)
+	}
+	if top.Replicas != nil {
+		if result.Replicas == nil {
+			result.Replicas = &mpaTypes.ReplicaBounds{}
+		}
+		if top.Replicas.MinReplicas != nil {
+			min := *top.Replicas.MinReplicas
+			result.Replicas.MinReplicas = &min
+		}
+		if top.Replicas.MaxReplicas != nil {
+			max := *top.Replicas.MaxReplicas
+			result.Replicas.MaxReplicas = &max
+		}
+	}
+	return result
+}
+
+// overlayResourcePolicy 按容器名合并容器策略
+// 同名的容器策略中 top 指定的字段覆盖 base, 只在 top 中出现的容器策略追加到最后
+func overlayResourcePolicy(base, top *mpaTypes.PodResourcePolicy) *mpaTypes.PodResourcePolicy {
+	if base == nil {
+		return top.DeepCopy()
+	}
+	for _, topContainer := range top.ContainerPolicies {
+		merged := false
+		for i := range base.ContainerPolicies {
+			if base.ContainerPolicies[i].ContainerName == topContainer.ContainerName {
+				overlayContainerPolicy(&base.ContainerPolicies[i], &topContainer)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			base.ContainerPolicies = append(base.ContainerPolicies, *topContainer.DeepCopy())
+		}
+	}
+	return base
+}
+
+// overlayContainerPolicy 用 top 中指定的字段覆盖 base
+// 资源上下限按资源种类合并
+func overlayContainerPolicy(base, top *mpaTypes.ContainerResourcePolicy) {
+	if top.Mode != nil {
+		mode := *top.Mode
+		base.Mode = &mode
+	}
+	base.MinAllowed = overlayResourceList(base.MinAllowed, top.MinAllowed)
+	base.MaxAllowed = overlayResourceList(base.MaxAllowed, top.MaxAllowed)
+	if top.ExpRespTime != 0 {
+		base.ExpRespTime = top.ExpRespTime
+	}
+	if top.ControlledMode != nil {
+		mode := *top.ControlledMode
+		base.ControlledMode = &mode
+	}
+	if top.ControlledResources != nil {
+		resources := append([]corev1.ResourceName{}, *top.ControlledResources...)
+		base.ControlledResources = &resources
+	}
+}
+
+func overlayResourceList(base, top corev1.ResourceList) corev1.ResourceList {
+	if len(top) == 0 {
+		return base
+	}
+	if base == nil {
+		base = corev1.ResourceList{}
+	}
+	for name, quantity := range top {
+		base[name] = quantity.DeepCopy()
+	}
+	return base
+}
+
+// overlayAlgorithm 合并推荐算法
+// top 未指定算法名时沿用 base 的算法; 算法相同时 top 未指定的参数沿用 base 的参数,
+// 算法不同时 base 的参数不再适用
+func overlayAlgorithm(base, top *mpaTypes.RecommendationAlgorithmSpec) *mpaTypes.RecommendationAlgorithmSpec {
+	if base == nil {
+		return top.DeepCopy()
+	}
+	result := top.DeepCopy()
+	if result.Name == "" {
+		result.Name = base.Name
+	}
+	if result.Params == nil && result.Name == base.Name {
+		result.Params = base.Params.DeepCopy()
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"testing"
+
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func updatePolicy(mode mpaTypes.UpdateMode) *mpaTypes.PodUpdatePolicy {
+	return &mpaTypes.PodUpdatePolicy{UpdateMode: &mode}
+}
+
+func replicas(min, max int32) *mpaTypes.ReplicaBounds {
+	bounds := &mpaTypes.ReplicaBounds{}
+	if min > 0 {
+		bounds.MinReplicas = &min
+	}
+	if max > 0 {
+		bounds.MaxReplicas = &max
+	}
+	return bounds
+}
+
+func algorithm(name, params string) *mpaTypes.RecommendationAlgorithmSpec {
+	spec := &mpaTypes.RecommendationAlgorithmSpec{Name: name}
+	if params != "" {
+		spec.Params = &runtime.RawExtension{Raw: []byte(params)}
+	}
+	return spec
+}
+
+func containerPolicies(policies ...mpaTypes.ContainerResourcePolicy) *mpaTypes.PodResourcePolicy {
+	return &mpaTypes.PodResourcePolicy{ContainerPolicies: policies}
+}
+
+func TestMerge(t *testing.T) {
+	off := mpaTypes.ContainerScalingModeOff
+	cpu := func(value string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}
+	}
+
+	tests := []struct {
+		name     string
+		spec     mpaTypes.MultidimPodAutoscalerSpec
+		policies []*mpaTypes.DefaultPolicy
+		expected mpaTypes.DefaultPolicy
+	}{
+		{
+			name:     "no policies",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeAuto)},
+			expected: mpaTypes.DefaultPolicy{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeAuto)},
+		},
+		{
+			name:     "policy fills fields missing in spec",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{},
+			policies: []*mpaTypes.DefaultPolicy{{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeOff), Replicas: replicas(2, 0)}},
+			expected: mpaTypes.DefaultPolicy{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeOff), Replicas: replicas(2, 0)},
+		},
+		{
+			name: "later policy overrides earlier policy",
+			spec: mpaTypes.MultidimPodAutoscalerSpec{},
+			policies: []*mpaTypes.DefaultPolicy{
+				{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeOff)},
+				{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeAuto)},
+			},
+			expected: mpaTypes.DefaultPolicy{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeAuto)},
+		},
+		{
+			name:     "spec overrides policies",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeAuto)},
+			policies: []*mpaTypes.DefaultPolicy{{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeOff)}},
+			expected: mpaTypes.DefaultPolicy{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeAuto)},
+		},
+		{
+			name:     "empty update mode in spec does not override",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{UpdatePolicy: updatePolicy("")},
+			policies: []*mpaTypes.DefaultPolicy{{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeOff)}},
+			expected: mpaTypes.DefaultPolicy{UpdatePolicy: updatePolicy(mpaTypes.UpdateModeOff)},
+		},
+		{
+			name:     "replica bounds merged field by field",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{Replicas: replicas(0, 10)},
+			policies: []*mpaTypes.DefaultPolicy{{Replicas: replicas(2, 5)}},
+			expected: mpaTypes.DefaultPolicy{Replicas: replicas(2, 10)},
+		},
+		{
+			name: "container policies merged by container name",
+			spec: mpaTypes.MultidimPodAutoscalerSpec{ResourcePolicy: containerPolicies(
+				mpaTypes.ContainerResourcePolicy{ContainerName: "*", MaxAllowed: cpu("2")},
+				mpaTypes.ContainerResourcePolicy{ContainerName: "sidecar", Mode: &off},
+			)},
+			policies: []*mpaTypes.DefaultPolicy{{ResourcePolicy: containerPolicies(
+				mpaTypes.ContainerResourcePolicy{ContainerName: "*", MinAllowed: cpu("100m"), MaxAllowed: cpu("1"), ExpRespTime: 200},
+			)}},
+			expected: mpaTypes.DefaultPolicy{ResourcePolicy: containerPolicies(
+				mpaTypes.ContainerResourcePolicy{ContainerName: "*", MinAllowed: cpu("100m"), MaxAllowed: cpu("2"), ExpRespTime: 200},
+				mpaTypes.ContainerResourcePolicy{ContainerName: "sidecar", Mode: &off},
+			)},
+		},
+		{
+			name:     "algorithm params inherited when the name is omitted",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{Algorithm: algorithm("", "")},
+			policies: []*mpaTypes.DefaultPolicy{{Algorithm: algorithm("mmc", `{"responseTimeMs":100}`)}},
+			expected: mpaTypes.DefaultPolicy{Algorithm: algorithm("mmc", `{"responseTimeMs":100}`)},
+		},
+		{
+			name:     "algorithm params inherited for the same algorithm",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{Algorithm: algorithm("mmc", "")},
+			policies: []*mpaTypes.DefaultPolicy{{Algorithm: algorithm("mmc", `{"responseTimeMs":100}`)}},
+			expected: mpaTypes.DefaultPolicy{Algorithm: algorithm("mmc", `{"responseTimeMs":100}`)},
+		},
+		{
+			name:     "algorithm params overridden by spec",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{Algorithm: algorithm("mmc", `{"responseTimeMs":50}`)},
+			policies: []*mpaTypes.DefaultPolicy{{Algorithm: algorithm("mmc", `{"responseTimeMs":100}`)}},
+			expected: mpaTypes.DefaultPolicy{Algorithm: algorithm("mmc", `{"responseTimeMs":50}`)},
+		},
+		{
+			name:     "algorithm params dropped for another algorithm",
+			spec:     mpaTypes.MultidimPodAutoscalerSpec{Algorithm: algorithm("grpc", "")},
+			policies: []*mpaTypes.DefaultPolicy{{Algorithm: algorithm("mmc", `{"responseTimeMs":100}`)}},
+			expected: mpaTypes.DefaultPolicy{Algorithm: algorithm("grpc", "")},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policiesCopy := make([]*mpaTypes.DefaultPolicy, 0, len(tc.policies))
+			for _, policy := range tc.policies {
+				policiesCopy = append(policiesCopy, policy.DeepCopy())
+			}
+			specCopy := tc.spec.DeepCopy()
+
+			result := Merge(&tc.spec, tc.policies...)
+			if !equality.Semantic.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, result)
+			}
+			if !equality.Semantic.DeepEqual(tc.policies, policiesCopy) || !equality.Semantic.DeepEqual(&tc.spec, specCopy) {
+				t.Errorf("expected the spec and policies not to be modified")
+			}
+		})
+	}
+}
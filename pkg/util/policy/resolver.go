@@ -0,0 +1,196 @@
+package policy
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	kubeInformers "k8s.io/client-go/informers"
+	kubeClient "k8s.io/client-go/kubernetes"
+	coreListers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	mpaClientset "multidim-pod-autoscaler/pkg/client/clientset/versioned"
+	mpaInformers "multidim-pod-autoscaler/pkg/client/informers/externalversions"
+	mpaListers "multidim-pod-autoscaler/pkg/client/listers/autoscaling/v1"
+	"sort"
+	"time"
+)
+
+const (
+	// ClusterPolicyPrefix、PolicyPrefix 为 status.effectivePolicy.appliedPolicies 中策略名的前缀
+	ClusterPolicyPrefix = "ClusterPolicy/"
+	PolicyPrefix        = "Policy/"
+)
+
+// Resolver 获取选中 MPA 的默认策略(MultidimPodAutoscalerClusterPolicy、MultidimPodAutoscalerPolicy),
+// 并将其合并到 MPA 的 spec 之下
+type Resolver interface {
+	// Resolve 返回 spec 为生效配置的 mpa 副本, 以及生效的策略
+	// 没有策略选中 mpa 时返回 mpa 本身, 生效的策略为 nil
+	// 返回的副本只用于读取, 不能写回 apiserver
+	Resolve(mpa *mpaTypes.MultidimPodAutoscaler) (*mpaTypes.MultidimPodAutoscaler, *mpaTypes.EffectivePolicy)
+}
+
+// resolver 实现 Resolver 接口
+type resolver struct {
+	clusterPolicyLister mpaListers.MultidimPodAutoscalerClusterPolicyLister
+	policyLister        mpaListers.MultidimPodAutoscalerPolicyLister
+	namespaceLister     coreListers.NamespaceLister
+}
+
+// NewResolver 创建 Resolver, 并等待策略及命名空间的缓存同步完成
+// namespace 不为空时只关注该命名空间(与 MPA 的范围一致); onChange 不为 nil 时, 策略变化后被调用
+func NewResolver(
+	kubeclient kubeClient.Interface,
+	mpaclient mpaClientset.Interface,
+	namespace string,
+	onChange func(),
+	stopCh <-chan struct{},
+) (Resolver, error) {
+	mpaInformerFactory := mpaInformers.NewSharedInformerFactoryWithOptions(mpaclient, time.Hour, mpaInformers.WithNamespace(namespace))
+	clusterPolicyInformer := mpaInformerFactory.Autoscaling().V1().MultidimPodAutoscalerClusterPolicies()
+	policyInformer := mpaInformerFactory.Autoscaling().V1().MultidimPodAutoscalerPolicies()
+	// 命名空间的 label 用于集群策略的 namespaceSelector
+	kubeInformerFactory := kubeInformers.NewSharedInformerFactoryWithOptions(kubeclient, time.Hour,
+		kubeInformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			if namespace != "" {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", namespace).String()
+			}
+		}))
+	namespaceInformer := kubeInformerFactory.Core().V1().Namespaces()
+
+	r := &resolver{
+		clusterPolicyLister: clusterPolicyInformer.Lister(),
+		policyLister:        policyInformer.Lister(),
+		namespaceLister:     namespaceInformer.Lister(),
+	}
+	if onChange != nil {
+		handler := cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { onChange() },
+			UpdateFunc: func(interface{}, interface{}) { onChange() },
+			DeleteFunc: func(interface{}) { onChange() },
+		}
+		clusterPolicyInformer.Informer().AddEventHandler(handler)
+		policyInformer.Informer().AddEventHandler(handler)
+	}
+
+	mpaInformerFactory.Start(stopCh)
+	kubeInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, clusterPolicyInformer.Informer().HasSynced, policyInformer.Informer().HasSynced,
+		namespaceInformer.Informer().HasSynced) {
+		return nil, fmt.Errorf("failed to sync MPA policy cache during initialization")
+	}
+	klog.Infof("Initial MPA policies synced successful")
+	return r, nil
+}
+
+// Resolve 实现 Resolver 接口
+// 合并顺序: 集群策略 -> 命名空间内的策略 -> MPA 的 spec, 同一范围内的策略按优先级从低到高、名字的字母序合并
+func (r *resolver) Resolve(mpa *mpaTypes.MultidimPodAutoscaler) (*mpaTypes.MultidimPodAutoscaler, *mpaTypes.EffectivePolicy) {
+	names := make([]string, 0)
+	policies := make([]*mpaTypes.DefaultPolicy, 0)
+	for _, clusterPolicy := range r.clusterPoliciesFor(mpa) {
+		names = append(names, ClusterPolicyPrefix+clusterPolicy.Name)
+		policies = append(policies, &clusterPolicy.Spec.Defaults)
+	}
+	for _, policy := range r.policiesFor(mpa) {
+		names = append(names, PolicyPrefix+policy.Name)
+		policies = append(policies, &policy.Spec.Defaults)
+	}
+	if len(policies) == 0 {
+		return mpa, nil
+	}
+
+	effective := &mpaTypes.EffectivePolicy{
+		AppliedPolicies: names,
+		DefaultPolicy:   Merge(&mpa.Spec, policies...),
+	}
+	mpaCopy := mpa.DeepCopy()
+	ApplyTo(&mpaCopy.Spec, &effective.DefaultPolicy)
+	return mpaCopy, effective
+}
+
+// clusterPoliciesFor 返回选中 mpa 的集群策略, 按合并顺序排列
+func (r *resolver) clusterPoliciesFor(mpa *mpaTypes.MultidimPodAutoscaler) []*mpaTypes.MultidimPodAutoscalerClusterPolicy {
+	if r.clusterPolicyLister == nil {
+		return nil
+	}
+	clusterPolicies, err := r.clusterPolicyLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list MPA cluster policies: %v", err)
+		return nil
+	}
+	var namespaceLabels labels.Set
+	if r.namespaceLister != nil {
+		namespace, err := r.namespaceLister.Get(mpa.Namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("failed to get namespace %s: %v", mpa.Namespace, err)
+		} else if err == nil {
+			namespaceLabels = namespace.Labels
+		}
+	}
+
+	selected := make([]*mpaTypes.MultidimPodAutoscalerClusterPolicy, 0)
+	for _, clusterPolicy := range clusterPolicies {
+		if !selectorMatches(ClusterPolicyPrefix+clusterPolicy.Name, clusterPolicy.Spec.NamespaceSelector, namespaceLabels) ||
+			!selectorMatches(ClusterPolicyPrefix+clusterPolicy.Name, clusterPolicy.Spec.Selector, mpa.Labels) {
+			continue
+		}
+		selected = append(selected, clusterPolicy)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return policyBefore(&selected[i].Spec.MultidimPodAutoscalerPolicySpec, selected[i].Name,
+			&selected[j].Spec.MultidimPodAutoscalerPolicySpec, selected[j].Name)
+	})
+	return selected
+}
+
+// policiesFor 返回 mpa 所在命名空间中选中 mpa 的策略, 按合并顺序排列
+func (r *resolver) policiesFor(mpa *mpaTypes.MultidimPodAutoscaler) []*mpaTypes.MultidimPodAutoscalerPolicy {
+	if r.policyLister == nil {
+		return nil
+	}
+	policies, err := r.policyLister.MultidimPodAutoscalerPolicies(mpa.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list MPA policies in namespace %s: %v", mpa.Namespace, err)
+		return nil
+	}
+
+	selected := make([]*mpaTypes.MultidimPodAutoscalerPolicy, 0)
+	for _, policy := range policies {
+		if selectorMatches(PolicyPrefix+mpa.Namespace+"/"+policy.Name, policy.Spec.Selector, mpa.Labels) {
+			selected = append(selected, policy)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return policyBefore(&selected[i].Spec, selected[i].Name, &selected[j].Spec, selected[j].Name)
+	})
+	return selected
+}
+
+// policyBefore 判断策略 a 是否先于策略 b 合并(被 b 覆盖)
+// 1. 优先级低的先合并
+// 2. 优先级相同时按名字的字母序
+func policyBefore(a *mpaTypes.MultidimPodAutoscalerPolicySpec, aName string, b *mpaTypes.MultidimPodAutoscalerPolicySpec, bName string) bool {
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	return aName < bName
+}
+
+// selectorMatches 判断 selector 是否匹配 set, selector 为 nil 时匹配全部
+// selector 不合法时不匹配
+func selectorMatches(policyName string, selector *metav1.LabelSelector, set labels.Set) bool {
+	if selector == nil {
+		return true
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Warningf("invalid selector of MPA policy %s, ignored: %v", policyName, err)
+		return false
+	}
+	return s.Matches(set)
+}
@@ -0,0 +1,66 @@
+package recommendation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	mpaApi "multidim-pod-autoscaler/pkg/util/mpa"
+)
+
+// ReplicasWithinBounds 判断当前副本数是否在推荐方案的上下界内(无需伸缩)
+// 推荐方案没有给出上下界时, 只有与推荐的副本数相同才无需伸缩
+func ReplicasWithinBounds(replicas int32, recommendation *mpaTypes.RecommendedResources) bool {
+	target := int32(recommendation.TargetPodNum)
+	if recommendation.UpperBoundPodNum <= 0 {
+		return replicas == target
+	}
+	lower, upper := int32(recommendation.LowerBoundPodNum), int32(recommendation.UpperBoundPodNum)
+	// 推荐方案可能被调整到上下界之外, 上下界需包含推荐方案
+	if target < lower {
+		lower = target
+	}
+	if target > upper {
+		upper = target
+	}
+	return replicas >= lower && replicas <= upper
+}
+
+// PodNeedsUpdate 判断 pod 中受控容器的 requests 是否超出推荐方案的上下界
+// 推荐方案没有给出某个资源的上下界时, 只有与推荐值相同才无需更新
+func PodNeedsUpdate(pod *corev1.Pod, mpa *mpaTypes.MultidimPodAutoscaler) bool {
+	policy := mpa.Spec.ResourcePolicy
+	for _, container := range pod.Spec.Containers {
+		if mpaApi.GetContainerScalingMode(container.Name, policy) == mpaTypes.ContainerScalingModeOff {
+			continue
+		}
+		recommendation := GetContainerRecommendation(container.Name, mpa.Status.RecommendationResources.ContainerRecommendations)
+		if recommendation == nil {
+			continue
+		}
+		for _, resourceName := range mpaApi.GetContainerControlledResources(container.Name, policy) {
+			target, existed := recommendation.Target[resourceName]
+			if !existed {
+				continue
+			}
+			request := container.Resources.Requests[resourceName]
+			lower, hasLower := recommendation.LowerBound[resourceName]
+			upper, hasUpper := recommendation.UpperBound[resourceName]
+			if !hasLower || !hasUpper {
+				if request.Cmp(target) != 0 {
+					return true
+				}
+				continue
+			}
+			// 推荐方案可能被调整到上下界之外, 上下界需包含推荐方案
+			if target.Cmp(lower) < 0 {
+				lower = target
+			}
+			if target.Cmp(upper) > 0 {
+				upper = target
+			}
+			if request.Cmp(lower) < 0 || request.Cmp(upper) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -5,7 +5,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog"
+	"math"
 	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+	containerUtil "multidim-pod-autoscaler/pkg/util/container"
 	"multidim-pod-autoscaler/pkg/util/limitrange"
 	mpaApi "multidim-pod-autoscaler/pkg/util/mpa"
 )
@@ -30,11 +32,14 @@ type Processor interface {
 type adjustAction string
 
 const (
-	adjustToMinAllowed adjustAction = "adjust to min allowed"
-	adjustToMaxAllowed adjustAction = "adjust to max allowed"
-	adjustToLimit      adjustAction = "adjust to container limit"
-	adjustToMaxLimit   adjustAction = "adjust to fix Max limit in limit range"
-	adjustToMinLimit   adjustAction = "adjust to fix Min limit in limit range"
+	adjustToMinAllowed           adjustAction = "adjust to min allowed"
+	adjustToMaxAllowed           adjustAction = "adjust to max allowed"
+	adjustToLimit                adjustAction = "adjust to container limit"
+	adjustToMaxLimit             adjustAction = "adjust to fix Max limit in limit range"
+	adjustToMinLimit             adjustAction = "adjust to fix Min limit in limit range"
+	adjustToMaxLimitRequestRatio adjustAction = "adjust to fix MaxLimitRequestRatio in limit range"
+	adjustToPodMaxLimit          adjustAction = "adjust to fix pod Max limit in limit range"
+	adjustToPodMinLimit          adjustAction = "adjust to fix pod Min limit in limit range"
 )
 
 type processor struct {
@@ -51,6 +56,7 @@ func NewProcessor(limitrangeCalculator limitrange.Calculator) Processor {
 // 考虑因素：容器资源限制(limitrange); pod资源策略(用户预配置)等
 // 调整后的推荐方案 和 error 必须有一个不为空
 // 返回的ContainerAnnotationsMap包含了被处理容器和其对应的处理标记的map
+// 通配容器名("*")的推荐方案会展开为 pod 中每个容器各自的推荐方案
 func (p *processor) AdjustRecommendation(
 	podRecommendation *mpaTypes.RecommendedResources,
 	policy *mpaTypes.PodResourcePolicy,
@@ -60,36 +66,29 @@ func (p *processor) AdjustRecommendation(
 		return nil, nil, fmt.Errorf("no recommendation aviliable for adjust(pod: %s/%s)", pod.Namespace, pod.Name)
 	}
 
-	return podRecommendation, nil, nil
-	// TODO: 修正当前的limitrange计算
-
-	var adjustedRecommendations []mpaTypes.RecommendedContainerResources
-	containersAnnotations := ContainerAnnotationsMap{}
-
-	limitAdjustedRecomendations, err := p.adjustToPodLimitRange(podRecommendation.ContainerRecommendations, pod)
-	if err != nil {
-		return nil, nil, err
+	// 获取容器的 limit range
+	var containerLimitRange *corev1.LimitRangeItem
+	if p.limitRangeCalculator != nil {
+		var err error
+		containerLimitRange, err = p.limitRangeCalculator.GetContainerLimitRangeItem(pod.Namespace)
+		if err != nil {
+			klog.Warningf("failed to fetch limit range for %v namespace: %v", pod.Namespace, err)
+		}
 	}
 
-	for _, containerRecomm := range limitAdjustedRecomendations {
-		// 获取容器对象
-		container := getContainer(containerRecomm.ContainerName, pod)
+	adjustedRecommendations := make([]mpaTypes.RecommendedContainerResources, 0, len(pod.Spec.Containers))
+	containersAnnotations := ContainerAnnotationsMap{}
 
-		if container == nil {
-			klog.V(2).Infof("no matching container(name: %s) found for adjust recommendation", containerRecomm.ContainerName)
+	for _, container := range pod.Spec.Containers {
+		// 获取容器的推荐方案(精确匹配容器名或通配)
+		containerRecomm := GetContainerRecommendation(container.Name, podRecommendation.ContainerRecommendations)
+		if containerRecomm == nil {
+			klog.V(2).Infof("no matching recommendation found for container %s", container.Name)
 			continue
 		}
-		// 获取容器的 limit range
-		var containerLimitRange *corev1.LimitRangeItem
-		var err error
-		if p.limitRangeCalculator != nil {
-			containerLimitRange, err = p.limitRangeCalculator.GetContainerLimitRangeItem(pod.Namespace)
-		}
-		if err != nil {
-			klog.Warningf("failed to fetch limit range for %v namespace", pod.Namespace)
-		}
+		containerRecomm.ContainerName = container.Name
 		// 调整推荐方案
-		adjustedContainerResource, containerAnnotations, err := adjustRecommendationForContainer(*container, &containerRecomm, policy, containerLimitRange)
+		adjustedContainerResource, containerAnnotations, err := adjustRecommendationForContainer(container, containerRecomm, policy, containerLimitRange)
 		// 添加该容器的处理标记
 		if len(containerAnnotations) > 0 {
 			containersAnnotations[container.Name] = containerAnnotations
@@ -100,6 +99,13 @@ func (p *processor) AdjustRecommendation(
 		// 保存当前容器调整后的推荐方案
 		adjustedRecommendations = append(adjustedRecommendations, *adjustedContainerResource)
 	}
+
+	// 调整 pod 整体的推荐方案以符合 pod 的 LimitRange
+	adjustedRecommendations, err := p.adjustToPodLimitRange(adjustedRecommendations, policy, pod, containerLimitRange, containersAnnotations)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return &mpaTypes.RecommendedResources{
 		TargetPodNum:             podRecommendation.TargetPodNum,
 		LowerBoundPodNum:         podRecommendation.LowerBoundPodNum,
@@ -117,7 +123,7 @@ func GetContainerRecommendation(
 	if recommendation != nil {
 		for _, recomm := range recommendation {
 			if containerName == recomm.ContainerName || recomm.ContainerName == mpaTypes.DefaultContainerResourcePolicy {
-				return &recomm
+				return recomm.DeepCopy()
 			}
 		}
 	}
@@ -125,6 +131,7 @@ func GetContainerRecommendation(
 }
 
 // adjustRecommendationForContainer 调整容器推荐资源使其符合 mpa policy 和 limit range 的限制
+// 先应用 mpa policy, 再应用 limit range(不符合 limit range 的 pod 会被 api-server 拒绝)
 func adjustRecommendationForContainer(
 	container corev1.Container,
 	recommendation *mpaTypes.RecommendedContainerResources,
@@ -136,16 +143,21 @@ func adjustRecommendationForContainer(
 	}
 
 	containerPolicy := mpaApi.GetContainerResourcePolicy(container.Name, podPolicy)
+	controlledMode := mpaApi.GetContainerControlledMode(container.Name, podPolicy)
 	adjustedRecommendation := recommendation.DeepCopy()
+	// 保留调整前的推荐方案(仅用于状态描述及后续推荐的比较)
+	if adjustedRecommendation.UncappedTarget == nil {
+		adjustedRecommendation.UncappedTarget = recommendation.Target.DeepCopy()
+	}
 
 	adjustAnnotations := make([]string, 0)
 
 	process := func(recomm corev1.ResourceList, getAnnotations bool) {
-		limitAnnotations := adjustToContainerLimitRange(recomm, container, limitRange)
 		policyAnnotations := adjustToMpaPolicy(recomm, containerPolicy)
+		limitAnnotations := adjustToContainerLimitRange(recomm, container, limitRange, controlledMode)
 		if getAnnotations {
-			adjustAnnotations = append(adjustAnnotations, limitAnnotations...)
 			adjustAnnotations = append(adjustAnnotations, policyAnnotations...)
+			adjustAnnotations = append(adjustAnnotations, limitAnnotations...)
 		}
 	}
 	process(adjustedRecommendation.Target, true)
@@ -171,45 +183,220 @@ func adjustToMpaPolicy(
 	for name, recommened := range recommendation {
 		// 调整下限
 		toMin, overflow := adjustToPolicyMin(name, recommened, *policy)
-		recommendation[name] = toMin
 		if overflow {
 			annotations = append(annotations, adjustAnnotation(name, adjustToMinAllowed))
 		}
 		// 调整上限
-		toMax, overflow := adjustToPolicyMax(name, recommened, *policy)
-		recommendation[name] = toMax
+		toMax, overflow := adjustToPolicyMax(name, toMin, *policy)
 		if overflow {
 			annotations = append(annotations, adjustAnnotation(name, adjustToMaxAllowed))
 		}
+		recommendation[name] = toMax
 	}
 	return annotations
 }
 
+// adjustToContainerLimitRange 调整容器的推荐 request 使其符合容器 LimitRange 的限制
+// RequestsAndLimits: limit 与 request 按原比例伸缩, 因此通过 Max 反推 request 的上界, 比例保持不变
+// RequestsOnly: limit 不变, request 不能超过 limit, 且 limit:request 不能超过 MaxLimitRequestRatio
 func adjustToContainerLimitRange(
 	recommendation corev1.ResourceList,
 	container corev1.Container,
-	limitRrange *corev1.LimitRangeItem,
+	limitRange *corev1.LimitRangeItem,
+	controlledMode mpaTypes.ContainerControlledMode,
 ) []string {
-	// TODO: 调整容器资源推荐方案以符合容器 LimitRange 的限制
-	return make([]string, 0)
+	annotations := make([]string, 0)
+	if limitRange == nil {
+		return annotations
+	}
+
+	for name, recommended := range recommendation {
+		originalRequest := quantityOrNil(container.Resources.Requests, name)
+		originalLimit := quantityOrNil(container.Resources.Limits, name)
+		defaultLimit := quantityOrNil(limitRange.Default, name)
+		// 容器最终的 limit(RequestsOnly 时不变)
+		fixedLimit := originalLimit
+		if fixedLimit == nil {
+			fixedLimit = defaultLimit
+		}
+
+		// 调整上限
+		if maxQuantity := quantityOrNil(limitRange.Max, name); maxQuantity != nil {
+			maxRequest := maxQuantity
+			if controlledMode == mpaTypes.ContainerControlledRequestsAndLimits {
+				if boundary := containerUtil.GetBoundaryRequest(originalRequest, originalLimit, maxQuantity, defaultLimit); !boundary.IsZero() {
+					maxRequest = boundary
+				}
+			}
+			if recommended.Cmp(*maxRequest) > 0 {
+				recommended = maxRequest.DeepCopy()
+				annotations = append(annotations, adjustAnnotation(name, adjustToMaxLimit))
+			}
+		}
+		if controlledMode == mpaTypes.ContainerControlledRequestsOnly && fixedLimit != nil {
+			// request 不能超过 limit
+			if recommended.Cmp(*fixedLimit) > 0 {
+				recommended = fixedLimit.DeepCopy()
+				annotations = append(annotations, adjustAnnotation(name, adjustToLimit))
+			}
+			// limit:request 不能超过 MaxLimitRequestRatio
+			if ratio := quantityOrNil(limitRange.MaxLimitRequestRatio, name); ratio != nil && ratio.MilliValue() > 0 {
+				minRequest := resource.NewMilliQuantity(
+					int64(math.Ceil(float64(fixedLimit.MilliValue())*1000/float64(ratio.MilliValue()))), recommended.Format)
+				if recommended.Cmp(*minRequest) < 0 {
+					recommended = *minRequest
+					annotations = append(annotations, adjustAnnotation(name, adjustToMaxLimitRequestRatio))
+				}
+			}
+		}
+		// 调整下限(下限优先, 否则 pod 会被拒绝)
+		if minQuantity := quantityOrNil(limitRange.Min, name); minQuantity != nil && recommended.Cmp(*minQuantity) < 0 {
+			recommended = minQuantity.DeepCopy()
+			annotations = append(annotations, adjustAnnotation(name, adjustToMinLimit))
+		}
+		recommendation[name] = recommended
+	}
+	return annotations
 }
 
+// adjustToPodLimitRange 调整推荐方案使 pod 中所有容器的资源之和符合 pod 的 LimitRange 限制
+// 超出 Max(或低于 Min)时, 所有容器的 request 按同一比例缩放(即按各容器的推荐值比例分摊)
+// 未被伸缩器控制的容器的资源不变, 从 pod 的限制中扣除
 func (p *processor) adjustToPodLimitRange(
 	recommendations []mpaTypes.RecommendedContainerResources,
+	podPolicy *mpaTypes.PodResourcePolicy,
 	pod *corev1.Pod,
+	containerLimitRange *corev1.LimitRangeItem,
+	annotations ContainerAnnotationsMap,
 ) ([]mpaTypes.RecommendedContainerResources, error) {
-	//podLimitRange, err := p.limitRangeCalculator.GetPodLimitRangeItem(pod.Namespace)
-	//if err != nil {
-	//	return nil, fmt.Errorf("connot fetch pod(name: %v)'s limit range: %v", pod.Name, err)
-	//}
-	//if podLimitRange == nil {
-	//	// 没有 limit range 的限制，原样返回
-	//	return recommendations, nil
-	//}
-	// TODO: 调整推荐以符合 POD 的 LimitRange 限制
+	if p.limitRangeCalculator == nil {
+		return recommendations, nil
+	}
+	podLimitRange, err := p.limitRangeCalculator.GetPodLimitRangeItem(pod.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("connot fetch pod(name: %v)'s limit range: %v", pod.Name, err)
+	}
+	if podLimitRange == nil {
+		// 没有 limit range 的限制，原样返回
+		return recommendations, nil
+	}
+	var defaultLimit corev1.ResourceList
+	if containerLimitRange != nil {
+		defaultLimit = containerLimitRange.Default
+	}
+
+	// 被推荐的容器
+	recommended := map[string]bool{}
+	for _, recomm := range recommendations {
+		recommended[recomm.ContainerName] = true
+	}
+
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		// 统计推荐方案下 pod 的 request 及 limit 之和
+		var requestSum, limitSum, fixedRequestSum, fixedLimitSum int64
+		for _, container := range pod.Spec.Containers {
+			if recommended[container.Name] {
+				continue
+			}
+			fixedRequestSum += container.Resources.Requests.Name(name, resource.DecimalSI).MilliValue()
+			fixedLimitSum += container.Resources.Limits.Name(name, resource.DecimalSI).MilliValue()
+		}
+		for _, recomm := range recommendations {
+			request, existed := recomm.Target[name]
+			if !existed {
+				continue
+			}
+			container := getContainer(recomm.ContainerName, pod)
+			requestSum += request.MilliValue()
+			limit := projectedLimit(name, container, request, defaultLimit, podPolicy)
+			limitSum += limit.MilliValue()
+		}
+		if requestSum == 0 {
+			continue
+		}
+
+		factor := 1.0
+		action := adjustAction("")
+		if maxQuantity := quantityOrNil(podLimitRange.Max, name); maxQuantity != nil {
+			budget := maxQuantity.MilliValue() - fixedLimitSum
+			if limitSum > budget && limitSum > 0 {
+				factor, action = float64(budget)/float64(limitSum), adjustToPodMaxLimit
+			} else if requestSum > maxQuantity.MilliValue()-fixedRequestSum {
+				factor, action = float64(maxQuantity.MilliValue()-fixedRequestSum)/float64(requestSum), adjustToPodMaxLimit
+			}
+		}
+		if minQuantity := quantityOrNil(podLimitRange.Min, name); minQuantity != nil {
+			budget := minQuantity.MilliValue() - fixedRequestSum
+			if requestSum < budget {
+				factor, action = float64(budget)/float64(requestSum), adjustToPodMinLimit
+			}
+		}
+		if action == "" {
+			continue
+		}
+		if factor <= 0 {
+			return nil, fmt.Errorf("pod(%s/%s) cannot fit the %s limit range of namespace %s", pod.Namespace, pod.Name, name, pod.Namespace)
+		}
+		for i := range recommendations {
+			request, existed := recommendations[i].Target[name]
+			if !existed {
+				continue
+			}
+			recommendations[i].Target[name] = scaleQuantity(request, factor, action == adjustToPodMinLimit)
+			containerName := recommendations[i].ContainerName
+			annotations[containerName] = append(annotations[containerName], adjustAnnotation(name, action))
+		}
+	}
 	return recommendations, nil
 }
 
+// projectedLimit 返回容器 request 为 request 时, admission 设置的 limit(按原比例伸缩)
+func projectedLimit(
+	name corev1.ResourceName, container *corev1.Container,
+	request resource.Quantity, defaultLimit corev1.ResourceList,
+	podPolicy *mpaTypes.PodResourcePolicy,
+) resource.Quantity {
+	if container == nil {
+		return request
+	}
+	if mpaApi.GetContainerControlledMode(container.Name, podPolicy) == mpaTypes.ContainerControlledRequestsOnly {
+		if limit := quantityOrNil(container.Resources.Limits, name); limit != nil {
+			return *limit
+		}
+		if limit := quantityOrNil(defaultLimit, name); limit != nil {
+			return *limit
+		}
+		return request
+	}
+	limit, _ := containerUtil.GetProportionalResourceLimit(name,
+		quantityOrNil(container.Resources.Limits, name), quantityOrNil(container.Resources.Requests, name),
+		&request, quantityOrNil(defaultLimit, name))
+	if limit == nil {
+		return request
+	}
+	return *limit
+}
+
+// scaleQuantity 将 quantity 缩放 factor 倍; roundUp 为 true 时向上取整, 否则向下取整
+func scaleQuantity(quantity resource.Quantity, factor float64, roundUp bool) resource.Quantity {
+	scaled := float64(quantity.MilliValue()) * factor
+	if roundUp {
+		scaled = math.Ceil(scaled)
+	} else {
+		scaled = math.Floor(scaled)
+	}
+	return *resource.NewMilliQuantity(int64(scaled), quantity.Format)
+}
+
+// quantityOrNil 返回 resources 中指定的资源量, 不存在或为0时返回 nil
+func quantityOrNil(resources corev1.ResourceList, name corev1.ResourceName) *resource.Quantity {
+	quantity, existed := resources[name]
+	if !existed || quantity.IsZero() {
+		return nil
+	}
+	return &quantity
+}
+
 // adjustToPolicyMin 调整 推荐资源量 符合policy策略的最小值
 func adjustToPolicyMin(
 	resourceName corev1.ResourceName,
@@ -258,9 +445,9 @@ func adjustToMax(
 // 未找到返回 nil
 func getContainer(containerName string, pod *corev1.Pod) *corev1.Container {
 	if pod != nil {
-		for _, container := range pod.Spec.Containers {
-			if containerName == container.Name {
-				return &container
+		for i := range pod.Spec.Containers {
+			if containerName == pod.Spec.Containers[i].Name {
+				return &pod.Spec.Containers[i]
 			}
 		}
 	}
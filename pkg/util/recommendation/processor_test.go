@@ -0,0 +1,176 @@
+package recommendation
+
+import (
+	"testing"
+
+	mpaTypes "multidim-pod-autoscaler/pkg/apis/autoscaling/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// fakeLimitRangeCalculator 返回固定的 LimitRange
+type fakeLimitRangeCalculator struct {
+	containerLimitRange *corev1.LimitRangeItem
+	podLimitRange       *corev1.LimitRangeItem
+}
+
+func (f *fakeLimitRangeCalculator) GetContainerLimitRangeItem(namespace string) (*corev1.LimitRangeItem, error) {
+	return f.containerLimitRange, nil
+}
+
+func (f *fakeLimitRangeCalculator) GetPodLimitRangeItem(namespace string) (*corev1.LimitRangeItem, error) {
+	return f.podLimitRange, nil
+}
+
+func cpuList(cpu string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+}
+
+func TestAdjustToPodLimitRange(t *testing.T) {
+	// 容器的 limit:request 为 2:1, 伸缩后 limit 为推荐 request 的 2 倍
+	container := func(name string) corev1.Container {
+		return corev1.Container{
+			Name: name,
+			Resources: corev1.ResourceRequirements{
+				Requests: cpuList("500m"),
+				Limits:   cpuList("1"),
+			},
+		}
+	}
+	newPod := func(containers ...corev1.Container) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{Containers: containers}}
+	}
+	recommendations := func(cpus ...string) []mpaTypes.RecommendedContainerResources {
+		result := make([]mpaTypes.RecommendedContainerResources, 0, len(cpus))
+		for i, cpu := range cpus {
+			result = append(result, mpaTypes.RecommendedContainerResources{
+				ContainerName: string(rune('a' + i)),
+				Target:        cpuList(cpu),
+			})
+		}
+		return result
+	}
+	// 未被推荐的容器 c 的 request 为 1, limit 为 2
+	unmanaged := corev1.Container{
+		Name: "c",
+		Resources: corev1.ResourceRequirements{
+			Requests: cpuList("1"),
+			Limits:   cpuList("2"),
+		},
+	}
+	maxAnnotation := adjustAnnotation(corev1.ResourceCPU, adjustToPodMaxLimit)
+	minAnnotation := adjustAnnotation(corev1.ResourceCPU, adjustToPodMinLimit)
+
+	tests := []struct {
+		name                string
+		pod                 *corev1.Pod
+		podLimitRange       *corev1.LimitRangeItem
+		recommendations     []mpaTypes.RecommendedContainerResources
+		expected            []mpaTypes.RecommendedContainerResources
+		expectedAnnotations ContainerAnnotationsMap
+		expectError         bool
+	}{
+		{
+			name:                "no pod limit range",
+			pod:                 newPod(container("a"), container("b")),
+			podLimitRange:       nil,
+			recommendations:     recommendations("2", "2"),
+			expected:            recommendations("2", "2"),
+			expectedAnnotations: ContainerAnnotationsMap{},
+		},
+		{
+			name:                "within pod limit range",
+			pod:                 newPod(container("a"), container("b")),
+			podLimitRange:       &corev1.LimitRangeItem{Min: cpuList("1"), Max: cpuList("8")},
+			recommendations:     recommendations("2", "2"),
+			expected:            recommendations("2", "2"),
+			expectedAnnotations: ContainerAnnotationsMap{},
+		},
+		{
+			name:            "projected limits above pod max are scaled down",
+			pod:             newPod(container("a"), container("b")),
+			podLimitRange:   &corev1.LimitRangeItem{Max: cpuList("4")},
+			recommendations: recommendations("2", "2"),
+			expected:        recommendations("1", "1"),
+			expectedAnnotations: ContainerAnnotationsMap{
+				"a": {maxAnnotation},
+				"b": {maxAnnotation},
+			},
+		},
+		{
+			name:            "scaled down in proportion to the recommendations",
+			pod:             newPod(container("a"), container("b")),
+			podLimitRange:   &corev1.LimitRangeItem{Max: cpuList("4")},
+			recommendations: recommendations("3", "1"),
+			expected:        recommendations("1500m", "500m"),
+			expectedAnnotations: ContainerAnnotationsMap{
+				"a": {maxAnnotation},
+				"b": {maxAnnotation},
+			},
+		},
+		{
+			name:            "requests below pod min are scaled up",
+			pod:             newPod(container("a"), container("b")),
+			podLimitRange:   &corev1.LimitRangeItem{Min: cpuList("6")},
+			recommendations: recommendations("2", "2"),
+			expected:        recommendations("3", "3"),
+			expectedAnnotations: ContainerAnnotationsMap{
+				"a": {minAnnotation},
+				"b": {minAnnotation},
+			},
+		},
+		{
+			name:            "unmanaged container is deducted from pod max",
+			pod:             newPod(container("a"), container("b"), unmanaged),
+			podLimitRange:   &corev1.LimitRangeItem{Max: cpuList("6")},
+			recommendations: recommendations("2", "2"),
+			expected:        recommendations("1", "1"),
+			expectedAnnotations: ContainerAnnotationsMap{
+				"a": {maxAnnotation},
+				"b": {maxAnnotation},
+			},
+		},
+		{
+			name:            "unmanaged container is deducted from pod min",
+			pod:             newPod(container("a"), container("b"), unmanaged),
+			podLimitRange:   &corev1.LimitRangeItem{Min: cpuList("5")},
+			recommendations: recommendations("1", "1"),
+			expected:        recommendations("2", "2"),
+			expectedAnnotations: ContainerAnnotationsMap{
+				"a": {minAnnotation},
+				"b": {minAnnotation},
+			},
+		},
+		{
+			name:            "unmanaged container uses up pod max",
+			pod:             newPod(container("a"), container("b"), unmanaged),
+			podLimitRange:   &corev1.LimitRangeItem{Max: cpuList("2")},
+			recommendations: recommendations("2", "2"),
+			expectError:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &processor{limitRangeCalculator: &fakeLimitRangeCalculator{podLimitRange: tc.podLimitRange}}
+			annotations := ContainerAnnotationsMap{}
+			result, err := p.adjustToPodLimitRange(tc.recommendations, nil, tc.pod, nil, annotations)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+			if !equality.Semantic.DeepEqual(annotations, tc.expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tc.expectedAnnotations, annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilNet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// TransientErrorBackoff 访问 API-Server 出现暂时性错误时的重试间隔(共约 6s)
+var TransientErrorBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 200 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+}
+
+// IsTransientError 判断访问 API-Server 的错误是否是暂时性的(超时、限流、服务不可用、连接断开等), 重试可能成功
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.IsServerTimeout(err) || errors.IsTimeout(err) || errors.IsTooManyRequests(err) ||
+		errors.IsInternalError(err) || errors.IsServiceUnavailable(err) {
+		return true
+	}
+	if utilNet.IsConnectionReset(err) || utilNet.IsConnectionRefused(err) || utilNet.IsProbableEOF(err) {
+		return true
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+// RetryOnTransientError 执行 fn, 出现暂时性错误时按 TransientErrorBackoff 重试
+// ctx 结束后不再重试, 返回最后一次的错误
+func RetryOnTransientError(ctx context.Context, fn func() error) error {
+	return retry.OnError(TransientErrorBackoff, func(err error) bool {
+		return ctx.Err() == nil && IsTransientError(err)
+	}, fn)
+}
@@ -0,0 +1,67 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// hashRing 一致性哈希环
+// 每个成员在环上有 virtualNodes 个虚拟节点, key 属于顺时针方向第一个虚拟节点对应的成员
+// 成员增减时只有相邻区间内的 key 会被重新分配
+type hashRing struct {
+	// members 排序后的成员列表
+	members []string
+	// hashes 排序后的虚拟节点哈希值
+	hashes []uint32
+	// owners 虚拟节点哈希值 -> 成员
+	owners map[uint32]string
+}
+
+// newHashRing 根据成员列表构造哈希环
+func newHashRing(members []string, virtualNodes int) *hashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = 1
+	}
+	sorted := append([]string{}, members...)
+	sort.Strings(sorted)
+
+	ring := &hashRing{
+		members: sorted,
+		hashes:  make([]uint32, 0, len(sorted)*virtualNodes),
+		owners:  make(map[uint32]string, len(sorted)*virtualNodes),
+	}
+	for _, member := range sorted {
+		for i := 0; i < virtualNodes; i += 1 {
+			hash := hashKey(member + "#" + strconv.Itoa(i))
+			// 哈希冲突时保留字典序较小的成员, 保证各副本计算结果一致
+			if _, existed := ring.owners[hash]; existed {
+				continue
+			}
+			ring.owners[hash] = member
+			ring.hashes = append(ring.hashes, hash)
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+// get 返回 key 所属的成员; 环为空时返回空字符串
+func (r *hashRing) get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	hash := hashKey(key)
+	idx := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+	return r.owners[r.hashes[idx]]
+}
+
+// hashKey 计算 key 的 32 位 FNV-1a 哈希值
+func hashKey(key string) uint32 {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	return hasher.Sum32()
+}
@@ -0,0 +1,331 @@
+// Package sharding 为 recommender 提供多副本 active/active 分片
+// 每个副本维护一个 membership Lease, 通过一致性哈希将 MPA 分配给存活的副本
+// 副本失效(Lease 过期)后, 它负责的 MPA 会自动转移给其他副本
+package sharding
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	coordinationV1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	kubeClient "k8s.io/client-go/kubernetes"
+	coordinationListers "k8s.io/client-go/listers/coordination/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"multidim-pod-autoscaler/pkg/util/metrics"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// metricsNamespace 分片相关 metrics 的namespace
+	metricsNamespace = metrics.TopNamespace + "sharding"
+	// shardGroupLabel 标记 membership Lease 所属的分片组(即组件名)
+	shardGroupLabel = "mpa.k8s.io/shard-group"
+	// podNameEnv 副本的 pod 名(downward API), 设置时作为 membership Lease 的名字, 容器重启后复用同一个 Lease
+	podNameEnv = "POD_NAME"
+	// staleLeaseFactor 过期超过 staleLeaseFactor 个 lease duration 的 Lease 被视为异常退出(OOM、节点失联等)的副本遗留, 由存活的副本删除
+	staleLeaseFactor = 10
+)
+
+var (
+	shardMembers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "members",
+			Help:      "当前副本观测到的存活分片成员数",
+		},
+		[]string{"component"},
+	)
+	membershipChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "membership_changes_total",
+			Help:      "当前副本观测到的分片成员变更次数",
+		},
+		[]string{"component"},
+	)
+)
+
+// Config 分片配置
+type Config struct {
+	// Enabled 是否开启分片; 开启后各副本同时运行, 只处理属于自己的 MPA
+	Enabled bool
+	// LeaseDuration membership Lease 的有效期, 超过该时间未续约的副本被视为失效
+	LeaseDuration time.Duration
+	// RenewPeriod 续约 membership Lease 的时间间隔, 需小于 lease duration
+	RenewPeriod time.Duration
+	// ResourceNamespace membership Lease 对象的命名空间
+	ResourceNamespace string
+	// VirtualNodes 每个副本在哈希环上的虚拟节点数
+	VirtualNodes int
+}
+
+// RegisterFlags 在 fs 中注册分片相关的命令行参数
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	config := &Config{}
+	fs.BoolVar(&config.Enabled, "sharding", false, "是否开启分片(各副本同时运行, 按命名空间一致性哈希分配MPA; 开启后不再选主)")
+	fs.DurationVar(&config.LeaseDuration, "sharding-lease-duration", 15*time.Second, "membership Lease 的有效期, 超时未续约的副本被视为失效")
+	fs.DurationVar(&config.RenewPeriod, "sharding-renew-period", 5*time.Second, "续约 membership Lease 的时间间隔, 需小于 lease duration")
+	fs.StringVar(&config.ResourceNamespace, "sharding-resource-namespace", "kube-system", "membership Lease 对象的命名空间")
+	fs.IntVar(&config.VirtualNodes, "sharding-virtual-nodes", 100, "每个副本在哈希环上的虚拟节点数")
+	return config
+}
+
+// RegisterMetrics 注册分片相关的 metrics
+func RegisterMetrics() {
+	prometheus.MustRegister(shardMembers, membershipChanges)
+}
+
+// String 返回分片配置的描述, 用于日志
+func (c *Config) String() string {
+	if !c.Enabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("namespace=%s,leaseDuration=%v,renewPeriod=%v,virtualNodes=%d",
+		c.ResourceNamespace, c.LeaseDuration, c.RenewPeriod, c.VirtualNodes)
+}
+
+// Sharder 判断某个 key 是否由当前副本负责
+type Sharder interface {
+	// Owns 返回 key(如: recommender 使用 MPA 的命名空间) 是否属于当前副本
+	Owns(key string) bool
+	// AddMembershipHandler 注册分片成员变化时的回调(用于重新分配 key)
+	AddMembershipHandler(handler func())
+}
+
+// Member 为分片组中的一个副本, 实现 Sharder 接口
+type Member struct {
+	client      kubeClient.Interface
+	config      *Config
+	component   string
+	identity    string
+	leaseName   string
+	leaseLister coordinationListers.LeaseNamespaceLister
+	leaseSynced cache.InformerSynced
+
+	lock     sync.RWMutex
+	ring     *hashRing
+	members  []string
+	handlers []func()
+}
+
+// NewMember 创建分片组 component 中的一个成员, 并同步 membership Lease 缓存(stopCh 关闭时停止 informer)
+func NewMember(client kubeClient.Interface, config *Config, component string, stopCh <-chan struct{}) (*Member, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname for sharding: %v", err)
+	}
+	// 同一节点上可能运行多个副本, 加上 uuid 保证唯一
+	identity := hostname + "_" + string(uuid.NewUUID())
+
+	factory := informers.NewSharedInformerFactoryWithOptions(client, time.Hour,
+		informers.WithNamespace(config.ResourceNamespace),
+		informers.WithTweakListOptions(func(options *metaV1.ListOptions) {
+			options.LabelSelector = labels.Set{shardGroupLabel: component}.String()
+		}))
+	leaseInformer := factory.Coordination().V1().Leases()
+
+	m := &Member{
+		client:      client,
+		config:      config,
+		component:   component,
+		identity:    identity,
+		leaseName:   leaseName(component),
+		leaseLister: leaseInformer.Lister().Leases(config.ResourceNamespace),
+		leaseSynced: leaseInformer.Informer().HasSynced,
+	}
+	m.ring = newHashRing([]string{identity}, config.VirtualNodes)
+	m.members = []string{identity}
+
+	leaseInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { m.refreshMembers() },
+		UpdateFunc: func(interface{}, interface{}) { m.refreshMembers() },
+		DeleteFunc: func(interface{}) { m.refreshMembers() },
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, m.leaseSynced) {
+		return nil, fmt.Errorf("failed to sync membership leases for %s", component)
+	}
+	return m, nil
+}
+
+// leaseName 返回当前副本的 membership Lease 名: 优先使用 pod 名, 未设置时使用随机名
+func leaseName(component string) string {
+	if podName := os.Getenv(podNameEnv); podName != "" {
+		return fmt.Sprintf("%s-%s", component, podName)
+	}
+	return fmt.Sprintf("%s-%s", component, uuid.NewUUID())
+}
+
+// Identity 返回当前副本的唯一标识
+func (m *Member) Identity() string {
+	return m.identity
+}
+
+// Owns 实现 Sharder 接口
+func (m *Member) Owns(key string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.ring.get(key) == m.identity
+}
+
+// AddMembershipHandler 实现 Sharder 接口
+func (m *Member) AddMembershipHandler(handler func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Run 维护当前副本的 membership Lease, 直到 ctx 结束
+// Lease 过期没有对应的事件, 因此每次续约时也会重新计算存活成员
+// ctx 结束时删除 Lease, 让其他副本立即接管
+func (m *Member) Run(ctx context.Context) {
+	klog.Infof("%s(%s) joined the shard group with lease %s/%s", m.component, m.identity, m.config.ResourceNamespace, m.leaseName)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := m.renew(ctx); err != nil {
+			klog.Errorf("failed to renew membership lease %s/%s: %v", m.config.ResourceNamespace, m.leaseName, err)
+		}
+		m.refreshMembers()
+		m.deleteStaleLeases(ctx)
+	}, m.config.RenewPeriod)
+
+	err := m.client.CoordinationV1().Leases(m.config.ResourceNamespace).Delete(context.Background(), m.leaseName, metaV1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Warningf("failed to release membership lease %s/%s: %v", m.config.ResourceNamespace, m.leaseName, err)
+	}
+	klog.Infof("%s(%s) left the shard group", m.component, m.identity)
+}
+
+// renew 创建或续约当前副本的 membership Lease
+func (m *Member) renew(ctx context.Context) error {
+	now := metaV1.NewMicroTime(time.Now())
+	leaseDurationSeconds := int32(m.config.LeaseDuration.Seconds())
+	leases := m.client.CoordinationV1().Leases(m.config.ResourceNamespace)
+
+	lease, err := leases.Get(ctx, m.leaseName, metaV1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationV1.Lease{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:      m.leaseName,
+				Namespace: m.config.ResourceNamespace,
+				Labels:    map[string]string{shardGroupLabel: m.component},
+			},
+			Spec: coordinationV1.LeaseSpec{
+				HolderIdentity:       &m.identity,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metaV1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	// 复用重启前的 Lease 时更新持有者
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != m.identity {
+		lease.Spec.HolderIdentity = &m.identity
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = &leaseDurationSeconds
+	_, err = leases.Update(ctx, lease, metaV1.UpdateOptions{})
+	return err
+}
+
+// refreshMembers 根据未过期的 membership Lease 重建哈希环
+// 成员发生变化时调用所有注册的回调
+func (m *Member) refreshMembers() {
+	leases, err := m.leaseLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list membership leases: %v", err)
+		return
+	}
+	now := time.Now()
+	// 当前副本始终在环上(即使 Lease 还未创建成功)
+	members := []string{m.identity}
+	for _, lease := range leases {
+		if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == m.identity {
+			continue
+		}
+		if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		expire := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if expire.Before(now) {
+			continue
+		}
+		members = append(members, *lease.Spec.HolderIdentity)
+	}
+
+	m.lock.Lock()
+	ring := newHashRing(members, m.config.VirtualNodes)
+	if sameMembers(ring.members, m.members) {
+		m.lock.Unlock()
+		return
+	}
+	klog.Infof("%s shard members changed: %v -> %v", m.component, m.members, ring.members)
+	m.ring = ring
+	m.members = ring.members
+	handlers := append([]func(){}, m.handlers...)
+	m.lock.Unlock()
+
+	shardMembers.WithLabelValues(m.component).Set(float64(len(ring.members)))
+	membershipChanges.WithLabelValues(m.component).Inc()
+	for _, handler := range handlers {
+		handler()
+	}
+}
+
+// deleteStaleLeases 删除分片组中过期已久的 membership Lease
+// 副本异常退出时不会删除自己的 Lease, 由存活的副本清理; 使用 resourceVersion 作为前提条件, 不会删除刚被续约的 Lease
+func (m *Member) deleteStaleLeases(ctx context.Context) {
+	leases, err := m.leaseLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list membership leases: %v", err)
+		return
+	}
+	staleBefore := time.Now().Add(-staleLeaseFactor * m.config.LeaseDuration)
+	for _, lease := range leases {
+		if lease.Name == m.leaseName || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		expire := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if !expire.Before(staleBefore) {
+			continue
+		}
+		resourceVersion := lease.ResourceVersion
+		err := m.client.CoordinationV1().Leases(m.config.ResourceNamespace).Delete(ctx, lease.Name, metaV1.DeleteOptions{
+			Preconditions: &metaV1.Preconditions{ResourceVersion: &resourceVersion},
+		})
+		if err != nil && !errors.IsNotFound(err) && !errors.IsConflict(err) {
+			klog.Warningf("failed to delete stale membership lease %s/%s: %v", m.config.ResourceNamespace, lease.Name, err)
+			continue
+		}
+		if err == nil {
+			klog.Infof("deleted stale membership lease %s/%s expired at %v", m.config.ResourceNamespace, lease.Name, expire)
+		}
+	}
+}
+
+// sameMembers 判断两个(已排序的)成员列表是否相同
+func sameMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"k8s.io/klog"
+)
+
+// SetupSignalContext 返回收到 SIGTERM、SIGINT 时结束的根 context, 组件据此停止主流程及 informer 并退出
+// 再次收到信号时直接退出进程
+func SetupSignalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		sig := <-signals
+		klog.Infof("received signal %v, shutting down", sig)
+		cancel()
+		sig = <-signals
+		klog.Warningf("received signal %v again, exiting immediately", sig)
+		klog.Flush()
+		os.Exit(1)
+	}()
+	return ctx
+}